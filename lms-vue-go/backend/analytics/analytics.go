@@ -0,0 +1,154 @@
+// Package analytics computes statistics about the exam from graded answers:
+// item analysis of every question, score distributions and student progress.
+// The exam is the list of questions passed in; answers to other questions are ignored.
+package analytics
+
+import (
+	"math"
+	"sort"
+
+	"lms-vue-go/backend/models"
+)
+
+// cell is the result of one examinee for one question
+type cell struct {
+	answered bool
+	graded   bool
+	score    float64 // Points earned; 0 if not answered
+	max      float64 // Score of the question version the student answered
+	answer   string
+}
+
+// fraction returns the share of the points earned, from 0 to 1
+func (c cell) fraction() float64 {
+	if c.max <= 0 {
+		return 0
+	}
+	return c.score / c.max
+}
+
+// matrix holds the results of every examinee for every question. Examinees are
+// students with at least one answer to the exam, in ascending ID order.
+type matrix struct {
+	questions []models.Question
+	students  []uint
+	cells     [][]cell // [examinee][question]
+	earned    []float64
+	possible  []float64 // Points of graded and unanswered questions; ungraded answers are left out
+}
+
+// newMatrix arranges answers per examinee and question. Unanswered questions count
+// as zero out of their current score.
+func newMatrix(questions []models.Question, answers []models.StudentAnswerWithDetails) *matrix {
+	index := map[uint]int{}
+	for i, q := range questions {
+		index[q.ID] = i
+	}
+
+	byStudent := map[uint][]models.StudentAnswerWithDetails{}
+	for _, a := range answers {
+		if _, ok := index[a.QuestionID]; ok {
+			byStudent[a.StudentID] = append(byStudent[a.StudentID], a)
+		}
+	}
+
+	m := &matrix{questions: questions}
+	for id := range byStudent {
+		m.students = append(m.students, id)
+	}
+	sort.Slice(m.students, func(i, j int) bool { return m.students[i] < m.students[j] })
+
+	m.cells = make([][]cell, len(m.students))
+	m.earned = make([]float64, len(m.students))
+	m.possible = make([]float64, len(m.students))
+	for s, id := range m.students {
+		row := make([]cell, len(questions))
+		for i, q := range questions {
+			row[i] = cell{graded: true, max: float64(q.Score)}
+		}
+		for _, a := range byStudent[id] {
+			i := index[a.QuestionID]
+			max := float64(a.QuestionScore)
+			if max <= 0 {
+				max = float64(questions[i].Score)
+			}
+			row[i] = cell{answered: true, graded: a.Score != nil, max: max, answer: a.Answer}
+			if a.Score != nil {
+				row[i].score = float64(*a.Score)
+			}
+		}
+		for _, c := range row {
+			if c.graded {
+				m.earned[s] += c.score
+				m.possible[s] += c.max
+			}
+		}
+		m.cells[s] = row
+	}
+	return m
+}
+
+// total returns the share of the possible points an examinee earned
+func (m *matrix) total(s int) float64 {
+	if m.possible[s] <= 0 {
+		return 0
+	}
+	return m.earned[s] / m.possible[s]
+}
+
+// mean returns the average of values, or 0 for no values
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// variance returns the population variance of values
+func variance(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	avg := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - avg) * (v - avg)
+	}
+	return sum / float64(len(values))
+}
+
+// correlation returns the Pearson correlation of x and y. It reports false if
+// there are fewer than two pairs or either variable is constant.
+func correlation(x, y []float64) (float64, bool) {
+	if len(x) < 2 || len(x) != len(y) {
+		return 0, false
+	}
+	mx, my := mean(x), mean(y)
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0, false
+	}
+	return sxy / math.Sqrt(sxx*syy), true
+}
+
+// round rounds v to the given number of decimals
+func round(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
+
+// ratio returns a pointer to v rounded to three decimals, for values that may be unknown
+func ratio(v float64) *float64 {
+	r := round(v, 3)
+	return &r
+}
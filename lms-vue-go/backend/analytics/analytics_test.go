@@ -0,0 +1,241 @@
+package analytics
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"lms-vue-go/backend/models"
+)
+
+func intPtr(n int) *int { return &n }
+
+// examAnswers returns the answers of ten students to four questions: an easy
+// multiple choice question, two essays that decide the ranking, and a multiple
+// choice question whose key (A) is only chosen by weak students.
+func examAnswers() ([]models.Question, []models.StudentAnswerWithDetails) {
+	questions := []models.Question{
+		{ID: 1, Type: models.MultipleChoice, Question: "Easy", Options: []string{"Yes", "No", "Maybe"}, Answer: "A", Score: 10},
+		{ID: 2, Type: models.Essay, Question: "Essay", Score: 30},
+		{ID: 3, Type: models.MultipleChoice, Question: "Miskeyed", Options: []string{"1", "2", "3"}, Answer: "A", Score: 10},
+		{ID: 4, Type: models.Essay, Question: "Essay 2", Score: 30},
+	}
+	var answers []models.StudentAnswerWithDetails
+	for s := uint(1); s <= 10; s++ {
+		answers = append(answers,
+			models.StudentAnswerWithDetails{StudentID: s, QuestionID: 1, Answer: "A", Score: intPtr(10), QuestionScore: 10},
+			models.StudentAnswerWithDetails{StudentID: s, QuestionID: 2, Answer: "...", Score: intPtr(3 * int(s)), QuestionScore: 30},
+		)
+		// Students 1-3 answer the key, the others the "correct" option B
+		third := models.StudentAnswerWithDetails{StudentID: s, QuestionID: 3, Answer: "B", Score: intPtr(0), QuestionScore: 10}
+		if s <= 3 {
+			third.Answer, third.Score = "A", intPtr(10)
+		}
+		answers = append(answers, third,
+			models.StudentAnswerWithDetails{StudentID: s, QuestionID: 4, Answer: "...", Score: intPtr(3 * int(s)), QuestionScore: 30},
+		)
+	}
+	return questions, answers
+}
+
+func TestAnalyzeItems(t *testing.T) {
+	questions, answers := examAnswers()
+	analysis := AnalyzeItems(questions, answers)
+
+	if analysis.Examinees != 10 || analysis.UpperGroup != 3 {
+		t.Fatalf("examinees %d, upper group %d, want 10 and 3", analysis.Examinees, analysis.UpperGroup)
+	}
+
+	easy := analysis.Items[0]
+	if *easy.Difficulty != 1 || easy.PointBiserial != nil || !slices.Equal(easy.Flags, []Flag{FlagTooEasy}) {
+		t.Errorf("easy item: difficulty %v, point-biserial %v, flags %v", *easy.Difficulty, easy.PointBiserial, easy.Flags)
+	}
+	if easy.Distractors[0].Count != 10 || !easy.Distractors[0].IsKey || easy.Distractors[1].Proportion != 0 {
+		t.Errorf("easy distractors = %+v", easy.Distractors)
+	}
+
+	essay := analysis.Items[1]
+	if *essay.Difficulty != 0.55 || *essay.Discrimination <= 0.5 || *essay.PointBiserial <= 0 || len(essay.Flags) != 0 {
+		t.Errorf("essay: difficulty %v, discrimination %v, point-biserial %v, flags %v",
+			*essay.Difficulty, *essay.Discrimination, *essay.PointBiserial, essay.Flags)
+	}
+	if essay.Distractors != nil {
+		t.Errorf("essay has distractors %v", essay.Distractors)
+	}
+
+	miskeyed := analysis.Items[2]
+	if *miskeyed.Discrimination >= 0 || !slices.Contains(miskeyed.Flags, FlagMiskeyed) {
+		t.Errorf("miskeyed item: discrimination %v, flags %v", *miskeyed.Discrimination, miskeyed.Flags)
+	}
+	if b := miskeyed.Distractors[1]; b.Count != 7 || b.UpperCount <= miskeyed.Distractors[0].UpperCount {
+		t.Errorf("option B = %+v, key = %+v", b, miskeyed.Distractors[0])
+	}
+}
+
+func TestAnalyzeItemsUngradedAndOmitted(t *testing.T) {
+	questions := []models.Question{{ID: 1, Type: models.Essay, Score: 10}, {ID: 2, Type: models.Essay, Score: 10}}
+	answers := []models.StudentAnswerWithDetails{
+		{StudentID: 1, QuestionID: 1, Score: intPtr(10), QuestionScore: 10},
+		{StudentID: 1, QuestionID: 2, QuestionScore: 10}, // Not graded
+		{StudentID: 2, QuestionID: 1, Score: intPtr(4), QuestionScore: 10},
+		{StudentID: 3, QuestionID: 99, Score: intPtr(4), QuestionScore: 10}, // Not part of the exam
+	}
+
+	analysis := AnalyzeItems(questions, answers)
+	if analysis.Examinees != 2 {
+		t.Fatalf("examinees = %d, want 2", analysis.Examinees)
+	}
+	second := analysis.Items[1]
+	if second.Ungraded != 1 || second.Omitted != 1 || second.Responses != 0 || *second.Difficulty != 0 {
+		t.Errorf("second item = %+v", second)
+	}
+	if len(second.Flags) != 0 {
+		t.Errorf("flags with too few responses: %v", second.Flags)
+	}
+}
+
+func TestOptionIndex(t *testing.T) {
+	options := []string{"Jakarta", "Bandung"}
+	for answer, want := range map[string]int{"A": 0, "b": 1, " bandung ": 1, "C": -1, "Surabaya": -1, "": -1} {
+		if got := optionIndex(answer, options); got != want {
+			t.Errorf("optionIndex(%q) = %d, want %d", answer, got, want)
+		}
+	}
+}
+
+func TestExamStatistics(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Type: models.MultipleChoice, Score: 1},
+		{ID: 2, Type: models.MultipleChoice, Score: 1},
+		{ID: 3, Type: models.MultipleChoice, Score: 1},
+	}
+	// Student s answers the first 4-s questions correctly
+	var answers []models.StudentAnswerWithDetails
+	for s := uint(1); s <= 4; s++ {
+		for q := uint(1); q <= 3; q++ {
+			score := 0
+			if q <= 4-s {
+				score = 1
+			}
+			answers = append(answers, models.StudentAnswerWithDetails{StudentID: s, QuestionID: q, Score: intPtr(score), QuestionScore: 1})
+		}
+	}
+
+	stats := ExamStatistics(questions, answers, 0)
+	if stats.Examinees != 4 || stats.Mean != 50 || stats.Median != 50 || stats.StdDev != 43.03 || stats.Min != 0 || stats.Max != 100 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.Percentiles["p25"] != 25 || stats.Percentiles["p90"] != 90 {
+		t.Errorf("percentiles = %v", stats.Percentiles)
+	}
+
+	var counts []int
+	for _, bin := range stats.Histogram {
+		counts = append(counts, bin.Count)
+	}
+	if want := []int{1, 0, 0, 1, 0, 0, 1, 0, 0, 1}; !slices.Equal(counts, want) {
+		t.Errorf("histogram counts = %v, want %v", counts, want)
+	}
+
+	r := stats.Reliability
+	if r.Examinees != 4 || r.KR20 == nil || *r.KR20 != 0.75 || r.Alpha == nil || *r.Alpha != 0.75 {
+		t.Errorf("reliability = %+v", r)
+	}
+
+	// An essay makes the exam no longer right/wrong, and ungraded answers leave out the examinee
+	questions = append(questions, models.Question{ID: 4, Type: models.Essay, Score: 5})
+	answers = append(answers, models.StudentAnswerWithDetails{StudentID: 1, QuestionID: 4, QuestionScore: 5})
+	stats = ExamStatistics(questions, answers, 4)
+	if stats.Ungraded != 1 || stats.Reliability.Examinees != 3 || stats.Reliability.KR20 != nil || len(stats.Histogram) != 4 {
+		t.Errorf("stats with essay = %+v", stats)
+	}
+}
+
+func TestCache(t *testing.T) {
+	now := time.Now()
+	cache := NewCache[int](time.Minute)
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	compute := func() (int, error) { calls++; return calls, nil }
+
+	if v, hit, _ := cache.Get("all", "v1", compute); v != 1 || hit {
+		t.Fatalf("first get = %d, hit %v", v, hit)
+	}
+	if v, hit, _ := cache.Get("all", "v1", compute); v != 1 || !hit {
+		t.Errorf("same version = %d, hit %v; want cached 1", v, hit)
+	}
+	if v, _, _ := cache.Get("all", "v2", compute); v != 2 {
+		t.Errorf("new version = %d, want recomputed 2", v)
+	}
+	now = now.Add(time.Minute)
+	if v, _, _ := cache.Get("all", "v2", compute); v != 3 {
+		t.Errorf("expired entry = %d, want recomputed 3", v)
+	}
+	if v, hit, _ := cache.Get("all", "", compute); v != 4 || hit {
+		t.Errorf("unknown version = %d, hit %v; want recomputed 4", v, hit)
+	}
+}
+
+func TestProgressReport(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Score: 10, Tags: []string{"aljabar"}},
+		{ID: 2, Score: 10, Tags: []string{"aljabar"}},
+		{ID: 3, Score: 10, Tags: []string{"aljabar", "geometri"}},
+		{ID: 4, Score: 10, Tags: []string{"geometri"}},
+	}
+	week := func(n int) time.Time { return time.Date(2026, 10, 5+7*n, 9, 0, 0, 0, time.UTC) } // Mondays of W41, W42, W43
+	answer := func(student, question uint, score *int, at time.Time) models.StudentAnswerWithDetails {
+		return models.StudentAnswerWithDetails{StudentID: student, QuestionID: question, Score: score, QuestionScore: 10, CreatedAt: at}
+	}
+	answers := []models.StudentAnswerWithDetails{
+		// Student 1 gets worse every week
+		answer(1, 1, intPtr(10), week(0)),
+		answer(1, 2, intPtr(5), week(1)),
+		answer(1, 3, intPtr(0), week(2)),
+		answer(1, 4, intPtr(0), week(2)),
+		answer(1, 5, intPtr(0), week(2)), // Deleted question
+		// Student 2 earns every point; the ungraded answer is left out
+		answer(2, 1, intPtr(10), week(0)),
+		answer(2, 2, intPtr(10), week(1)),
+		answer(2, 3, intPtr(10), week(2)),
+		answer(2, 4, nil, week(2)),
+	}
+
+	report := ProgressReport(models.Student{ID: 1, Class: "X-1"}, questions, answers, "")
+	overall := report.Overall
+	if report.Period != PeriodWeek || overall.Answered != 4 || overall.Score != 37.5 || *overall.ClassAverage != 68.8 {
+		t.Errorf("overall = %+v (class average %v)", overall, *overall.ClassAverage)
+	}
+
+	var periods []string
+	for _, point := range overall.Trend {
+		periods = append(periods, point.Period)
+	}
+	if !slices.Equal(periods, []string{"2026-W41", "2026-W42", "2026-W43"}) || *overall.Trend[0].ClassAverage != 100 {
+		t.Errorf("trend = %+v", overall.Trend)
+	}
+	if *overall.Slope != -50 {
+		t.Errorf("slope = %v, want -50", *overall.Slope)
+	}
+
+	if len(report.Topics) != 2 || report.Topics[0].Topic != "aljabar" || report.Topics[1].Topic != "geometri" {
+		t.Fatalf("topics = %+v", report.Topics)
+	}
+	if algebra := report.Topics[0]; algebra.Mastery != 0.459 || algebra.Level != MasteryStruggling || algebra.Score != 50 {
+		t.Errorf("aljabar = %+v", algebra)
+	}
+	if geometry := report.Topics[1]; geometry.Level != MasteryInsufficient || geometry.Answered != 2 {
+		t.Errorf("geometri = %+v", geometry)
+	}
+
+	want := []string{ReasonBelowClassAverage, ReasonStrugglingTopics, ReasonDeclining}
+	if !report.NeedsAttention || !slices.Equal(report.Reasons, want) {
+		t.Errorf("reasons = %v, want %v", report.Reasons, want)
+	}
+
+	report = ProgressReport(models.Student{ID: 2}, questions, answers, PeriodMonth)
+	if report.NeedsAttention || len(report.Overall.Trend) != 1 || report.Overall.Trend[0].Period != "2026-10" {
+		t.Errorf("student 2 = %+v", report)
+	}
+}
@@ -0,0 +1,57 @@
+package analytics
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache keeps computed statistics per key. An entry is reused while the data
+// version it was computed from is current and it is younger than the TTL.
+type Cache[T any] struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry[T]
+	now     func() time.Time
+}
+
+type cacheEntry[T any] struct {
+	value    T
+	version  string
+	computed time.Time
+}
+
+// NewCache creates a cache; a TTL of 0 disables caching
+func NewCache[T any](ttl time.Duration) *Cache[T] {
+	return &Cache[T]{ttl: ttl, entries: map[string]cacheEntry[T]{}, now: time.Now}
+}
+
+// Get returns the cached value of key for the data version, or calls compute and
+// caches its result. It reports whether the value came from the cache. An empty
+// version means the data version is unknown, so the value is always computed.
+func (c *Cache[T]) Get(key, version string, compute func() (T, error)) (T, bool, error) {
+	if c.ttl > 0 && version != "" {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		c.mu.Unlock()
+		if ok && entry.version == version && c.now().Sub(entry.computed) < c.ttl {
+			return entry.value, true, nil
+		}
+	}
+
+	// Computed without holding the lock; concurrent misses may compute twice
+	value, err := compute()
+	if err != nil || c.ttl <= 0 || version == "" {
+		return value, false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, entry := range c.entries {
+		if now.Sub(entry.computed) >= c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry[T]{value: value, version: version, computed: now}
+	return value, false, nil
+}
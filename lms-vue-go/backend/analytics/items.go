@@ -0,0 +1,244 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"lms-vue-go/backend/models"
+)
+
+// Flag marks a question whose statistics suggest it should be reviewed
+type Flag string
+
+const (
+	FlagTooEasy  Flag = "too_easy" // Almost every examinee earned the points
+	FlagTooHard  Flag = "too_hard" // Almost no examinee earned the points
+	FlagMiskeyed Flag = "miskeyed" // Strong examinees do worse than weak ones, or prefer a distractor over the key
+)
+
+// Thresholds used for the flags
+const (
+	// GroupShare is the share of examinees in the upper and lower group of the discrimination index
+	GroupShare = 0.27
+	// MinResponses is the number of graded responses a question needs before it is flagged
+	MinResponses = 5
+	// TooEasy and TooHard are the difficulty limits of FlagTooEasy and FlagTooHard
+	TooEasy = 0.90
+	TooHard = 0.20
+)
+
+// OptionStats is the distractor analysis of one option of a multiple choice question
+type OptionStats struct {
+	Label      string  `json:"label"` // "A", "B", ...
+	Text       string  `json:"text"`
+	IsKey      bool    `json:"is_key"`
+	Count      int     `json:"count"`
+	Proportion float64 `json:"proportion"`  // Share of the responses that chose this option
+	UpperCount int     `json:"upper_count"` // Chosen by the upper group
+	LowerCount int     `json:"lower_count"` // Chosen by the lower group
+}
+
+// ItemStats holds the item analysis of one question
+type ItemStats struct {
+	QuestionID uint                `json:"question_id"`
+	Label      string              `json:"label"` // "S1", "S2", ... in exam order
+	Question   string              `json:"question"`
+	Type       models.QuestionType `json:"type"`
+	MaxScore   int                 `json:"max_score"`
+	Responses  int                 `json:"responses"` // Graded answers
+	Omitted    int                 `json:"omitted"`   // Examinees without an answer, counted as zero
+	Ungraded   int                 `json:"ungraded"`  // Answers left out until they are graded
+	// Difficulty is the p-value: the average share of the points earned, from 0 (nobody) to 1 (everybody)
+	Difficulty *float64 `json:"difficulty"`
+	// Discrimination is the difficulty in the upper group minus the difficulty in the lower group
+	Discrimination *float64 `json:"discrimination"`
+	// PointBiserial is the correlation between the item score and the score on the rest of the
+	// exam; for right/wrong items this is the (corrected) point-biserial correlation
+	PointBiserial *float64      `json:"point_biserial"`
+	Distractors   []OptionStats `json:"distractors,omitempty"`  // Multiple choice only
+	Unrecognized  int           `json:"unrecognized,omitempty"` // Multiple choice answers that match no option
+	Flags         []Flag        `json:"flags"`
+}
+
+// ItemAnalysis is the item analysis of an exam
+type ItemAnalysis struct {
+	Examinees   int         `json:"examinees"`
+	UpperGroup  int         `json:"upper_group"` // Examinees in the upper and in the lower group
+	Items       []ItemStats `json:"items"`
+	GeneratedAt time.Time   `json:"generated_at"`
+}
+
+// AnalyzeItems computes the item analysis of the questions. Examinees are the students
+// with at least one answer; they are ranked by the share of the points they earned
+// on graded questions to form the upper and lower groups.
+func AnalyzeItems(questions []models.Question, answers []models.StudentAnswerWithDetails) *ItemAnalysis {
+	m := newMatrix(questions, answers)
+	n := len(m.students)
+
+	// Rank examinees from the highest to the lowest total; ties by student ID
+	ranked := make([]int, n)
+	for s := range ranked {
+		ranked[s] = s
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return m.total(ranked[i]) > m.total(ranked[j]) })
+
+	groupSize := int(math.Round(float64(n) * GroupShare))
+	if groupSize < 1 && n >= 2 {
+		groupSize = 1
+	}
+	group := make([]int, n) // 1 upper, -1 lower, 0 middle
+	for i := 0; i < groupSize; i++ {
+		group[ranked[i]] = 1
+		group[ranked[n-1-i]] = -1
+	}
+
+	analysis := &ItemAnalysis{Examinees: n, UpperGroup: groupSize, Items: []ItemStats{}, GeneratedAt: time.Now()}
+	for i, q := range questions {
+		analysis.Items = append(analysis.Items, analyzeItem(m, i, q, group))
+	}
+	return analysis
+}
+
+// analyzeItem computes the statistics of question i
+func analyzeItem(m *matrix, i int, q models.Question, group []int) ItemStats {
+	item := ItemStats{
+		QuestionID: q.ID,
+		Label:      fmt.Sprintf("S%d", i+1),
+		Question:   q.Question,
+		Type:       q.Type,
+		MaxScore:   q.Score,
+		Flags:      []Flag{},
+	}
+
+	var scores, rest, upper, lower []float64
+	for s, row := range m.cells {
+		c := row[i]
+		switch {
+		case !c.answered:
+			item.Omitted++
+		case !c.graded:
+			item.Ungraded++
+			continue
+		default:
+			item.Responses++
+		}
+
+		scores = append(scores, c.fraction())
+		if other := m.possible[s] - c.max; other > 0 {
+			rest = append(rest, (m.earned[s]-c.score)/other)
+		} else {
+			rest = append(rest, math.NaN())
+		}
+		switch group[s] {
+		case 1:
+			upper = append(upper, c.fraction())
+		case -1:
+			lower = append(lower, c.fraction())
+		}
+	}
+
+	if len(scores) > 0 {
+		item.Difficulty = ratio(mean(scores))
+	}
+	if len(upper) > 0 && len(lower) > 0 {
+		item.Discrimination = ratio(mean(upper) - mean(lower))
+	}
+	if r, ok := correlation(withoutNaN(scores, rest)); ok {
+		item.PointBiserial = ratio(r)
+	}
+
+	keyBeaten := false
+	if q.Type == models.MultipleChoice && len(q.Options) > 0 {
+		keyBeaten = analyzeOptions(m, i, q, group, &item)
+	}
+
+	if item.Responses+item.Omitted >= MinResponses && item.Difficulty != nil {
+		switch {
+		case *item.Difficulty >= TooEasy:
+			item.Flags = append(item.Flags, FlagTooEasy)
+		case *item.Difficulty <= TooHard:
+			item.Flags = append(item.Flags, FlagTooHard)
+		}
+		negative := item.Discrimination != nil && *item.Discrimination < 0 ||
+			item.PointBiserial != nil && *item.PointBiserial < 0
+		if negative || keyBeaten {
+			item.Flags = append(item.Flags, FlagMiskeyed)
+		}
+	}
+	return item
+}
+
+// analyzeOptions counts how often each option was chosen. It reports whether a
+// distractor was chosen by more upper group examinees than the key.
+func analyzeOptions(m *matrix, i int, q models.Question, group []int, item *ItemStats) bool {
+	key := optionIndex(q.Answer, q.Options)
+	options := make([]OptionStats, len(q.Options))
+	for o, text := range q.Options {
+		options[o] = OptionStats{Label: string(rune('A' + o)), Text: text, IsKey: o == key}
+	}
+
+	chosen := 0
+	for s, row := range m.cells {
+		c := row[i]
+		if !c.answered {
+			continue
+		}
+		o := optionIndex(c.answer, q.Options)
+		if o < 0 {
+			item.Unrecognized++
+			continue
+		}
+		chosen++
+		options[o].Count++
+		switch group[s] {
+		case 1:
+			options[o].UpperCount++
+		case -1:
+			options[o].LowerCount++
+		}
+	}
+
+	keyBeaten := false
+	for o := range options {
+		if chosen > 0 {
+			options[o].Proportion = round(float64(options[o].Count)/float64(chosen), 3)
+		}
+		if key >= 0 && o != key && options[o].UpperCount > options[key].UpperCount {
+			keyBeaten = true
+		}
+	}
+	item.Distractors = options
+	return keyBeaten
+}
+
+// optionIndex returns the option an answer refers to, either by letter ("B") or by
+// its text, or -1 if it matches no option
+func optionIndex(answer string, options []string) int {
+	answer = strings.TrimSpace(answer)
+	if len(answer) == 1 {
+		if i := int(strings.ToUpper(answer)[0]) - 'A'; i >= 0 && i < len(options) {
+			return i
+		}
+	}
+	for i, option := range options {
+		if strings.EqualFold(strings.TrimSpace(option), answer) {
+			return i
+		}
+	}
+	return -1
+}
+
+// withoutNaN drops the pairs whose y value is NaN
+func withoutNaN(x, y []float64) ([]float64, []float64) {
+	var fx, fy []float64
+	for i := range x {
+		if !math.IsNaN(y[i]) {
+			fx = append(fx, x[i])
+			fy = append(fy, y[i])
+		}
+	}
+	return fx, fy
+}
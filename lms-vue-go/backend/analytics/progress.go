@@ -0,0 +1,295 @@
+package analytics
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"lms-vue-go/backend/models"
+)
+
+// Period is the length of the time buckets of a progress trend
+type Period string
+
+const (
+	PeriodWeek  Period = "week"  // ISO weeks such as "2026-W42"
+	PeriodMonth Period = "month" // Months such as "2026-10"
+)
+
+// key returns the bucket of t
+func (p Period) key(t time.Time) string {
+	if p == PeriodMonth {
+		return t.Format("2006-01")
+	}
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// MasteryLevel summarizes the mastery estimate of a topic
+type MasteryLevel string
+
+const (
+	MasteryInsufficient MasteryLevel = "insufficient_data"
+	MasteryStruggling   MasteryLevel = "struggling"
+	MasteryDeveloping   MasteryLevel = "developing"
+	MasteryMastered     MasteryLevel = "mastered"
+)
+
+// Parameters of the mastery estimate and of the attention flags
+const (
+	// MasteryDecay is the weight of an answer relative to the next newer one, so recent work counts more
+	MasteryDecay = 0.8
+	// MasteryPrior is the assumed mastery before any answer; it counts as MasteryPriorWeight answers
+	MasteryPrior       = 0.5
+	MasteryPriorWeight = 2.0
+	// MinMasteryAnswers is the number of graded answers needed for a mastery level
+	MinMasteryAnswers = 3
+	// MasteredLevel and DevelopingLevel are the lower limits of the mastery levels
+	MasteredLevel   = 0.8
+	DevelopingLevel = 0.5
+	// BelowAverageGap is how many percentage points under the class average count as falling behind
+	BelowAverageGap = 10.0
+	// DecliningSlope is the trend, in percentage points per period, that counts as declining
+	DecliningSlope = -5.0
+)
+
+// Reasons why a student needs attention
+const (
+	ReasonBelowClassAverage = "below_class_average"
+	ReasonStrugglingTopics  = "struggling_topics"
+	ReasonDeclining         = "declining"
+)
+
+// TrendPoint is the result of one period
+type TrendPoint struct {
+	Period       string   `json:"period"`
+	Answered     int      `json:"answered"`      // Graded answers in the period
+	Score        float64  `json:"score"`         // Percentage of the points earned
+	ClassAverage *float64 `json:"class_average"` // Average score of the class in the period; nil if nobody else answered
+}
+
+// TopicProgress is the progress of a student in one topic, or overall if Topic is empty
+type TopicProgress struct {
+	Topic        string       `json:"topic,omitempty"`
+	Answered     int          `json:"answered"` // Graded answers
+	Score        float64      `json:"score"`    // Percentage of the points earned
+	ClassAverage *float64     `json:"class_average"`
+	Mastery      float64      `json:"mastery"` // Estimate from 0 to 1 that weighs recent answers more
+	Level        MasteryLevel `json:"level"`
+	Trend        []TrendPoint `json:"trend"`
+	// Slope is the change of the score in percentage points per period, over the periods with answers
+	Slope *float64 `json:"slope"`
+}
+
+// StudentProgress is the progress report of one student
+type StudentProgress struct {
+	Student        models.Student  `json:"student"`
+	Period         Period          `json:"period"`
+	Overall        TopicProgress   `json:"overall"`
+	Topics         []TopicProgress `json:"topics"` // One per question tag the student answered, by name
+	NeedsAttention bool            `json:"needs_attention"`
+	Reasons        []string        `json:"reasons"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+}
+
+// ProgressReport computes the progress of student over time. Topics are the tags of
+// the questions; classAnswers are the answers of the student's class, used for the
+// class averages. Only graded answers count, dated by when they were first submitted.
+func ProgressReport(student models.Student, questions []models.Question, classAnswers []models.StudentAnswerWithDetails, period Period) *StudentProgress {
+	if period != PeriodMonth {
+		period = PeriodWeek
+	}
+
+	tags := map[uint][]string{}
+	for _, q := range questions {
+		tags[q.ID] = q.Tags
+	}
+	byStudent := map[uint][]models.StudentAnswerWithDetails{}
+	for _, a := range classAnswers {
+		if _, ok := tags[a.QuestionID]; ok && a.Score != nil && a.QuestionScore > 0 {
+			byStudent[a.StudentID] = append(byStudent[a.StudentID], a)
+		}
+	}
+	for _, list := range byStudent {
+		sort.SliceStable(list, func(i, j int) bool {
+			if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+				return list[i].CreatedAt.Before(list[j].CreatedAt)
+			}
+			return list[i].ID < list[j].ID
+		})
+	}
+
+	own := byStudent[student.ID]
+	report := &StudentProgress{
+		Student:     student,
+		Period:      period,
+		Topics:      []TopicProgress{},
+		Reasons:     []string{},
+		GeneratedAt: time.Now(),
+	}
+	report.Overall = topicProgress("", own, byStudent, func(models.StudentAnswerWithDetails) bool { return true }, period)
+
+	var topics []string
+	for _, a := range own {
+		for _, tag := range tags[a.QuestionID] {
+			if !slices.Contains(topics, tag) {
+				topics = append(topics, tag)
+			}
+		}
+	}
+	sort.Strings(topics)
+	struggling := false
+	for _, topic := range topics {
+		inTopic := func(a models.StudentAnswerWithDetails) bool { return slices.Contains(tags[a.QuestionID], topic) }
+		progress := topicProgress(topic, own, byStudent, inTopic, period)
+		struggling = struggling || progress.Level == MasteryStruggling
+		report.Topics = append(report.Topics, progress)
+	}
+
+	overall := report.Overall
+	if overall.Answered > 0 && overall.ClassAverage != nil && overall.Score < *overall.ClassAverage-BelowAverageGap {
+		report.Reasons = append(report.Reasons, ReasonBelowClassAverage)
+	}
+	if struggling {
+		report.Reasons = append(report.Reasons, ReasonStrugglingTopics)
+	}
+	if overall.Slope != nil && *overall.Slope <= DecliningSlope {
+		report.Reasons = append(report.Reasons, ReasonDeclining)
+	}
+	report.NeedsAttention = len(report.Reasons) > 0
+	return report
+}
+
+// topicProgress computes the progress in the answers accepted by keep
+func topicProgress(topic string, own []models.StudentAnswerWithDetails, byStudent map[uint][]models.StudentAnswerWithDetails,
+	keep func(models.StudentAnswerWithDetails) bool, period Period) TopicProgress {
+	mine := filterAnswers(own, keep)
+	progress := TopicProgress{Topic: topic, Answered: len(mine), Trend: []TrendPoint{}}
+	progress.Score = round(percentage(mine), 1)
+	progress.Mastery, progress.Level = mastery(mine)
+
+	// Class averages are the mean of every student's own percentage, so students
+	// who answered more questions do not weigh more
+	var sum float64
+	var students int
+	periodTotals := map[string][2]float64{} // Sum of percentages and number of students
+	for _, list := range byStudent {
+		theirs := filterAnswers(list, keep)
+		if len(theirs) == 0 {
+			continue
+		}
+		sum += percentage(theirs)
+		students++
+		for key, group := range groupByPeriod(theirs, period) {
+			t := periodTotals[key]
+			periodTotals[key] = [2]float64{t[0] + percentage(group), t[1] + 1}
+		}
+	}
+	if students > 0 {
+		avg := round(sum/float64(students), 1)
+		progress.ClassAverage = &avg
+	}
+
+	groups := groupByPeriod(mine, period)
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var x, y []float64
+	for i, key := range keys {
+		point := TrendPoint{Period: key, Answered: len(groups[key]), Score: round(percentage(groups[key]), 1)}
+		if t, ok := periodTotals[key]; ok {
+			avg := round(t[0]/t[1], 1)
+			point.ClassAverage = &avg
+		}
+		progress.Trend = append(progress.Trend, point)
+		x = append(x, float64(i))
+		y = append(y, percentage(groups[key]))
+	}
+	if slope, ok := slope(x, y); ok {
+		s := round(slope, 2)
+		progress.Slope = &s
+	}
+	return progress
+}
+
+// mastery estimates how well a topic is mastered from answers in chronological order:
+// a weighted average of the answer scores in which every older answer weighs
+// MasteryDecay times the next one, pulled towards MasteryPrior while there are few answers
+func mastery(answers []models.StudentAnswerWithDetails) (float64, MasteryLevel) {
+	sum := MasteryPrior * MasteryPriorWeight
+	weights := MasteryPriorWeight
+	weight := 1.0
+	for i := len(answers) - 1; i >= 0; i-- {
+		a := answers[i]
+		sum += weight * float64(*a.Score) / float64(a.QuestionScore)
+		weights += weight
+		weight *= MasteryDecay
+	}
+	estimate := round(sum/weights, 3)
+
+	switch {
+	case len(answers) < MinMasteryAnswers:
+		return estimate, MasteryInsufficient
+	case estimate >= MasteredLevel:
+		return estimate, MasteryMastered
+	case estimate >= DevelopingLevel:
+		return estimate, MasteryDeveloping
+	default:
+		return estimate, MasteryStruggling
+	}
+}
+
+// percentage returns the share of the points earned in graded answers, from 0 to 100
+func percentage(answers []models.StudentAnswerWithDetails) float64 {
+	var earned, possible float64
+	for _, a := range answers {
+		earned += float64(*a.Score)
+		possible += float64(a.QuestionScore)
+	}
+	if possible == 0 {
+		return 0
+	}
+	return earned / possible * 100
+}
+
+// filterAnswers returns the answers accepted by keep
+func filterAnswers(answers []models.StudentAnswerWithDetails, keep func(models.StudentAnswerWithDetails) bool) []models.StudentAnswerWithDetails {
+	var kept []models.StudentAnswerWithDetails
+	for _, a := range answers {
+		if keep(a) {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// groupByPeriod groups answers by the period they were submitted in
+func groupByPeriod(answers []models.StudentAnswerWithDetails, period Period) map[string][]models.StudentAnswerWithDetails {
+	groups := map[string][]models.StudentAnswerWithDetails{}
+	for _, a := range answers {
+		key := period.key(a.CreatedAt)
+		groups[key] = append(groups[key], a)
+	}
+	return groups
+}
+
+// slope returns the least squares slope of y over x. It reports false for fewer than two points.
+func slope(x, y []float64) (float64, bool) {
+	if len(x) < 2 {
+		return 0, false
+	}
+	mx, my := mean(x), mean(y)
+	var sxy, sxx float64
+	for i := range x {
+		sxy += (x[i] - mx) * (y[i] - my)
+		sxx += (x[i] - mx) * (x[i] - mx)
+	}
+	if sxx == 0 {
+		return 0, false
+	}
+	return sxy / sxx, true
+}
@@ -0,0 +1,192 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"lms-vue-go/backend/models"
+)
+
+// DefaultBins is the number of histogram bins when none is given
+const DefaultBins = 10
+
+// Bin is one bar of the score histogram. Scores from From up to (but not
+// including) To fall in the bin; the last bin includes 100.
+type Bin struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// Reliability holds the internal consistency of the exam. Only examinees without
+// ungraded answers are used, since every item needs a score.
+type Reliability struct {
+	Examinees int `json:"examinees"`
+	// Alpha is Cronbach's alpha over the item scores in points
+	Alpha *float64 `json:"alpha"`
+	// KR20 is Kuder-Richardson 20, given when every question is multiple choice (right or wrong)
+	KR20 *float64 `json:"kr20"`
+}
+
+// ExamStats describes the score distribution and reliability of an exam. Scores are
+// percentages of the points an examinee could earn on graded questions.
+type ExamStats struct {
+	Examinees   int                `json:"examinees"`
+	Questions   int                `json:"questions"`
+	Ungraded    int                `json:"ungraded"` // Answers not graded yet
+	Mean        float64            `json:"mean"`
+	Median      float64            `json:"median"`
+	StdDev      float64            `json:"std_dev"` // Sample standard deviation
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Percentiles map[string]float64 `json:"percentiles"` // p10, p25, p50, p75 and p90
+	Histogram   []Bin              `json:"histogram"`
+	Reliability Reliability        `json:"reliability"`
+	GeneratedAt time.Time          `json:"generated_at"`
+}
+
+// ExamStatistics computes the score distribution with the given number of histogram
+// bins and the reliability of the exam formed by questions
+func ExamStatistics(questions []models.Question, answers []models.StudentAnswerWithDetails, bins int) *ExamStats {
+	if bins <= 0 {
+		bins = DefaultBins
+	}
+	m := newMatrix(questions, answers)
+	stats := &ExamStats{
+		Examinees:   len(m.students),
+		Questions:   len(questions),
+		Percentiles: map[string]float64{},
+		GeneratedAt: time.Now(),
+	}
+
+	scores := make([]float64, len(m.students))
+	for s, row := range m.cells {
+		scores[s] = m.total(s) * 100
+		for _, c := range row {
+			if c.answered && !c.graded {
+				stats.Ungraded++
+			}
+		}
+	}
+	sort.Float64s(scores)
+
+	stats.Histogram = histogram(scores, bins)
+	if len(scores) > 0 {
+		stats.Mean = round(mean(scores), 2)
+		stats.Median = round(percentile(scores, 50), 2)
+		stats.Min = round(scores[0], 2)
+		stats.Max = round(scores[len(scores)-1], 2)
+		if n := float64(len(scores)); n > 1 {
+			stats.StdDev = round(math.Sqrt(variance(scores)*n/(n-1)), 2)
+		}
+		for _, p := range []int{10, 25, 50, 75, 90} {
+			stats.Percentiles["p"+strconv.Itoa(p)] = round(percentile(scores, float64(p)), 2)
+		}
+	}
+
+	stats.Reliability = reliability(m)
+	return stats
+}
+
+// percentile returns the p-th percentile of sorted values, interpolating between
+// the closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// histogram counts the scores (0-100) in bins of equal width
+func histogram(scores []float64, bins int) []Bin {
+	width := 100 / float64(bins)
+	histogram := make([]Bin, bins)
+	for i := range histogram {
+		histogram[i] = Bin{From: round(float64(i)*width, 2), To: round(float64(i+1)*width, 2)}
+	}
+	for _, score := range scores {
+		i := int(score / width)
+		if i >= bins {
+			i = bins - 1
+		}
+		if i < 0 {
+			i = 0
+		}
+		histogram[i].Count++
+	}
+	return histogram
+}
+
+// reliability computes Cronbach's alpha and KR-20 over the examinees without ungraded answers
+func reliability(m *matrix) Reliability {
+	k := len(m.questions)
+	dichotomous := k > 0
+	for _, q := range m.questions {
+		if q.Type != models.MultipleChoice {
+			dichotomous = false
+		}
+	}
+
+	var rows [][]cell
+	for _, row := range m.cells {
+		complete := true
+		for _, c := range row {
+			if !c.graded {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			rows = append(rows, row)
+		}
+	}
+
+	result := Reliability{Examinees: len(rows)}
+	if k < 2 || len(rows) < 2 {
+		return result
+	}
+
+	points := make([][]float64, k) // [item][examinee]
+	correct := make([][]float64, k)
+	totals := make([]float64, len(rows))
+	correctTotals := make([]float64, len(rows))
+	for s, row := range rows {
+		for i, c := range row {
+			points[i] = append(points[i], c.score)
+			correct[i] = append(correct[i], c.fraction())
+			totals[s] += c.score
+			correctTotals[s] += c.fraction()
+		}
+	}
+
+	if alpha, ok := cronbachAlpha(points, totals); ok {
+		result.Alpha = ratio(alpha)
+	}
+	if dichotomous {
+		// For 0/1 items the item variance is p*q, which makes alpha equal to KR-20
+		if kr20, ok := cronbachAlpha(correct, correctTotals); ok {
+			result.KR20 = ratio(kr20)
+		}
+	}
+	return result
+}
+
+// cronbachAlpha returns k/(k-1) * (1 - sum of item variances / total variance). It
+// reports false if the total score does not vary.
+func cronbachAlpha(items [][]float64, totals []float64) (float64, bool) {
+	k := float64(len(items))
+	totalVariance := variance(totals)
+	if k < 2 || totalVariance == 0 {
+		return 0, false
+	}
+	itemVariance := 0.0
+	for _, item := range items {
+		itemVariance += variance(item)
+	}
+	return k / (k - 1) * (1 - itemVariance/totalVariance), true
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"lms-vue-go/backend/models"
+)
+
+func chain(t *testing.T) []models.AuditLog {
+	t.Helper()
+	actor := uint(2)
+	at := time.Date(2026, 10, 19, 9, 30, 0, 123456789, time.UTC)
+	entries := []models.AuditLog{
+		{ActorID: &actor, ActorRole: models.RoleTeacher, Action: models.AuditGradeUpdate, EntityType: models.AuditEntityAnswer, EntityID: 7,
+			Before: json.RawMessage(`{"score": null}`), After: json.RawMessage(`{"score":80}`), IP: "10.0.0.5", CreatedAt: at},
+		{Action: models.AuditGradeUpdate, EntityType: models.AuditEntityAnswer, EntityID: 7,
+			Before: json.RawMessage(`{"score":80}`), After: json.RawMessage(`{"score":100}`), IP: "10.0.0.9", CreatedAt: at.Add(time.Minute)},
+		{ActorID: &actor, ActorRole: models.RoleTeacher, Action: models.AuditStudentDelete, EntityType: models.AuditEntityStudent, EntityID: 3,
+			Before: json.RawMessage(`{"name":"Budi"}`), IP: "10.0.0.5", CreatedAt: at.Add(2 * time.Minute)},
+	}
+	prev := GenesisHash
+	for i := range entries {
+		Seal(&entries[i], prev)
+		prev = entries[i].Hash
+	}
+	return entries
+}
+
+func TestVerifyIntactChain(t *testing.T) {
+	entries := chain(t)
+	if i := Verify(entries, GenesisHash); i != -1 {
+		t.Fatalf("intact chain broken at %d", i)
+	}
+	// A stored entry comes back with reformatted JSON and in another time zone
+	stored := entries[0]
+	stored.Before = json.RawMessage(`{"score":null}`)
+	stored.CreatedAt = stored.CreatedAt.In(time.FixedZone("WIB", 7*3600))
+	if Hash(&stored) != entries[0].Hash {
+		t.Error("hash depends on JSON whitespace or time zone")
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	edited := chain(t)
+	edited[1].After = json.RawMessage(`{"score":60}`)
+	if i := Verify(edited, GenesisHash); i != 1 {
+		t.Errorf("edited entry: broken at %d, want 1", i)
+	}
+
+	removed := chain(t)
+	removed = append(removed[:1], removed[2:]...)
+	if i := Verify(removed, GenesisHash); i != 1 {
+		t.Errorf("removed entry: broken at %d, want 1", i)
+	}
+
+	// Re-hashing an edited entry does not help: the next entry still links to the old hash
+	rehashed := chain(t)
+	rehashed[0].IP = "192.168.1.1"
+	Seal(&rehashed[0], GenesisHash)
+	if i := Verify(rehashed, GenesisHash); i != 1 {
+		t.Errorf("rehashed entry: broken at %d, want 1", i)
+	}
+}
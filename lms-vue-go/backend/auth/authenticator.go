@@ -0,0 +1,89 @@
+// Package auth contains the login backends (local passwords, LDAP) behind a
+// common Authenticator interface so they can be chained.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/logging"
+	"lms-vue-go/backend/models"
+)
+
+// ErrInvalidCredentials is returned when a backend does not accept the username and password
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// Authenticator verifies a username and password and returns the local user
+type Authenticator interface {
+	// Name identifies the backend in logs
+	Name() string
+	// Authenticate returns ErrInvalidCredentials when the credentials are rejected
+	// or the user is unknown to this backend. ctx carries the request for logging.
+	Authenticate(ctx context.Context, username, password string) (*models.User, error)
+}
+
+// Chain tries several authenticators in order and returns the first success
+type Chain []Authenticator
+
+// Name returns the names of the chained backends
+func (c Chain) Name() string {
+	name := "chain("
+	for i, a := range c {
+		if i > 0 {
+			name += ","
+		}
+		name += a.Name()
+	}
+	return name + ")"
+}
+
+// Authenticate returns the first successful result. A backend failure (e.g. the
+// directory server is down) does not stop the chain; it is only returned when no
+// backend gave a definite answer, so local users can still log in during an outage.
+func (c Chain) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	var backendErr error
+	rejected := false
+
+	for _, a := range c {
+		user, err := a.Authenticate(ctx, username, password)
+		switch {
+		case err == nil:
+			return user, nil
+		case errors.Is(err, ErrInvalidCredentials):
+			rejected = true
+		default:
+			logging.FromContext(ctx).Error("Authenticator failed", "backend", a.Name(), "error", err)
+			backendErr = err
+		}
+	}
+
+	if rejected || backendErr == nil {
+		return nil, ErrInvalidCredentials
+	}
+	return nil, backendErr
+}
+
+// NewFromConfig builds the authenticator chain listed in AUTH_BACKENDS
+func NewFromConfig(cfg config.AuthConfig) (Authenticator, error) {
+	var chain Chain
+	for _, backend := range cfg.Backends {
+		switch backend {
+		case "local":
+			chain = append(chain, NewLocalAuthenticator())
+		case "ldap":
+			chain = append(chain, NewLDAPAuthenticator(config.DefaultLDAPConfig()))
+		default:
+			return nil, fmt.Errorf("unknown authentication backend %q", backend)
+		}
+	}
+
+	if len(chain) == 0 {
+		return nil, errors.New("no authentication backends configured")
+	}
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+	return chain, nil
+}
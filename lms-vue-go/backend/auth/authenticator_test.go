@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+)
+
+// fakeAuthenticator returns a fixed result
+type fakeAuthenticator struct {
+	name string
+	user *models.User
+	err  error
+}
+
+func (f fakeAuthenticator) Name() string { return f.name }
+
+func (f fakeAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	return f.user, f.err
+}
+
+func TestChainFallsThroughToNextBackend(t *testing.T) {
+	want := &models.User{ID: 7, Username: "guru"}
+	chain := Chain{
+		fakeAuthenticator{name: "local", err: ErrInvalidCredentials},
+		fakeAuthenticator{name: "ldap", user: want},
+	}
+
+	user, err := chain.Authenticate(context.Background(), "guru", "rahasia")
+	if err != nil || user != want {
+		t.Fatalf("expected ldap user, got %v, %v", user, err)
+	}
+}
+
+func TestChainReportsRejectionDespiteBackendOutage(t *testing.T) {
+	outage := errors.New("connection refused")
+	chain := Chain{
+		fakeAuthenticator{name: "local", err: ErrInvalidCredentials},
+		fakeAuthenticator{name: "ldap", err: outage},
+	}
+	if _, err := chain.Authenticate(context.Background(), "siswa", "salah"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	chain = Chain{fakeAuthenticator{name: "ldap", err: outage}}
+	if _, err := chain.Authenticate(context.Background(), "siswa", "salah"); !errors.Is(err, outage) {
+		t.Errorf("expected backend error when no backend answered, got %v", err)
+	}
+}
+
+func TestNewFromConfigRejectsUnknownBackend(t *testing.T) {
+	if _, err := NewFromConfig(config.AuthConfig{Backends: []string{"local", "kerberos"}}); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestPasswordMatchesUnknownUser(t *testing.T) {
+	if PasswordMatches(nil, dummyPassword) {
+		t.Error("unknown user must never match, even with the dummy password")
+	}
+	if !PasswordMatches(&models.User{Password: "admin123"}, "admin123") {
+		t.Error("expected matching password to succeed")
+	}
+}
+
+func TestMapRoleReportsMatch(t *testing.T) {
+	mapping := map[string]string{"cn=guru,ou=groups,dc=sekolah,dc=id": "teacher"}
+
+	role, matched := MapRole([]string{"cn=guru,ou=groups,dc=sekolah,dc=id"}, mapping, "student")
+	if role != models.RoleTeacher || !matched {
+		t.Errorf("expected mapped teacher role, got %s matched=%v", role, matched)
+	}
+
+	role, matched = MapRole([]string{"cn=lain,dc=sekolah,dc=id"}, mapping, "student")
+	if role != models.RoleStudent || matched {
+		t.Errorf("expected default student role without match, got %s matched=%v", role, matched)
+	}
+}
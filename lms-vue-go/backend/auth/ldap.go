@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/logging"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ldapTimeout bounds connecting and searching so a slow directory cannot hang logins
+const ldapTimeout = 10 * time.Second
+
+// LDAPAuthenticator authenticates against an LDAP or Active Directory server
+type LDAPAuthenticator struct {
+	Config config.LDAPConfig
+}
+
+// NewLDAPAuthenticator creates a new LDAP authenticator
+func NewLDAPAuthenticator(cfg config.LDAPConfig) *LDAPAuthenticator {
+	return &LDAPAuthenticator{Config: cfg}
+}
+
+// Name returns the backend name
+func (a *LDAPAuthenticator) Name() string {
+	return "ldap"
+}
+
+// Authenticate searches the user with the service account, binds as the user to
+// check the password, maps groups to a role and creates the local user on first login
+func (a *LDAPAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	// An empty password would be an unauthenticated bind, which many servers accept
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	if a.Config.URL == "" || a.Config.BaseDN == "" {
+		return nil, errors.New("ldap is not configured")
+	}
+
+	conn, err := a.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if a.Config.BindDN != "" {
+		if err := conn.Bind(a.Config.BindDN, a.Config.BindPassword); err != nil {
+			return nil, fmt.Errorf("ldap service bind failed: %v", err)
+		}
+	}
+
+	entry, err := a.findEntry(conn, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap user bind failed: %v", err)
+	}
+
+	return a.syncUser(ctx, username, entry)
+}
+
+// connect dials the directory and upgrades to TLS when configured
+func (a *LDAPAuthenticator) connect() (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: a.Config.InsecureSkipVerify}
+
+	conn, err := ldap.DialURL(a.Config.URL, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("ldap connect failed: %v", err)
+	}
+	conn.SetTimeout(ldapTimeout)
+
+	if a.Config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap starttls failed: %v", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// findEntry searches exactly one directory entry for the username
+func (a *LDAPAuthenticator) findEntry(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	request := ldap.NewSearchRequest(
+		a.Config.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2, // Two results are enough to detect an ambiguous filter
+		int(ldapTimeout.Seconds()),
+		false,
+		fmt.Sprintf(a.Config.UserFilter, ldap.EscapeFilter(username)),
+		[]string{"dn", a.Config.EmailAttribute, a.Config.NameAttribute, a.Config.GroupAttribute},
+		nil,
+	)
+
+	result, err := conn.Search(request)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("ldap search failed: %v", err)
+	}
+
+	switch {
+	case result == nil || len(result.Entries) == 0:
+		return nil, ErrInvalidCredentials
+	case len(result.Entries) > 1:
+		return nil, fmt.Errorf("ldap filter matched more than one entry for %q", username)
+	}
+
+	return result.Entries[0], nil
+}
+
+// ldapIssuer is the issuer stored in user_identities for directory logins; the
+// subject is the entry DN in lower case
+const ldapIssuer = "ldap"
+
+// syncUser finds the local user linked to a directory entry, or creates and links
+// one on first login, and applies the mapped role. Local accounts are never taken
+// over by a directory entry with the same username.
+func (a *LDAPAuthenticator) syncUser(ctx context.Context, username string, entry *ldap.Entry) (*models.User, error) {
+	userRepo := repository.NewUserRepository().WithContext(ctx)
+	identityRepo := repository.NewIdentityRepository().WithContext(ctx)
+
+	// Group DNs are compared case-insensitively, as LDAP does
+	var groups []string
+	for _, group := range entry.GetAttributeValues(a.Config.GroupAttribute) {
+		groups = append(groups, strings.ToLower(group))
+	}
+	role, matched := MapRole(groups, a.Config.GroupRoleMapping, a.Config.DefaultRole)
+
+	subject := strings.ToLower(entry.DN)
+	identity, err := identityRepo.FindByIssuerAndSubject(ldapIssuer, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity == nil {
+		existing, err := userRepo.FindByUsername(username)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			logging.FromContext(ctx).Warn("LDAP entry matches a local user that is not linked to the directory, login refused", "dn", entry.DN, "username", username)
+			return nil, ErrInvalidCredentials
+		}
+		if !a.Config.AutoCreate {
+			return nil, ErrInvalidCredentials
+		}
+
+		user, err := ProvisionUser(ctx, ExternalProfile{
+			Username: username,
+			Email:    entry.GetAttributeValue(a.Config.EmailAttribute),
+			Name:     entry.GetAttributeValue(a.Config.NameAttribute),
+			Role:     role,
+		})
+		if err != nil {
+			return nil, err
+		}
+		link := models.UserIdentity{UserID: user.ID, Issuer: ldapIssuer, Subject: subject}
+		if err := identityRepo.Create(&link); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	user, err := userRepo.FindByID(identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("linked user no longer exists")
+	}
+
+	// The directory is the source of truth for the roles of the users it provisioned
+	if matched && user.Role != role {
+		if err := userRepo.UpdateRole(user.ID, role); err != nil {
+			return nil, err
+		}
+		user.Role = role
+	}
+
+	return user, nil
+}
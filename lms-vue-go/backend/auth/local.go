@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+)
+
+// dummyPassword is compared when the username does not exist so both paths take the same time
+const dummyPassword = "lms-dummy-password-for-constant-timing"
+
+// LocalAuthenticator checks passwords stored in the users table
+type LocalAuthenticator struct{}
+
+// NewLocalAuthenticator creates a new local authenticator
+func NewLocalAuthenticator() *LocalAuthenticator {
+	return &LocalAuthenticator{}
+}
+
+// Name returns the backend name
+func (a *LocalAuthenticator) Name() string {
+	return "local"
+}
+
+// Authenticate looks the user up by username and compares the password
+func (a *LocalAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	user, err := repository.NewUserRepository().WithContext(ctx).FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Not-found and wrong-password go through the same comparison to prevent user enumeration
+	if !PasswordMatches(user, password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// PasswordMatches compares a password in constant time. When user is nil the
+// comparison still runs against dummyPassword so callers cannot tell which usernames exist.
+func PasswordMatches(user *models.User, password string) bool {
+	stored := dummyPassword
+	if user != nil {
+		stored = user.Password
+	}
+
+	match := subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+	return user != nil && match
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"lms-vue-go/backend/logging"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+)
+
+// ExternalProfile describes a user coming from an external identity source (SSO or LDAP)
+type ExternalProfile struct {
+	Username      string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Class         string
+	Role          models.Role
+}
+
+// rolePriority decides which role wins when a user matches several mappings
+var rolePriority = map[models.Role]int{
+	models.RoleStudent: 1,
+	models.RoleTeacher: 2,
+	models.RoleAdmin:   3,
+}
+
+// usernameCleaner removes characters that are not allowed in provisioned usernames
+var usernameCleaner = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// MapRole returns the highest role mapped from the values. matched is false when
+// no value had a mapping and the default role was used.
+func MapRole(values []string, mapping map[string]string, defaultRole string) (role models.Role, matched bool) {
+	role = models.Role(defaultRole)
+	if !models.IsValidRole(role) {
+		role = models.RoleStudent
+	}
+
+	for _, value := range values {
+		mapped := models.Role(mapping[value])
+		if !models.IsValidRole(mapped) {
+			continue
+		}
+		if !matched || rolePriority[mapped] > rolePriority[role] {
+			role = mapped
+			matched = true
+		}
+	}
+
+	return role, matched
+}
+
+// ProvisionUser creates a local user for an external profile, together with the
+// students row for students like Register does. The local password is random
+// because these users authenticate against their identity source.
+func ProvisionUser(ctx context.Context, profile ExternalProfile) (*models.User, error) {
+	userRepo := repository.NewUserRepository().WithContext(ctx)
+	studentRepo := repository.NewStudentRepository().WithContext(ctx)
+
+	if profile.Email == "" {
+		return nil, fmt.Errorf("cannot provision %q without an email address", profile.Username)
+	}
+
+	username, err := availableUsername(ctx, profile.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		Username: username,
+		Password: base64.RawURLEncoding.EncodeToString(buf),
+		Email:    profile.Email,
+		Role:     profile.Role,
+	}
+	if err := userRepo.Create(&user); err != nil {
+		return nil, err
+	}
+
+	if profile.EmailVerified {
+		if err := userRepo.MarkEmailVerified(user.ID); err != nil {
+			logging.FromContext(ctx).Error("Error marking email verified", "error", err)
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if user.Role == models.RoleStudent {
+		name := profile.Name
+		if name == "" {
+			name = username
+		}
+		class := profile.Class
+		if class == "" {
+			class = "Unassigned"
+		}
+
+		student := models.Student{Name: name, Class: class, Email: user.Email}
+		if err := studentRepo.Create(&student, user.ID); err != nil {
+			logging.FromContext(ctx).Error("Error creating student record", "error", err)
+		}
+	}
+
+	return &user, nil
+}
+
+// availableUsername cleans a username and appends a number when it is already taken
+func availableUsername(ctx context.Context, base string) (string, error) {
+	userRepo := repository.NewUserRepository().WithContext(ctx)
+
+	base = strings.Trim(usernameCleaner.ReplaceAllString(base, ""), ".-_")
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	candidate := base
+	for i := 2; i < 100; i++ {
+		existing, err := userRepo.FindByUsername(candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+
+	return "", fmt.Errorf("no available username for %q", base)
+}
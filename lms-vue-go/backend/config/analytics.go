@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+// AnalyticsConfig holds configuration for question and exam statistics
+type AnalyticsConfig struct {
+	// CacheTTL is how long computed statistics are reused while the answers are
+	// unchanged; 0 disables the cache
+	CacheTTL time.Duration
+	// SimilarityThreshold is the score (0-1) from which two essay answers are reported as similar
+	SimilarityThreshold float64
+}
+
+// DefaultAnalyticsConfig returns the analytics configuration, overridable through environment variables
+func DefaultAnalyticsConfig() AnalyticsConfig {
+	return AnalyticsConfig{
+		CacheTTL:            time.Duration(getEnvInt("ANALYTICS_CACHE_TTL_MINUTES", 10)) * time.Minute,
+		SimilarityThreshold: float64(getEnvInt("SIMILARITY_THRESHOLD_PERCENT", 50)) / 100,
+	}
+}
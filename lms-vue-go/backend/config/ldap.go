@@ -0,0 +1,73 @@
+package config
+
+import "strings"
+
+// LDAPConfig holds LDAP / Active Directory authentication configuration
+type LDAPConfig struct {
+	// URL of the directory, e.g. ldap://dc.sekolah.local:389 or ldaps://dc.sekolah.local:636
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	// BindDN and BindPassword are the service account used to search for users; empty means anonymous search
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// UserFilter must contain one %s that is replaced by the escaped username,
+	// e.g. (uid=%s) for OpenLDAP or (sAMAccountName=%s) for Active Directory
+	UserFilter     string
+	EmailAttribute string
+	NameAttribute  string
+	GroupAttribute string
+	// GroupRoleMapping maps group DNs to LMS roles
+	GroupRoleMapping map[string]string
+	DefaultRole      string
+	// AutoCreate creates a local user on the first successful LDAP login
+	AutoCreate bool
+}
+
+// AuthConfig selects the login backends
+type AuthConfig struct {
+	// Backends are tried in order, e.g. ["local", "ldap"]
+	Backends []string
+}
+
+// DefaultAuthConfig returns the authentication backend configuration from AUTH_BACKENDS
+func DefaultAuthConfig() AuthConfig {
+	var backends []string
+	for _, backend := range strings.Split(getEnv("AUTH_BACKENDS", "local"), ",") {
+		if backend = strings.TrimSpace(strings.ToLower(backend)); backend != "" {
+			backends = append(backends, backend)
+		}
+	}
+	return AuthConfig{Backends: backends}
+}
+
+// DefaultLDAPConfig returns the LDAP configuration from environment variables.
+// LDAP_GROUP_ROLE_MAPPING is a semicolon separated list of groupDN:role pairs such as
+// "cn=guru,ou=groups,dc=sekolah,dc=id:teacher;cn=it,ou=groups,dc=sekolah,dc=id:admin".
+func DefaultLDAPConfig() LDAPConfig {
+	mapping := map[string]string{}
+	for _, pair := range strings.Split(getEnv("LDAP_GROUP_ROLE_MAPPING", ""), ";") {
+		idx := strings.LastIndex(pair, ":")
+		if idx <= 0 {
+			continue
+		}
+		mapping[strings.ToLower(strings.TrimSpace(pair[:idx]))] = strings.TrimSpace(pair[idx+1:])
+	}
+
+	return LDAPConfig{
+		URL:                getEnv("LDAP_URL", ""),
+		StartTLS:           getEnv("LDAP_STARTTLS", "false") == "true",
+		InsecureSkipVerify: getEnv("LDAP_INSECURE_SKIP_VERIFY", "false") == "true",
+		BindDN:             getEnv("LDAP_BIND_DN", ""),
+		BindPassword:       getEnv("LDAP_BIND_PASSWORD", ""),
+		BaseDN:             getEnv("LDAP_BASE_DN", ""),
+		UserFilter:         getEnv("LDAP_USER_FILTER", "(uid=%s)"),
+		EmailAttribute:     getEnv("LDAP_EMAIL_ATTRIBUTE", "mail"),
+		NameAttribute:      getEnv("LDAP_NAME_ATTRIBUTE", "cn"),
+		GroupAttribute:     getEnv("LDAP_GROUP_ATTRIBUTE", "memberOf"),
+		GroupRoleMapping:   mapping,
+		DefaultRole:        getEnv("LDAP_DEFAULT_ROLE", "student"),
+		AutoCreate:         getEnv("LDAP_AUTO_CREATE", "true") == "true",
+	}
+}
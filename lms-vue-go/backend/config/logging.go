@@ -0,0 +1,21 @@
+package config
+
+// LogConfig holds configuration for the server logs
+type LogConfig struct {
+	// Level is the minimum level written: debug, info, warn or error
+	Level string
+	// Format is json (default) or text
+	Format string
+	// Redact replaces passwords, tokens and secrets in log records with [REDACTED].
+	// Turn it off only in development, e.g. to read links sent by the log mail driver.
+	Redact bool
+}
+
+// DefaultLogConfig returns the logging configuration, overridable through environment variables
+func DefaultLogConfig() LogConfig {
+	return LogConfig{
+		Level:  getEnv("LOG_LEVEL", "info"),
+		Format: getEnv("LOG_FORMAT", "json"),
+		Redact: getEnv("LOG_REDACT", "true") != "false",
+	}
+}
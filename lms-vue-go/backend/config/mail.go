@@ -0,0 +1,30 @@
+package config
+
+// MailConfig holds configuration for outgoing email
+type MailConfig struct {
+	// Driver selects the mailer implementation: "smtp" or "log"
+	Driver   string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// LogPath is the file used by the log driver; empty means the standard logger
+	LogPath string
+	// AppURL is the frontend base URL used to build links inside emails
+	AppURL string
+}
+
+// DefaultMailConfig returns the mail configuration, overridable through environment variables
+func DefaultMailConfig() MailConfig {
+	return MailConfig{
+		Driver:   getEnv("MAIL_DRIVER", "log"),
+		Host:     getEnv("SMTP_HOST", "localhost"),
+		Port:     getEnvInt("SMTP_PORT", 587),
+		Username: getEnv("SMTP_USERNAME", ""),
+		Password: getEnv("SMTP_PASSWORD", ""),
+		From:     getEnv("MAIL_FROM", "LMS <no-reply@example.com>"),
+		LogPath:  getEnv("MAIL_LOG_PATH", ""),
+		AppURL:   getEnv("APP_URL", "http://localhost:8080"),
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import "strings"
+
+// OIDCConfig holds OpenID Connect single sign-on configuration
+type OIDCConfig struct {
+	// Issuer is the IdP issuer URL; SSO is disabled when empty
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the backend callback URL registered at the IdP
+	RedirectURL string
+	Scopes      []string
+	// UsernameClaim, NameClaim and ClassClaim select the claims used to provision users
+	UsernameClaim string
+	NameClaim     string
+	ClassClaim    string
+	// RoleClaim holds a string or list of strings (e.g. "groups") mapped through RoleMapping
+	RoleClaim string
+	// RoleMapping maps claim values to LMS roles, e.g. {"guru": "teacher"}
+	RoleMapping map[string]string
+	DefaultRole string
+}
+
+// Enabled reports whether OIDC login is configured
+func (c OIDCConfig) Enabled() bool {
+	return c.Issuer != "" && c.ClientID != ""
+}
+
+// DefaultOIDCConfig returns the OIDC configuration from environment variables.
+// OIDC_ROLE_MAPPING is a comma separated list of claim=role pairs such as "guru=teacher,staf-it=admin".
+func DefaultOIDCConfig() OIDCConfig {
+	mapping := map[string]string{}
+	for _, pair := range strings.Split(getEnv("OIDC_ROLE_MAPPING", ""), ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if ok && strings.TrimSpace(key) != "" {
+			mapping[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	return OIDCConfig{
+		Issuer:        strings.TrimSuffix(getEnv("OIDC_ISSUER", ""), "/"),
+		ClientID:      getEnv("OIDC_CLIENT_ID", ""),
+		ClientSecret:  getEnv("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:   getEnv("OIDC_REDIRECT_URL", "http://localhost:3001/api/auth/oidc/callback"),
+		Scopes:        strings.Fields(getEnv("OIDC_SCOPES", "openid profile email")),
+		UsernameClaim: getEnv("OIDC_USERNAME_CLAIM", "preferred_username"),
+		NameClaim:     getEnv("OIDC_NAME_CLAIM", "name"),
+		ClassClaim:    getEnv("OIDC_CLASS_CLAIM", ""),
+		RoleClaim:     getEnv("OIDC_ROLE_CLAIM", "groups"),
+		RoleMapping:   mapping,
+		DefaultRole:   getEnv("OIDC_DEFAULT_ROLE", "student"),
+	}
+}
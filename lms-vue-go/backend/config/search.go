@@ -0,0 +1,20 @@
+package config
+
+// SearchConfig holds configuration for the question bank search
+type SearchConfig struct {
+	// Mode selects the search implementation: "fulltext" uses the MySQL FULLTEXT
+	// index, "like" uses plain LIKE matching that works on any SQL backend
+	Mode string
+}
+
+// DefaultSearchConfig returns the search configuration, overridable through environment variables
+func DefaultSearchConfig() SearchConfig {
+	return SearchConfig{
+		Mode: getEnv("SEARCH_MODE", "fulltext"),
+	}
+}
+
+// FullText reports whether the MySQL FULLTEXT index should be used
+func (c SearchConfig) FullText() bool {
+	return c.Mode != "like"
+}
@@ -0,0 +1,26 @@
+package config
+
+import "strings"
+
+// TwoFactorConfig holds the two-factor authentication policy
+type TwoFactorConfig struct {
+	// Issuer is the name shown in authenticator apps
+	Issuer string
+	// RequiredRoles lists roles that must enroll in 2FA before they can log in
+	RequiredRoles []string
+}
+
+// DefaultTwoFactorConfig returns the 2FA policy, overridable through environment variables.
+// TOTP_REQUIRED_ROLES is a comma separated list such as "admin,teacher".
+func DefaultTwoFactorConfig() TwoFactorConfig {
+	var roles []string
+	for _, role := range strings.Split(getEnv("TOTP_REQUIRED_ROLES", ""), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return TwoFactorConfig{
+		Issuer:        getEnv("TOTP_ISSUER", "LMS"),
+		RequiredRoles: roles,
+	}
+}
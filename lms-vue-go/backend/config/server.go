@@ -0,0 +1,21 @@
+package config
+
+import "strings"
+
+// ServerConfig holds HTTP server configuration
+type ServerConfig struct {
+	// TrustedProxies lists proxy addresses allowed to set X-Forwarded-For.
+	// Empty means the client IP is always taken from the connection.
+	TrustedProxies []string
+}
+
+// DefaultServerConfig returns the server configuration, overridable through environment variables
+func DefaultServerConfig() ServerConfig {
+	var proxies []string
+	for _, proxy := range strings.Split(getEnv("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return ServerConfig{TrustedProxies: proxies}
+}
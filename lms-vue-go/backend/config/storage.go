@@ -0,0 +1,41 @@
+package config
+
+import "time"
+
+// StorageConfig holds configuration for uploaded files
+type StorageConfig struct {
+	// Driver selects the blob store: "local" or "s3"
+	Driver string
+	// LocalPath is the directory used by the local driver
+	LocalPath string
+	// S3 settings; any S3-compatible server such as MinIO can be used through S3Endpoint
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	// S3PathStyle addresses the bucket in the path instead of the host name (needed for MinIO)
+	S3PathStyle bool
+	// SignedURLTTL is how long a signed link to a private file stays valid
+	SignedURLTTL time.Duration
+	// Upload size limits in bytes
+	MaxImageSize      int64
+	MaxAttachmentSize int64
+}
+
+// DefaultStorageConfig returns the storage configuration, overridable through environment variables
+func DefaultStorageConfig() StorageConfig {
+	return StorageConfig{
+		Driver:            getEnv("STORAGE_DRIVER", "local"),
+		LocalPath:         getEnv("STORAGE_LOCAL_PATH", "uploads"),
+		S3Endpoint:        getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:          getEnv("S3_BUCKET", ""),
+		S3AccessKey:       getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:       getEnv("S3_SECRET_KEY", ""),
+		S3PathStyle:       getEnv("S3_PATH_STYLE", "false") == "true",
+		SignedURLTTL:      time.Duration(getEnvInt("SIGNED_URL_TTL_MINUTES", 60)) * time.Minute,
+		MaxImageSize:      int64(getEnvInt("UPLOAD_MAX_IMAGE_MB", 5)) << 20,
+		MaxAttachmentSize: int64(getEnvInt("UPLOAD_MAX_ATTACHMENT_MB", 20)) << 20,
+	}
+}
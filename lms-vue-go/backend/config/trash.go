@@ -0,0 +1,19 @@
+package config
+
+import "time"
+
+// TrashConfig holds configuration for soft-deleted questions and students
+type TrashConfig struct {
+	// Retention is how long deleted rows stay restorable before they are purged
+	Retention time.Duration
+	// PurgeInterval is how often expired rows are purged; 0 disables the scheduled purge
+	PurgeInterval time.Duration
+}
+
+// DefaultTrashConfig returns the trash configuration, overridable through environment variables
+func DefaultTrashConfig() TrashConfig {
+	return TrashConfig{
+		Retention:     time.Duration(getEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		PurgeInterval: time.Duration(getEnvInt("TRASH_PURGE_INTERVAL_HOURS", 24)) * time.Hour,
+	}
+}
@@ -11,6 +11,8 @@ CREATE TABLE IF NOT EXISTS users (
     password VARCHAR(255) NOT NULL,
     email VARCHAR(100) NOT NULL UNIQUE,
     role ENUM('admin', 'teacher', 'student') NOT NULL DEFAULT 'student',
+    email_verified_at TIMESTAMP NULL DEFAULT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 ) ENGINE=InnoDB;
@@ -22,7 +24,10 @@ CREATE TABLE IF NOT EXISTS students (
     name VARCHAR(100) NOT NULL,
     class VARCHAR(20) NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    INDEX idx_students_updated_at (updated_at),
+    INDEX idx_students_deleted_at (deleted_at),
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 ) ENGINE=InnoDB;
 
@@ -35,8 +40,15 @@ CREATE TABLE IF NOT EXISTS questions (
     answer TEXT NULL,
     image_url VARCHAR(255) NULL,
     score INT NOT NULL DEFAULT 1,
+    file_rule JSON NULL,
+    current_version INT NOT NULL DEFAULT 1,
+    search_text TEXT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+    updated_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    INDEX idx_questions_updated_at (updated_at),
+    INDEX idx_questions_deleted_at (deleted_at),
+    FULLTEXT INDEX ft_questions_search (search_text)
 ) ENGINE=InnoDB;
 
 -- Create student_answers table
@@ -46,12 +58,211 @@ CREATE TABLE IF NOT EXISTS student_answers (
     question_id INT NOT NULL,
     answer TEXT NOT NULL,
     score INT NULL,
+    feedback TEXT NULL,
+    question_version INT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+    INDEX idx_student_answers_updated_at (updated_at),
+    INDEX idx_student_answers_created_at (created_at),
+    INDEX idx_student_answers_score (score),
     FOREIGN KEY (student_id) REFERENCES students(id) ON DELETE CASCADE,
     FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE
 ) ENGINE=InnoDB;
 
+-- Create user_tokens table (single-use email verification and password reset tokens)
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    purpose ENUM('email_verification', 'password_reset') NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_tokens_user_purpose (user_id, purpose),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Create login_attempts table (failed login tracking per username and IP)
+CREATE TABLE IF NOT EXISTS login_attempts (
+    kind ENUM('username', 'ip') NOT NULL,
+    attempt_key VARCHAR(100) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (kind, attempt_key)
+) ENGINE=InnoDB;
+
+-- Create user_totp table (TOTP two-factor authentication secrets)
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id INT PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    enabled_at TIMESTAMP NULL DEFAULT NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Create user_recovery_codes table (hashed single-use 2FA recovery codes)
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_recovery_code (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Create user_identities table (links to external identity providers for SSO)
+CREATE TABLE IF NOT EXISTS user_identities (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_identity (issuer, subject),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Create api_tokens table (personal API tokens, only the SHA-256 hash is stored)
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(16) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    scopes JSON NOT NULL,
+    expires_at TIMESTAMP NULL DEFAULT NULL,
+    last_used_at TIMESTAMP NULL DEFAULT NULL,
+    revoked_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_api_token_hash (token_hash),
+    INDEX idx_api_tokens_user (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Create question_tags table (tags used for searching and grouping questions)
+CREATE TABLE IF NOT EXISTS question_tags (
+    question_id INT NOT NULL,
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (question_id, tag),
+    INDEX idx_question_tags_tag (tag),
+    FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Create question_versions table (immutable revisions; answers reference the version answered)
+CREATE TABLE IF NOT EXISTS question_versions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    question_id INT NOT NULL,
+    version INT NOT NULL,
+    type ENUM('multiple_choice', 'essay') NOT NULL,
+    question TEXT NOT NULL,
+    options JSON NULL,
+    answer TEXT NULL,
+    image_url VARCHAR(255) NULL,
+    score INT NOT NULL,
+    tags JSON NULL,
+    file_rule JSON NULL,
+    created_by INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_question_version (question_id, version),
+    FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB;
+
+-- Create uploads table (metadata of uploaded files; the content is in the configured blob store)
+CREATE TABLE IF NOT EXISTS uploads (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    storage_key VARCHAR(255) NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    purpose VARCHAR(30) NOT NULL,
+    uploaded_by INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_upload_storage_key (storage_key),
+    INDEX idx_uploads_uploaded_by (uploaded_by),
+    FOREIGN KEY (uploaded_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB;
+
+-- Create answer_files table (files attached to essay answers)
+CREATE TABLE IF NOT EXISTS answer_files (
+    answer_id INT NOT NULL,
+    upload_id INT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (answer_id, upload_id),
+    UNIQUE KEY uq_answer_files_upload (upload_id),
+    FOREIGN KEY (answer_id) REFERENCES student_answers(id) ON DELETE CASCADE,
+    FOREIGN KEY (upload_id) REFERENCES uploads(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Create proctoring_events table (browser events during exam attempts)
+CREATE TABLE IF NOT EXISTS proctoring_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    student_id INT NOT NULL,
+    session_id VARCHAR(64) CHARACTER SET ascii NOT NULL,
+    event_type ENUM('tab_hidden', 'tab_visible', 'focus_lost', 'focus_gained', 'paste', 'copy', 'fullscreen_exit', 'fullscreen_enter') NOT NULL,
+    question_id INT NULL DEFAULT NULL,
+    length INT NOT NULL DEFAULT 0,
+    occurred_at TIMESTAMP(3) NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_proctoring_events_session (student_id, session_id, occurred_at),
+    INDEX idx_proctoring_events_question (student_id, question_id),
+    FOREIGN KEY (student_id) REFERENCES students(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Create audit_logs table (append-only, hash-chained log of data changes)
+-- before_data and after_data are TEXT rather than JSON: MySQL normalizes JSON
+-- values, which would change the bytes covered by the hash
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor_id INT NULL DEFAULT NULL,
+    actor_role VARCHAR(20) NOT NULL DEFAULT '',
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id INT NOT NULL,
+    before_data MEDIUMTEXT NULL,
+    after_data MEDIUMTEXT NULL,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at DATETIME(6) NOT NULL,
+    prev_hash CHAR(64) CHARACTER SET ascii NOT NULL,
+    hash CHAR(64) CHARACTER SET ascii NOT NULL,
+    UNIQUE KEY uq_audit_logs_hash (hash),
+    INDEX idx_audit_logs_entity (entity_type, entity_id),
+    INDEX idx_audit_logs_actor (actor_id),
+    INDEX idx_audit_logs_created_at (created_at)
+) ENGINE=InnoDB;
+
+-- Hash of the newest entry; locking this row serializes appends to the chain
+CREATE TABLE IF NOT EXISTS audit_log_head (
+    id TINYINT PRIMARY KEY,
+    last_hash CHAR(64) CHARACTER SET ascii NOT NULL
+) ENGINE=InnoDB;
+
+INSERT IGNORE INTO audit_log_head (id, last_hash) VALUES (1, REPEAT('0', 64));
+
+-- Reject changes to stored entries
+DROP TRIGGER IF EXISTS audit_logs_no_update;
+CREATE TRIGGER audit_logs_no_update BEFORE UPDATE ON audit_logs
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
+
+DROP TRIGGER IF EXISTS audit_logs_no_delete;
+CREATE TRIGGER audit_logs_no_delete BEFORE DELETE ON audit_logs
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
+
+-- Create exam_schedules table (exam deadlines, '' class means every class)
+CREATE TABLE IF NOT EXISTS exam_schedules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    title VARCHAR(150) NOT NULL,
+    class VARCHAR(20) NOT NULL DEFAULT '',
+    due_at DATETIME NOT NULL,
+    created_by INT NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_exam_schedules_due_at (due_at),
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB;
+
 -- Insert default admin user (password: admin123)
 INSERT INTO users (username, password, email, role) VALUES
 ('admin', 'admin123', 'admin@example.com', 'admin'),
@@ -72,3 +283,10 @@ INSERT INTO questions (type, question, options, answer, image_url, score) VALUES
 ('essay', 'Jelaskan mengapa belajar pemrograman penting di era digital?', NULL, NULL, NULL, 5),
 ('multiple_choice', 'Bahasa pemrograman yang berjalan di lingkungan browser adalah...', '["JavaScript", "Java", "Python", "Go", "C++"]', 'A', NULL, 2),
 ('multiple_choice', 'Mana yang bukan termasuk framework JavaScript?', '["Django", "React", "Angular", "Vue", "Svelte"]', 'A', 'https://example.com/frameworks.jpg', 3);
+
+-- Fill the search column of the sample questions
+UPDATE questions SET search_text = CONCAT_WS('\n', question, options) WHERE search_text IS NULL;
+
+-- Store the sample questions as their first version
+INSERT INTO question_versions (question_id, version, type, question, options, answer, image_url, score)
+SELECT id, current_version, type, question, options, answer, image_url, score FROM questions;
@@ -0,0 +1,15 @@
+-- Migration script to add teacher feedback to student answers
+
+-- Check if feedback column exists, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'student_answers'
+               AND COLUMN_NAME = 'feedback');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE student_answers ADD COLUMN feedback TEXT NULL AFTER score',
+                'SELECT "feedback column already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
@@ -0,0 +1,40 @@
+-- Migration script to add file upload answers: per-question file rules and answer_files
+
+-- Check if file_rule column exists on questions, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'questions'
+               AND COLUMN_NAME = 'file_rule');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE questions ADD COLUMN file_rule JSON NULL AFTER score',
+                'SELECT "questions.file_rule column already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- Check if file_rule column exists on question_versions, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'question_versions'
+               AND COLUMN_NAME = 'file_rule');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE question_versions ADD COLUMN file_rule JSON NULL AFTER tags',
+                'SELECT "question_versions.file_rule column already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- Files attached to answers (requires the uploads table from add_uploads.sql)
+CREATE TABLE IF NOT EXISTS answer_files (
+    answer_id INT NOT NULL,
+    upload_id INT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (answer_id, upload_id),
+    UNIQUE KEY uq_answer_files_upload (upload_id),
+    FOREIGN KEY (answer_id) REFERENCES student_answers(id) ON DELETE CASCADE,
+    FOREIGN KEY (upload_id) REFERENCES uploads(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,39 @@
+-- Migration script to add the append-only audit log
+
+-- before_data and after_data are TEXT rather than JSON: MySQL normalizes JSON
+-- values, which would change the bytes covered by the hash
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor_id INT NULL DEFAULT NULL,
+    actor_role VARCHAR(20) NOT NULL DEFAULT '',
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id INT NOT NULL,
+    before_data MEDIUMTEXT NULL,
+    after_data MEDIUMTEXT NULL,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at DATETIME(6) NOT NULL,
+    prev_hash CHAR(64) CHARACTER SET ascii NOT NULL,
+    hash CHAR(64) CHARACTER SET ascii NOT NULL,
+    UNIQUE KEY uq_audit_logs_hash (hash),
+    INDEX idx_audit_logs_entity (entity_type, entity_id),
+    INDEX idx_audit_logs_actor (actor_id),
+    INDEX idx_audit_logs_created_at (created_at)
+) ENGINE=InnoDB;
+
+-- Hash of the newest entry; locking this row serializes appends to the chain
+CREATE TABLE IF NOT EXISTS audit_log_head (
+    id TINYINT PRIMARY KEY,
+    last_hash CHAR(64) CHARACTER SET ascii NOT NULL
+) ENGINE=InnoDB;
+
+INSERT IGNORE INTO audit_log_head (id, last_hash) VALUES (1, REPEAT('0', 64));
+
+-- Reject changes to stored entries
+DROP TRIGGER IF EXISTS audit_logs_no_update;
+CREATE TRIGGER audit_logs_no_update BEFORE UPDATE ON audit_logs
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
+
+DROP TRIGGER IF EXISTS audit_logs_no_delete;
+CREATE TRIGGER audit_logs_no_delete BEFORE DELETE ON audit_logs
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
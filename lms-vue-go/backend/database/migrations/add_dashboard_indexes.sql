@@ -0,0 +1,29 @@
+-- Migration script to add indexes used by the teacher dashboard aggregates
+
+-- Check if the created_at index exists, if not add it (recent submissions)
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.STATISTICS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'student_answers'
+               AND INDEX_NAME = 'idx_student_answers_created_at');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE student_answers ADD INDEX idx_student_answers_created_at (created_at)',
+                'SELECT "idx_student_answers_created_at index already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- Check if the score index exists, if not add it (answers pending grading)
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.STATISTICS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'student_answers'
+               AND INDEX_NAME = 'idx_student_answers_score');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE student_answers ADD INDEX idx_student_answers_score (score)',
+                'SELECT "idx_student_answers_score index already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
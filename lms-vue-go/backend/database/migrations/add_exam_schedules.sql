@@ -0,0 +1,13 @@
+-- Migration script to add exam schedules with deadlines for the teacher dashboard
+
+CREATE TABLE IF NOT EXISTS exam_schedules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    title VARCHAR(150) NOT NULL,
+    class VARCHAR(20) NOT NULL DEFAULT '',
+    due_at DATETIME NOT NULL,
+    created_by INT NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_exam_schedules_due_at (due_at),
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB;
@@ -0,0 +1,10 @@
+-- Migration script to add login_attempts table for brute-force protection
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    kind ENUM('username', 'ip') NOT NULL,
+    attempt_key VARCHAR(100) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (kind, attempt_key)
+) ENGINE=InnoDB;
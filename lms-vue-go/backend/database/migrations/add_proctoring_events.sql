@@ -0,0 +1,17 @@
+-- Migration script to add the proctoring_events table for exam attempt monitoring
+
+-- One narrow row per browser event; the event type is an ENUM and the session id
+-- ASCII, so rows stay small and a batch is written with one INSERT
+CREATE TABLE IF NOT EXISTS proctoring_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    student_id INT NOT NULL,
+    session_id VARCHAR(64) CHARACTER SET ascii NOT NULL,
+    event_type ENUM('tab_hidden', 'tab_visible', 'focus_lost', 'focus_gained', 'paste', 'copy', 'fullscreen_exit', 'fullscreen_enter') NOT NULL,
+    question_id INT NULL DEFAULT NULL,
+    length INT NOT NULL DEFAULT 0,
+    occurred_at TIMESTAMP(3) NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_proctoring_events_session (student_id, session_id, occurred_at),
+    INDEX idx_proctoring_events_question (student_id, question_id),
+    FOREIGN KEY (student_id) REFERENCES students(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
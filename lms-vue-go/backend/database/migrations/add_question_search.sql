@@ -0,0 +1,40 @@
+-- Migration script to add question tags and the full-text search column
+
+-- Check if search_text column exists, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'questions'
+               AND COLUMN_NAME = 'search_text');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE questions ADD COLUMN search_text TEXT NULL AFTER score',
+                'SELECT "search_text column already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- Fill search_text for existing questions (the backend keeps it up to date afterwards)
+UPDATE questions SET search_text = CONCAT_WS('\n', question, options) WHERE search_text IS NULL;
+
+-- Check if the FULLTEXT index exists, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.STATISTICS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'questions'
+               AND INDEX_NAME = 'ft_questions_search');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE questions ADD FULLTEXT INDEX ft_questions_search (search_text)',
+                'SELECT "ft_questions_search index already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+CREATE TABLE IF NOT EXISTS question_tags (
+    question_id INT NOT NULL,
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (question_id, tag),
+    INDEX idx_question_tags_tag (tag),
+    FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
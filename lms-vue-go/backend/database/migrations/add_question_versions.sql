@@ -0,0 +1,59 @@
+-- Migration script to add immutable question versions
+
+-- Check if current_version column exists, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'questions'
+               AND COLUMN_NAME = 'current_version');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE questions ADD COLUMN current_version INT NOT NULL DEFAULT 1 AFTER score',
+                'SELECT "current_version column already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- Check if question_version column exists, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'student_answers'
+               AND COLUMN_NAME = 'question_version');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE student_answers ADD COLUMN question_version INT NULL AFTER score',
+                'SELECT "question_version column already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+CREATE TABLE IF NOT EXISTS question_versions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    question_id INT NOT NULL,
+    version INT NOT NULL,
+    type ENUM('multiple_choice', 'essay') NOT NULL,
+    question TEXT NOT NULL,
+    options JSON NULL,
+    answer TEXT NULL,
+    image_url VARCHAR(255) NULL,
+    score INT NOT NULL,
+    tags JSON NULL,
+    created_by INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_question_version (question_id, version),
+    FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB;
+
+-- Store the current content of existing questions as their first version
+INSERT IGNORE INTO question_versions (question_id, version, type, question, options, answer, image_url, score, tags)
+SELECT q.id, q.current_version, q.type, q.question, q.options, q.answer, q.image_url, q.score,
+       (SELECT JSON_ARRAYAGG(qt.tag) FROM question_tags qt WHERE qt.question_id = q.id)
+FROM questions q;
+
+-- Existing answers were given to the current version
+UPDATE student_answers sa
+JOIN questions q ON sa.question_id = q.id
+SET sa.question_version = q.current_version
+WHERE sa.question_version IS NULL;
@@ -0,0 +1,29 @@
+-- Migration script to add soft delete (trash) columns to questions and students
+
+-- Check if deleted_at column exists on questions, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'questions'
+               AND COLUMN_NAME = 'deleted_at');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE questions ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL, ADD INDEX idx_questions_deleted_at (deleted_at)',
+                'SELECT "questions.deleted_at column already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- Check if deleted_at column exists on students, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'students'
+               AND COLUMN_NAME = 'deleted_at');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE students ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL, ADD INDEX idx_students_deleted_at (deleted_at)',
+                'SELECT "students.deleted_at column already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
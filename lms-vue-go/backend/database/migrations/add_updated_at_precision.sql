@@ -0,0 +1,89 @@
+-- Migration script for the analytics cache version: updated_at with microseconds and an
+-- index on it, so MAX(updated_at) is an index lookup that changes on every edit
+
+-- Check if student_answers.updated_at has microseconds, if not change it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'student_answers'
+               AND COLUMN_NAME = 'updated_at'
+               AND DATETIME_PRECISION = 6);
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE student_answers MODIFY updated_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)',
+                'SELECT "student_answers.updated_at already has microseconds"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- Check if the updated_at index exists, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.STATISTICS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'student_answers'
+               AND INDEX_NAME = 'idx_student_answers_updated_at');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE student_answers ADD INDEX idx_student_answers_updated_at (updated_at)',
+                'SELECT "idx_student_answers_updated_at index already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- Check if questions.updated_at has microseconds, if not change it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'questions'
+               AND COLUMN_NAME = 'updated_at'
+               AND DATETIME_PRECISION = 6);
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE questions MODIFY updated_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)',
+                'SELECT "questions.updated_at already has microseconds"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- Check if the updated_at index exists, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.STATISTICS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'questions'
+               AND INDEX_NAME = 'idx_questions_updated_at');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE questions ADD INDEX idx_questions_updated_at (updated_at)',
+                'SELECT "idx_questions_updated_at index already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- Check if students.updated_at has microseconds, if not change it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'students'
+               AND COLUMN_NAME = 'updated_at'
+               AND DATETIME_PRECISION = 6);
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE students MODIFY updated_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)',
+                'SELECT "students.updated_at already has microseconds"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+-- Check if the updated_at index exists, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.STATISTICS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'students'
+               AND INDEX_NAME = 'idx_students_updated_at');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE students ADD INDEX idx_students_updated_at (updated_at)',
+                'SELECT "idx_students_updated_at index already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
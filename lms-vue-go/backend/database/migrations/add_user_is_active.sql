@@ -0,0 +1,15 @@
+-- Migration script to add is_active column to users table
+
+-- Check if is_active column exists, if not add it
+SET @exist := (SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+               WHERE TABLE_SCHEMA = 'lms_db'
+               AND TABLE_NAME = 'users'
+               AND COLUMN_NAME = 'is_active');
+
+SET @query = IF(@exist = 0,
+                'ALTER TABLE users ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE AFTER email_verified_at',
+                'SELECT "is_active column already exists"');
+
+PREPARE stmt FROM @query;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
@@ -0,0 +1,171 @@
+// Package gradebook combines students, questions and graded answers into a
+// grade table and renders it as CSV, XLSX or PDF report cards.
+package gradebook
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/spreadsheet"
+)
+
+// Column is one question of the exam
+type Column struct {
+	QuestionID uint                `json:"question_id"`
+	Label      string              `json:"label"` // "S1", "S2", ... in exam order
+	Question   string              `json:"question"`
+	Type       models.QuestionType `json:"type"`
+	MaxScore   int                 `json:"max_score"`
+}
+
+// Cell is the result of one student for one question
+type Cell struct {
+	Answered bool   `json:"answered"`
+	Score    *int   `json:"score"`              // Nil if not answered or not graded yet
+	MaxScore int    `json:"max_score"`          // Score of the question version the student answered
+	Feedback string `json:"feedback,omitempty"` // Teacher feedback given when grading
+}
+
+// Row holds the grades of one student
+type Row struct {
+	Student    models.Student `json:"student"`
+	Cells      []Cell         `json:"cells"` // One per column
+	Total      int            `json:"total"`
+	MaxScore   int            `json:"max_score"`
+	Percentage float64        `json:"percentage"` // Total / MaxScore * 100, rounded to one decimal
+	Ungraded   int            `json:"ungraded"`   // Answered but not graded yet
+	Missing    int            `json:"missing"`    // Not answered
+}
+
+// Gradebook is the grade table of every student for every question
+type Gradebook struct {
+	Columns      []Column           `json:"columns"`
+	Rows         []Row              `json:"rows"`
+	ClassAverage map[string]float64 `json:"class_average"` // Average percentage per class
+	GeneratedAt  time.Time          `json:"generated_at"`
+}
+
+// Build creates the gradebook. Questions are the exam in order; answers to other
+// questions are ignored. Unanswered questions count as zero out of their current score.
+func Build(students []models.Student, questions []models.Question, answers []models.StudentAnswerWithDetails) *Gradebook {
+	g := &Gradebook{ClassAverage: map[string]float64{}, GeneratedAt: time.Now()}
+
+	index := map[uint]int{}
+	for i, q := range questions {
+		index[q.ID] = i
+		g.Columns = append(g.Columns, Column{
+			QuestionID: q.ID,
+			Label:      fmt.Sprintf("S%d", i+1),
+			Question:   q.Question,
+			Type:       q.Type,
+			MaxScore:   q.Score,
+		})
+	}
+
+	byStudent := map[uint][]models.StudentAnswerWithDetails{}
+	for _, a := range answers {
+		byStudent[a.StudentID] = append(byStudent[a.StudentID], a)
+	}
+
+	sorted := append([]models.Student(nil), students...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Class != sorted[j].Class {
+			return sorted[i].Class < sorted[j].Class
+		}
+		return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+	})
+
+	classTotals := map[string][2]float64{} // Sum of percentages and number of students
+	for _, s := range sorted {
+		row := Row{Student: s, Cells: make([]Cell, len(g.Columns))}
+		for i, col := range g.Columns {
+			row.Cells[i].MaxScore = col.MaxScore
+		}
+		for _, a := range byStudent[s.ID] {
+			i, ok := index[a.QuestionID]
+			if !ok {
+				continue
+			}
+			row.Cells[i] = Cell{Answered: true, Score: a.Score, MaxScore: a.QuestionScore, Feedback: a.Feedback}
+		}
+
+		for _, cell := range row.Cells {
+			row.MaxScore += cell.MaxScore
+			switch {
+			case !cell.Answered:
+				row.Missing++
+			case cell.Score == nil:
+				row.Ungraded++
+			default:
+				row.Total += *cell.Score
+			}
+		}
+		if row.MaxScore > 0 {
+			row.Percentage = math.Round(float64(row.Total)/float64(row.MaxScore)*1000) / 10
+		}
+
+		t := classTotals[s.Class]
+		classTotals[s.Class] = [2]float64{t[0] + row.Percentage, t[1] + 1}
+		g.Rows = append(g.Rows, row)
+	}
+
+	for class, t := range classTotals {
+		g.ClassAverage[class] = math.Round(t[0]/t[1]*10) / 10
+	}
+	return g
+}
+
+// Filter returns the rows for which keep returns true
+func (g *Gradebook) Filter(keep func(Row) bool) []Row {
+	var rows []Row
+	for _, row := range g.Rows {
+		if keep(row) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Table returns the header and cells of a grade table for the given rows. Question
+// columns hold the score, "" while the answer is not graded and "-" if there is no answer.
+func (g *Gradebook) Table(rows []Row) ([]string, [][]interface{}) {
+	header := []string{"Nama", "Kelas", "Email"}
+	for _, col := range g.Columns {
+		header = append(header, fmt.Sprintf("%s (%d)", col.Label, col.MaxScore))
+	}
+	header = append(header, "Total", "Skor Maksimal", "Persentase", "Belum Dinilai", "Tidak Dijawab")
+
+	var cells [][]interface{}
+	for _, row := range rows {
+		line := []interface{}{row.Student.Name, row.Student.Class, row.Student.Email}
+		for _, cell := range row.Cells {
+			switch {
+			case !cell.Answered:
+				line = append(line, "-")
+			case cell.Score == nil:
+				line = append(line, "")
+			default:
+				line = append(line, *cell.Score)
+			}
+		}
+		line = append(line, row.Total, row.MaxScore, row.Percentage, row.Ungraded, row.Missing)
+		cells = append(cells, line)
+	}
+	return header, cells
+}
+
+// CSV renders the rows as a CSV file
+func (g *Gradebook) CSV(rows []Row) ([]byte, error) {
+	header, cells := g.Table(rows)
+	return spreadsheet.WriteCSV(header, cells)
+}
+
+// XLSX renders the rows as an XLSX workbook
+func (g *Gradebook) XLSX(sheetName string, rows []Row) ([]byte, error) {
+	header, cells := g.Table(rows)
+	return spreadsheet.WriteXLSX(sheetName, header, cells)
+}
@@ -0,0 +1,72 @@
+package gradebook
+
+import (
+	"bytes"
+	"testing"
+
+	"lms-vue-go/backend/models"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestBuild(t *testing.T) {
+	students := []models.Student{
+		{ID: 1, Name: "Budi", Class: "X-2"},
+		{ID: 2, Name: "ani", Class: "X-1"},
+		{ID: 3, Name: "Citra", Class: "X-1"},
+	}
+	questions := []models.Question{
+		{ID: 10, Type: models.MultipleChoice, Question: "1 + 1?", Score: 10},
+		{ID: 11, Type: models.Essay, Question: "Jelaskan.", Score: 20},
+	}
+	answers := []models.StudentAnswerWithDetails{
+		{StudentID: 2, QuestionID: 10, Score: intPtr(10), QuestionScore: 10},
+		{StudentID: 2, QuestionID: 11, Score: intPtr(15), QuestionScore: 20, Feedback: "Contohnya kurang."},
+		{StudentID: 3, QuestionID: 10, Score: intPtr(0), QuestionScore: 10},
+		{StudentID: 3, QuestionID: 11, QuestionScore: 20},
+		{StudentID: 1, QuestionID: 99, Score: intPtr(5), QuestionScore: 5}, // Not part of the exam
+	}
+
+	g := Build(students, questions, answers)
+
+	var names []string
+	for _, row := range g.Rows {
+		names = append(names, row.Student.Name)
+	}
+	if want := []string{"ani", "Citra", "Budi"}; len(names) != 3 || names[0] != want[0] || names[1] != want[1] || names[2] != want[2] {
+		t.Fatalf("rows are ordered %v, want %v", names, want)
+	}
+
+	ani, citra, budi := g.Rows[0], g.Rows[1], g.Rows[2]
+	if ani.Total != 25 || ani.MaxScore != 30 || ani.Percentage != 83.3 {
+		t.Errorf("ani = %d/%d (%v%%), want 25/30 (83.3%%)", ani.Total, ani.MaxScore, ani.Percentage)
+	}
+	if ani.Cells[1].Feedback != "Contohnya kurang." {
+		t.Errorf("ani feedback = %q, want the teacher feedback of the essay", ani.Cells[1].Feedback)
+	}
+	if citra.Ungraded != 1 || citra.Missing != 0 || citra.Total != 0 {
+		t.Errorf("citra ungraded %d missing %d total %d, want 1, 0, 0", citra.Ungraded, citra.Missing, citra.Total)
+	}
+	if budi.Missing != 2 || budi.Total != 0 || budi.MaxScore != 30 {
+		t.Errorf("budi missing %d total %d max %d, want 2, 0, 30", budi.Missing, budi.Total, budi.MaxScore)
+	}
+	if avg := g.ClassAverage["X-1"]; avg != 41.7 {
+		t.Errorf("class average of X-1 = %v, want 41.7", avg)
+	}
+
+	header, cells := g.Table(g.Rows)
+	if header[3] != "S1 (10)" || len(cells[1]) != len(header) {
+		t.Fatalf("unexpected table header %v", header)
+	}
+	if cells[1][4] != "" || cells[2][3] != "-" || cells[0][3] != 10 {
+		t.Errorf("question cells = %v, %v, %v", cells[0][3], cells[1][4], cells[2][3])
+	}
+
+	doc := g.ReportCards("Rapor Nilai", g.Rows)
+	if !bytes.Contains(doc, []byte("/Count 3")) {
+		t.Error("report cards should have one page per student")
+	}
+	if !bytes.Contains(doc, []byte("(Catatan Guru)")) || !bytes.Contains(doc, []byte("(Contohnya kurang.)")) {
+		t.Error("report cards should show the teacher feedback")
+	}
+}
@@ -0,0 +1,189 @@
+package gradebook
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/pdf"
+)
+
+// Layout of the report card in points
+const (
+	marginX      = 50.0
+	marginTop    = 60.0
+	marginBottom = 70.0
+	rowHeight    = 18.0
+	bodySize     = 10.0
+)
+
+// Table columns: left edge of No, Soal, Tipe and the right edges of Nilai and Maks
+const (
+	colNo       = marginX + 4
+	colQuestion = marginX + 34
+	colType     = 370.0
+	colScore    = 480.0
+	colMax      = pdf.PageWidth - marginX - 4
+)
+
+// ReportCards renders one report card per row. A student with many questions
+// continues on following pages; every student starts on a new page.
+func (g *Gradebook) ReportCards(title string, rows []Row) []byte {
+	doc := pdf.New(title)
+	for _, row := range rows {
+		g.reportCard(doc, title, row)
+	}
+	return doc.Bytes()
+}
+
+// reportCard draws the pages of one student
+func (g *Gradebook) reportCard(doc *pdf.Document, title string, row Row) {
+	page := doc.AddPage()
+	y := marginTop
+
+	page.Text(marginX, y, pdf.HelveticaBold, 18, title)
+	y += 16
+	page.Text(marginX, y, pdf.Helvetica, 9, "Dibuat pada "+g.GeneratedAt.Format("02-01-2006 15:04"))
+	y += 12
+	page.Line(marginX, y, pdf.PageWidth-marginX, y, 1)
+	y += 22
+
+	for _, field := range [][2]string{
+		{"Nama", row.Student.Name},
+		{"Kelas", row.Student.Class},
+		{"Email", row.Student.Email},
+	} {
+		page.Text(marginX, y, pdf.HelveticaBold, bodySize, field[0])
+		page.Text(marginX+60, y, pdf.Helvetica, bodySize, ": "+pdf.Truncate(pdf.Helvetica, bodySize, field[1], 400))
+		y += 15
+	}
+	y += 12
+
+	y = tableHeader(page, y)
+	for i, col := range g.Columns {
+		if y > pdf.PageHeight-marginBottom {
+			page = doc.AddPage()
+			page.Text(marginX, marginTop, pdf.Helvetica, 9, fmt.Sprintf("%s - %s (lanjutan)", row.Student.Name, row.Student.Class))
+			y = tableHeader(page, marginTop+20)
+		}
+
+		cell := row.Cells[i]
+		if i%2 == 1 {
+			page.FillRect(marginX, y-12.5, pdf.PageWidth-2*marginX, rowHeight, 0.95)
+		}
+		page.Text(colNo, y, pdf.Helvetica, bodySize, strconv.Itoa(i+1))
+		page.Text(colQuestion, y, pdf.Helvetica, bodySize, pdf.Truncate(pdf.Helvetica, bodySize, strings.Join(strings.Fields(col.Question), " "), colType-colQuestion-10))
+		page.Text(colType, y, pdf.Helvetica, bodySize, typeLabel(col.Type))
+		switch {
+		case !cell.Answered:
+			page.TextRight(colScore, y, pdf.Helvetica, 9, "Tidak dijawab")
+		case cell.Score == nil:
+			page.TextRight(colScore, y, pdf.Helvetica, 9, "Belum dinilai")
+		default:
+			page.TextRight(colScore, y, pdf.Helvetica, bodySize, strconv.Itoa(*cell.Score))
+		}
+		page.TextRight(colMax, y, pdf.Helvetica, bodySize, strconv.Itoa(cell.MaxScore))
+		y += rowHeight
+	}
+
+	// The summary needs about six lines
+	if y > pdf.PageHeight-marginBottom-90 {
+		page = doc.AddPage()
+		y = marginTop
+	}
+	y -= 6
+	page.Line(marginX, y, pdf.PageWidth-marginX, y, 1)
+	y += 16
+	page.Text(colQuestion, y, pdf.HelveticaBold, bodySize, "Total")
+	page.TextRight(colScore, y, pdf.HelveticaBold, bodySize, strconv.Itoa(row.Total))
+	page.TextRight(colMax, y, pdf.HelveticaBold, bodySize, strconv.Itoa(row.MaxScore))
+	y += 28
+
+	summary := [][2]string{
+		{"Persentase", formatPercent(row.Percentage)},
+	}
+	if average, ok := g.ClassAverage[row.Student.Class]; ok {
+		summary = append(summary, [2]string{"Rata-rata kelas " + row.Student.Class, formatPercent(average)})
+	}
+	summary = append(summary,
+		[2]string{"Belum dinilai", fmt.Sprintf("%d soal", row.Ungraded)},
+		[2]string{"Tidak dijawab", fmt.Sprintf("%d soal", row.Missing)},
+	)
+	for _, line := range summary {
+		page.Text(marginX, y, pdf.HelveticaBold, bodySize, line[0])
+		page.Text(marginX+150, y, pdf.Helvetica, bodySize, ": "+line[1])
+		y += 15
+	}
+	if row.Ungraded > 0 {
+		y += 8
+		page.Text(marginX, y, pdf.Helvetica, 9, "Nilai belum final karena masih ada jawaban yang belum dinilai.")
+		y += 15
+	}
+
+	g.feedback(doc, page, y+12, row)
+}
+
+// feedback draws the teacher feedback of a student below the summary, one block per
+// question with feedback, numbered like the score table
+func (g *Gradebook) feedback(doc *pdf.Document, page *pdf.Page, y float64, row Row) {
+	first := true
+	for i, cell := range row.Cells {
+		if cell.Feedback == "" {
+			continue
+		}
+		lines := pdf.Wrap(pdf.Helvetica, bodySize, cell.Feedback, colMax-colQuestion)
+
+		// The heading stays with the first block; a block is only split when it is longer than a page
+		needed := float64(len(lines))*14 + 6
+		if first {
+			needed += 22
+		}
+		if y+min(needed, 80) > pdf.PageHeight-marginBottom {
+			page = doc.AddPage()
+			page.Text(marginX, marginTop, pdf.Helvetica, 9, fmt.Sprintf("%s - %s (lanjutan)", row.Student.Name, row.Student.Class))
+			y = marginTop + 24
+		}
+		if first {
+			page.Text(marginX, y, pdf.HelveticaBold, 12, "Catatan Guru")
+			y += 22
+			first = false
+		}
+
+		page.Text(colNo, y, pdf.HelveticaBold, bodySize, strconv.Itoa(i+1))
+		for _, line := range lines {
+			if y > pdf.PageHeight-marginBottom {
+				page = doc.AddPage()
+				page.Text(marginX, marginTop, pdf.Helvetica, 9, fmt.Sprintf("%s - %s (lanjutan)", row.Student.Name, row.Student.Class))
+				y = marginTop + 24
+			}
+			page.Text(colQuestion, y, pdf.Helvetica, bodySize, line)
+			y += 14
+		}
+		y += 6
+	}
+}
+
+// tableHeader draws the header row of the score table and returns the baseline of the first row
+func tableHeader(page *pdf.Page, y float64) float64 {
+	page.FillRect(marginX, y-12.5, pdf.PageWidth-2*marginX, rowHeight, 0.85)
+	page.Text(colNo, y, pdf.HelveticaBold, bodySize, "No")
+	page.Text(colQuestion, y, pdf.HelveticaBold, bodySize, "Soal")
+	page.Text(colType, y, pdf.HelveticaBold, bodySize, "Tipe")
+	page.TextRight(colScore, y, pdf.HelveticaBold, bodySize, "Nilai")
+	page.TextRight(colMax, y, pdf.HelveticaBold, bodySize, "Maks")
+	return y + rowHeight
+}
+
+// typeLabel returns the Indonesian name of a question type
+func typeLabel(t models.QuestionType) string {
+	if t == models.Essay {
+		return "Esai"
+	}
+	return "Pilihan ganda"
+}
+
+// formatPercent formats a percentage with one decimal and a decimal comma
+func formatPercent(p float64) string {
+	return strings.Replace(strconv.FormatFloat(p, 'f', 1, 64), ".", ",", 1) + "%"
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/logging"
+	"lms-vue-go/backend/mailer"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Masa berlaku token sekali pakai
+const (
+	emailVerificationTTL = 48 * time.Hour
+	passwordResetTTL     = 1 * time.Hour
+)
+
+// mailConfig dan appMailer digunakan untuk mengirim email akun
+var (
+	mailConfig               = config.DefaultMailConfig()
+	appMailer  mailer.Mailer = mailer.New(mailConfig)
+)
+
+// SetMailer mengganti mailer yang digunakan handler (misalnya untuk pengujian)
+func SetMailer(m mailer.Mailer) {
+	appMailer = m
+}
+
+// ForgotPasswordRequest adalah struktur untuk request lupa password
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest adalah struktur untuk request reset password
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// VerifyEmailRequest adalah struktur untuk request verifikasi email
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPassword mengirim link reset password ke email pengguna
+func ForgotPassword(c *gin.Context) {
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
+
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	// Response selalu sama agar tidak membocorkan email yang terdaftar
+	response := gin.H{"message": "Jika email terdaftar, link reset password telah dikirim"}
+
+	user, err := userRepo.FindByEmail(req.Email)
+	if err != nil {
+		requestLog(c).Error("Error in ForgotPassword", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memproses permintaan"})
+		return
+	}
+
+	if user == nil || !user.IsActive {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Email dikirim di latar belakang agar waktu respons tidak membedakan email terdaftar;
+	// kegagalan hanya dicatat di log
+	ctx := context.WithoutCancel(c.Request.Context())
+	go func() {
+		if err := sendPasswordResetEmail(ctx, user); err != nil {
+			logging.FromContext(ctx).Error("Error sending password reset email", "error", err)
+		}
+	}()
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword mengganti password menggunakan token reset
+func ResetPassword(c *gin.Context) {
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
+	tokenRepo := repository.NewUserTokenRepository().WithContext(c.Request.Context())
+
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	token, ok := redeemToken(c, tokenRepo, req.Token, models.TokenPasswordReset)
+	if !ok {
+		return
+	}
+
+	// Simpan password baru
+	if err := userRepo.UpdatePassword(token.UserID, req.Password); err != nil {
+		requestLog(c).Error("Error updating password", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengganti password"})
+		return
+	}
+
+	// Token reset lain milik pengguna tidak boleh dipakai lagi
+	if err := tokenRepo.InvalidateForUser(token.UserID, models.TokenPasswordReset); err != nil {
+		requestLog(c).Error("Error invalidating reset tokens", "error", err)
+	}
+
+	// Link reset dikirim ke email, jadi reset yang berhasil juga membuktikan email valid
+	if err := userRepo.MarkEmailVerified(token.UserID); err != nil {
+		requestLog(c).Error("Error marking email verified", "error", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password berhasil diganti"})
+}
+
+// VerifyEmail mengkonfirmasi alamat email menggunakan token verifikasi
+func VerifyEmail(c *gin.Context) {
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
+	tokenRepo := repository.NewUserTokenRepository().WithContext(c.Request.Context())
+
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	token, ok := redeemToken(c, tokenRepo, req.Token, models.TokenEmailVerification)
+	if !ok {
+		return
+	}
+
+	if err := userRepo.MarkEmailVerified(token.UserID); err != nil {
+		requestLog(c).Error("Error marking email verified", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memverifikasi email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email berhasil diverifikasi"})
+}
+
+// ResendVerificationEmail mengirim ulang email verifikasi untuk pengguna yang sedang login
+func ResendVerificationEmail(c *gin.Context) {
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tidak terautentikasi"})
+		return
+	}
+
+	user, err := userRepo.FindByID(userID.(uint))
+	if err != nil {
+		requestLog(c).Error("Error in ResendVerificationEmail", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pengguna"})
+		return
+	}
+
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pengguna tidak ditemukan"})
+		return
+	}
+
+	if user.EmailVerifiedAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email sudah diverifikasi"})
+		return
+	}
+
+	if err := sendVerificationEmail(c.Request.Context(), user); err != nil {
+		requestLog(c).Error("Error sending verification email", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email verifikasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verifikasi telah dikirim"})
+}
+
+// redeemToken memvalidasi dan memakai token sekali pakai. Jika gagal, response error sudah dikirim.
+func redeemToken(c *gin.Context, tokenRepo *repository.UserTokenRepository, rawToken string, purpose models.TokenPurpose) (*models.UserToken, bool) {
+	token, err := tokenRepo.FindValid(hashToken(rawToken), purpose)
+	if err != nil {
+		requestLog(c).Error("Error finding token", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa token"})
+		return nil, false
+	}
+
+	if token == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token tidak valid atau sudah kedaluwarsa"})
+		return nil, false
+	}
+
+	used, err := tokenRepo.MarkUsed(token.ID)
+	if err != nil {
+		requestLog(c).Error("Error consuming token", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa token"})
+		return nil, false
+	}
+
+	if !used {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token tidak valid atau sudah kedaluwarsa"})
+		return nil, false
+	}
+
+	return token, true
+}
+
+// sendVerificationEmail membuat token verifikasi dan mengirimkannya ke email pengguna
+func sendVerificationEmail(ctx context.Context, user *models.User) error {
+	rawToken, err := issueToken(ctx, user.ID, models.TokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := mailConfig.AppURL + "/verify-email?token=" + url.QueryEscape(rawToken)
+	return appMailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email akun LMS",
+		Body: fmt.Sprintf("Halo %s,\n\nSilakan konfirmasi alamat email Anda melalui link berikut:\n%s\n\nLink berlaku selama %d jam.",
+			user.Username, link, int(emailVerificationTTL.Hours())),
+	})
+}
+
+// sendPasswordResetEmail membuat token reset password dan mengirimkannya ke email pengguna
+func sendPasswordResetEmail(ctx context.Context, user *models.User) error {
+	rawToken, err := issueToken(ctx, user.ID, models.TokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := mailConfig.AppURL + "/reset-password?token=" + url.QueryEscape(rawToken)
+	return appMailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password akun LMS",
+		Body: fmt.Sprintf("Halo %s,\n\nKami menerima permintaan reset password untuk akun Anda. Gunakan link berikut:\n%s\n\nLink berlaku selama %d menit dan hanya dapat digunakan sekali. Abaikan email ini jika Anda tidak memintanya.",
+			user.Username, link, int(passwordResetTTL.Minutes())),
+	})
+}
+
+// issueToken membuat token acak, menyimpan tanda tangannya, dan mengembalikan nilai aslinya
+func issueToken(ctx context.Context, userID uint, purpose models.TokenPurpose, ttl time.Duration) (string, error) {
+	tokenRepo := repository.NewUserTokenRepository().WithContext(ctx)
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	rawToken := base64.RawURLEncoding.EncodeToString(buf)
+
+	token := models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(rawToken),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := tokenRepo.Create(&token); err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// hashToken menandatangani token dengan HMAC-SHA256 sehingga isi tabel tidak dapat dipakai langsung
+func hashToken(rawToken string) string {
+	mac := hmac.New(sha256.New, jwtSecret)
+	mac.Write([]byte(rawToken))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateUserRoleRequest adalah struktur untuk request perubahan role
+type UpdateUserRoleRequest struct {
+	Role models.Role `json:"role" binding:"required"`
+}
+
+// UpdateUserStatusRequest adalah struktur untuk request aktif/nonaktif akun
+type UpdateUserStatusRequest struct {
+	Active *bool `json:"active" binding:"required"`
+}
+
+// AdminResetPasswordRequest adalah struktur untuk reset password oleh admin.
+// Jika Password kosong, password lama diganti dengan nilai acak dan link reset dikirim ke email pengguna.
+type AdminResetPasswordRequest struct {
+	Password string `json:"password" binding:"omitempty,min=6"`
+}
+
+// AdminListUsers mengembalikan daftar pengguna per halaman. Pencarian q pada username dan
+// email; filter role dan active (true/false).
+func AdminListUsers(c *gin.Context) {
+	query, ok := parseListQuery(c, repository.UserListSpec)
+	if !ok {
+		return
+	}
+
+	users, total, err := repository.NewUserRepository().WithContext(c.Request.Context()).List(query)
+	if err != nil {
+		requestLog(c).Error("Error in AdminListUsers", "error", err)
+		respondListError(c, err, "Gagal mengambil data pengguna")
+		return
+	}
+
+	responses := make([]models.UserResponse, len(users))
+	for i := range users {
+		responses[i] = users[i].ToResponse()
+	}
+
+	respondList(c, responses, total, query)
+}
+
+// AdminGetUser mengembalikan detail satu pengguna
+func AdminGetUser(c *gin.Context) {
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user.ToResponse()})
+}
+
+// AdminUpdateUserRole mengganti role pengguna
+func AdminUpdateUserRole(c *gin.Context) {
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
+
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	if !models.IsValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role tidak valid"})
+		return
+	}
+
+	if user.Role == models.RoleAdmin && req.Role != models.RoleAdmin && !ensureOtherAdmin(c, user) {
+		return
+	}
+
+	if err := userRepo.UpdateRole(user.ID, req.Role); err != nil {
+		requestLog(c).Error("Error updating user role", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah role pengguna"})
+		return
+	}
+
+	RecordAudit(c, models.AuditUserRoleUpdate, models.AuditEntityUser, user.ID, gin.H{"role": user.Role}, gin.H{"role": req.Role})
+	user.Role = req.Role
+	c.JSON(http.StatusOK, gin.H{"data": user.ToResponse(), "message": "Role pengguna berhasil diubah"})
+}
+
+// AdminUpdateUserStatus mengaktifkan atau menonaktifkan akun pengguna
+func AdminUpdateUserStatus(c *gin.Context) {
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
+
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	if !*req.Active {
+		if isCurrentUser(c, user.ID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tidak dapat menonaktifkan akun sendiri"})
+			return
+		}
+		if user.Role == models.RoleAdmin && !ensureOtherAdmin(c, user) {
+			return
+		}
+	}
+
+	if err := userRepo.SetActive(user.ID, *req.Active); err != nil {
+		requestLog(c).Error("Error updating user status", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah status pengguna"})
+		return
+	}
+
+	RecordAudit(c, models.AuditUserStatus, models.AuditEntityUser, user.ID, gin.H{"is_active": user.IsActive}, gin.H{"is_active": *req.Active})
+	user.IsActive = *req.Active
+	c.JSON(http.StatusOK, gin.H{"data": user.ToResponse(), "message": "Status pengguna berhasil diubah"})
+}
+
+// AdminResetUserPassword memaksa reset password pengguna
+func AdminResetUserPassword(c *gin.Context) {
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
+
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	// Body boleh kosong
+	var req AdminResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	// Admin menentukan password sementara secara langsung
+	if req.Password != "" {
+		if err := userRepo.UpdatePassword(user.ID, req.Password); err != nil {
+			requestLog(c).Error("Error resetting password", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mereset password"})
+			return
+		}
+		RecordAudit(c, models.AuditUserPassword, models.AuditEntityUser, user.ID, nil, gin.H{"method": "temporary_password"})
+		c.JSON(http.StatusOK, gin.H{"message": "Password pengguna berhasil direset"})
+		return
+	}
+
+	// Tanpa password: kunci password lama dan kirim link reset ke email pengguna
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mereset password"})
+		return
+	}
+	if err := userRepo.UpdatePassword(user.ID, base64.RawURLEncoding.EncodeToString(buf)); err != nil {
+		requestLog(c).Error("Error resetting password", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mereset password"})
+		return
+	}
+	RecordAudit(c, models.AuditUserPassword, models.AuditEntityUser, user.ID, nil, gin.H{"method": "email_link"})
+
+	if err := sendPasswordResetEmail(c.Request.Context(), user); err != nil {
+		requestLog(c).Error("Error sending password reset email", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Password direset tetapi email gagal dikirim"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password pengguna direset, link reset telah dikirim ke email"})
+}
+
+// AdminDeleteUser menghapus pengguna beserta data siswa yang terhubung.
+// Jika siswa sudah memiliki jawaban, penghapusan ditolak kecuali parameter force=true,
+// karena jawaban dan nilai ikut terhapus. Nonaktifkan akun untuk menyimpan riwayat nilai.
+func AdminDeleteUser(c *gin.Context) {
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
+
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	if isCurrentUser(c, user.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tidak dapat menghapus akun sendiri"})
+		return
+	}
+
+	if user.Role == models.RoleAdmin && !ensureOtherAdmin(c, user) {
+		return
+	}
+
+	answerCount, err := studentRepo.CountAnswersByUserID(user.ID)
+	if err != nil {
+		requestLog(c).Error("Error counting student answers", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa data siswa"})
+		return
+	}
+
+	if answerCount > 0 && c.Query("force") != "true" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":        "Pengguna memiliki jawaban siswa. Nonaktifkan akun atau gunakan force=true untuk menghapus beserta jawabannya",
+			"answer_count": answerCount,
+		})
+		return
+	}
+
+	if err := userRepo.DeleteWithStudent(user.ID); err != nil {
+		requestLog(c).Error("Error deleting user", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus pengguna"})
+		return
+	}
+	RecordAudit(c, models.AuditUserDelete, models.AuditEntityUser, user.ID, gin.H{"user": user.ToResponse(), "answer_count": answerCount}, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pengguna berhasil dihapus"})
+}
+
+// AdminUnlockUser menghapus penguncian login untuk username pengguna
+func AdminUnlockUser(c *gin.Context) {
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	if err := resetLoginFailures(c.Request.Context(), user.Username); err != nil {
+		requestLog(c).Error("Error unlocking user", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuka kunci pengguna"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kunci login pengguna berhasil dibuka"})
+}
+
+// AdminUnlockIP menghapus penguncian login untuk alamat IP
+func AdminUnlockIP(c *gin.Context) {
+	attemptRepo := repository.NewLoginAttemptRepository().WithContext(c.Request.Context())
+
+	ip := net.ParseIP(c.Param("ip"))
+	if ip == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alamat IP tidak valid"})
+		return
+	}
+
+	if err := attemptRepo.Reset(models.LoginAttemptIP, ip.String()); err != nil {
+		requestLog(c).Error("Error unlocking IP", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuka kunci IP"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kunci login IP berhasil dibuka"})
+}
+
+// findUserParam mencari pengguna dari parameter :id. Jika gagal, response error sudah dikirim.
+func findUserParam(c *gin.Context) (*models.User, bool) {
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return nil, false
+	}
+
+	user, err := userRepo.FindByID(uint(id))
+	if err != nil {
+		requestLog(c).Error("Error finding user", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pengguna"})
+		return nil, false
+	}
+
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pengguna tidak ditemukan"})
+		return nil, false
+	}
+
+	return user, true
+}
+
+// ensureOtherAdmin memastikan masih ada admin aktif lain sebelum admin diturunkan, dinonaktifkan, atau dihapus
+func ensureOtherAdmin(c *gin.Context, user *models.User) bool {
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
+
+	count, err := userRepo.CountActiveByRole(models.RoleAdmin)
+	if err != nil {
+		requestLog(c).Error("Error counting admins", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa data admin"})
+		return false
+	}
+
+	if user.IsActive {
+		count--
+	}
+	if count < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Harus ada minimal satu admin aktif"})
+		return false
+	}
+
+	return true
+}
+
+// isCurrentUser memeriksa apakah ID sama dengan pengguna yang sedang login
+func isCurrentUser(c *gin.Context, id uint) bool {
+	userID, exists := c.Get("userID")
+	return exists && userID.(uint) == id
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/analytics"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/logging"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+)
+
+// Statistik disimpan per kelas selama jawaban, soal, dan siswa tidak berubah
+var (
+	analyticsConfig = config.DefaultAnalyticsConfig()
+	itemCache       = analytics.NewCache[*analytics.ItemAnalysis](analyticsConfig.CacheTTL)
+	examStatsCache  = analytics.NewCache[*analytics.ExamStats](analyticsConfig.CacheTTL)
+)
+
+// maxHistogramBins membatasi parameter bins pada statistik ujian
+const maxHistogramBins = 100
+
+// GetItemAnalysis mengembalikan analisis butir soal: tingkat kesukaran (p-value), daya
+// beda, korelasi point-biserial, analisis pengecoh, dan tanda soal yang perlu ditinjau.
+// Parameter class membatasi jawaban satu kelas; flagged=true hanya menampilkan soal bertanda.
+func GetItemAnalysis(c *gin.Context) {
+	class := strings.TrimSpace(c.Query("class"))
+	analysis, cached, err := itemCache.Get(class, analyticsDataVersion(c.Request.Context()), func() (*analytics.ItemAnalysis, error) {
+		questions, answers, err := loadExamAnswers(c.Request.Context(), class)
+		if err != nil {
+			return nil, err
+		}
+		return analytics.AnalyzeItems(questions, answers), nil
+	})
+	if err != nil {
+		requestLog(c).Error("Error computing item analysis", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung analisis soal"})
+		return
+	}
+
+	if c.Query("flagged") == "true" {
+		// Salinan agar hasil di cache tidak ikut tersaring
+		filtered := *analysis
+		filtered.Items = []analytics.ItemStats{}
+		for _, item := range analysis.Items {
+			if len(item.Flags) > 0 {
+				filtered.Items = append(filtered.Items, item)
+			}
+		}
+		analysis = &filtered
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": analysis, "cached": cached})
+}
+
+// GetExamStatistics mengembalikan sebaran nilai ujian (histogram, rata-rata, median,
+// simpangan baku, persentil) dan reliabilitasnya (Cronbach's alpha dan KR-20).
+// Parameter class membatasi satu kelas, bins mengatur jumlah batang histogram.
+func GetExamStatistics(c *gin.Context) {
+	bins := analytics.DefaultBins
+	if raw := c.Query("bins"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxHistogramBins {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter bins harus antara 1 dan " + strconv.Itoa(maxHistogramBins)})
+			return
+		}
+		bins = n
+	}
+
+	class := strings.TrimSpace(c.Query("class"))
+	key := class + "|" + strconv.Itoa(bins)
+	stats, cached, err := examStatsCache.Get(key, analyticsDataVersion(c.Request.Context()), func() (*analytics.ExamStats, error) {
+		questions, answers, err := loadExamAnswers(c.Request.Context(), class)
+		if err != nil {
+			return nil, err
+		}
+		return analytics.ExamStatistics(questions, answers, bins), nil
+	})
+	if err != nil {
+		requestLog(c).Error("Error computing exam statistics", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung statistik ujian"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats, "cached": cached})
+}
+
+// analyticsDataVersion mengembalikan versi data untuk cache statistik, atau string
+// kosong jika gagal sehingga statistik dihitung ulang
+func analyticsDataVersion(ctx context.Context) string {
+	version, err := repository.NewStudentAnswerRepository().WithContext(ctx).DataVersion()
+	if err != nil {
+		logging.FromContext(ctx).Error("Error reading analytics data version", "error", err)
+		return ""
+	}
+	return version
+}
+
+// loadExamAnswers mengambil semua soal ujian dan jawaban siswa, dibatasi satu kelas jika class diisi
+func loadExamAnswers(ctx context.Context, class string) ([]models.Question, []models.StudentAnswerWithDetails, error) {
+	questions, err := repository.NewQuestionRepository().WithContext(ctx).FindAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	answers, err := repository.NewStudentAnswerRepository().WithContext(ctx).FindAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if class != "" {
+		filtered := answers[:0]
+		for _, a := range answers {
+			if a.StudentClass == class {
+				filtered = append(filtered, a)
+			}
+		}
+		answers = filtered
+	}
+	return questions, answers, nil
+}
+
+// GetStudentProgress mengembalikan perkembangan nilai seorang siswa dari waktu ke waktu
+// per topik (tag soal), perkiraan penguasaan, dan perbandingan dengan rata-rata kelas.
+// Parameter period adalah week (bawaan) atau month.
+func GetStudentProgress(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	student, err := repository.NewStudentRepository().WithContext(c.Request.Context()).FindByID(uint(id))
+	if err != nil {
+		requestLog(c).Error("Error finding student", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data siswa"})
+		return
+	}
+	if student == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Siswa tidak ditemukan"})
+		return
+	}
+
+	sendProgress(c, student)
+}
+
+// GetMyProgress mengembalikan perkembangan nilai siswa yang sedang login
+func GetMyProgress(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	student, err := repository.NewStudentRepository().WithContext(c.Request.Context()).FindByUserID(userID.(uint))
+	if err != nil {
+		requestLog(c).Error("Error finding student", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data siswa"})
+		return
+	}
+	if student == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profil siswa tidak ditemukan"})
+		return
+	}
+
+	sendProgress(c, student)
+}
+
+// sendProgress menghitung dan mengirim laporan perkembangan siswa
+func sendProgress(c *gin.Context, student *models.Student) {
+	period := analytics.Period(c.DefaultQuery("period", string(analytics.PeriodWeek)))
+	if period != analytics.PeriodWeek && period != analytics.PeriodMonth {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter period harus week atau month"})
+		return
+	}
+
+	questions, answers, err := loadExamAnswers(c.Request.Context(), student.Class)
+	if err != nil {
+		requestLog(c).Error("Error loading answers for progress", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil jawaban siswa"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": analytics.ProgressReport(*student, questions, answers, period)})
+}
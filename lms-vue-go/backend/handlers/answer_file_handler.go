@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+	"lms-vue-go/backend/storage"
+)
+
+// answerFilePolicy mengubah aturan file soal menjadi batas ukuran dan tipe upload
+func answerFilePolicy(rule *models.FileRule) storage.Policy {
+	return storage.Policy{MaxSize: int64(rule.MaxSizeMB) << 20, Types: rule.Types}
+}
+
+// UploadAnswerFile mengunggah file jawaban siswa untuk soal esai (?question_id=) dari
+// field multipart 'file'. File baru terlampir pada jawaban setelah dikirim lewat
+// file_ids pada /api/answers/submit.
+func UploadAnswerFile(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	student, err := repository.NewStudentRepository().WithContext(c.Request.Context()).FindByUserID(userID.(uint))
+	if err != nil {
+		requestLog(c).Error("Error finding student", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data siswa"})
+		return
+	}
+	if student == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profil siswa tidak ditemukan"})
+		return
+	}
+
+	questionID, err := strconv.Atoi(c.Query("question_id"))
+	if err != nil || questionID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter question_id tidak valid"})
+		return
+	}
+	question, err := repository.NewQuestionRepository().WithContext(c.Request.Context()).FindByID(uint(questionID))
+	if err != nil {
+		requestLog(c).Error("Error finding question", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data soal"})
+		return
+	}
+	if question == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Soal tidak ditemukan"})
+		return
+	}
+	if question.FileRule == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Soal ini tidak menerima lampiran file"})
+		return
+	}
+
+	upload, ok := storeUpload(c, models.UploadAnswerFile, answerFilePolicy(question.FileRule))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": upload, "message": "File berhasil diunggah"})
+}
+
+// answerIDByFile mengembalikan ID jawaban tempat file terlampir (0 jika belum); diganti dalam test
+var answerIDByFile = func(ctx context.Context, uploadID uint) (uint, error) {
+	return repository.NewStudentAnswerRepository().WithContext(ctx).FindAnswerIDByFile(uploadID)
+}
+
+// checkAnswerFiles memeriksa file yang akan dilampirkan pada jawaban: file jawaban milik
+// pengguna, belum dipakai jawaban lain, dan sesuai aturan file soal. answerID adalah
+// jawaban yang sedang diubah (0 untuk jawaban baru). Jika gagal, response error sudah dikirim.
+func checkAnswerFiles(c *gin.Context, question *models.Question, userID, answerID uint, fileIDs []uint) ([]models.Upload, bool) {
+	if len(fileIDs) == 0 {
+		return nil, true
+	}
+	rule := question.FileRule
+	if rule == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Soal ini tidak menerima lampiran file"})
+		return nil, false
+	}
+	if len(fileIDs) > rule.MaxFiles {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Maksimal %d file per jawaban", rule.MaxFiles)})
+		return nil, false
+	}
+
+	for i, id := range fileIDs {
+		if slices.Contains(fileIDs[:i], id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("File %d dikirim lebih dari sekali", id)})
+			return nil, false
+		}
+	}
+
+	uploadRepo := repository.NewUploadRepository().WithContext(c.Request.Context())
+	files := make([]models.Upload, 0, len(fileIDs))
+	for _, id := range fileIDs {
+		upload, err := uploadRepo.FindByID(id)
+		if err != nil {
+			requestLog(c).Error("Error finding upload", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data file"})
+			return nil, false
+		}
+
+		var attachedTo uint
+		if upload != nil {
+			if attachedTo, err = answerIDByFile(c.Request.Context(), id); err != nil {
+				requestLog(c).Error("Error finding answer of upload", "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data file"})
+				return nil, false
+			}
+		}
+
+		if msg := answerFileError(rule, upload, id, attachedTo, userID, answerID); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return nil, false
+		}
+
+		upload.URL = uploadURL(upload)
+		files = append(files, *upload)
+	}
+	return files, true
+}
+
+// answerFileError memeriksa satu file jawaban (nil jika tidak ditemukan) yang terlampir pada
+// jawaban attachedTo (0 jika belum). Mengembalikan pesan error, atau "" jika file boleh dipakai.
+func answerFileError(rule *models.FileRule, upload *models.Upload, id, attachedTo, userID, answerID uint) string {
+	if upload == nil || upload.Purpose != models.UploadAnswerFile || upload.UploadedBy != userID {
+		return fmt.Sprintf("File %d tidak ditemukan", id)
+	}
+	if attachedTo != 0 && attachedTo != answerID {
+		return fmt.Sprintf("File %d sudah dipakai pada jawaban lain", id)
+	}
+
+	// File diunggah untuk soal ini, tetapi aturannya bisa berubah sebelum jawaban dikirim
+	policy := answerFilePolicy(rule)
+	if !policy.Allows(upload.ContentType) || upload.Size > policy.MaxSize {
+		return fmt.Sprintf("File %s tidak sesuai aturan soal (maksimal %d MB)", upload.Filename, rule.MaxSizeMB)
+	}
+	return ""
+}
+
+// saveAnswer menyimpan jawaban beserta file lampirannya dalam satu transaksi, lalu menghapus
+// file lama yang tidak dilampirkan lagi. Jika gagal, response error sudah dikirim.
+func saveAnswer(c *gin.Context, answer *models.StudentAnswer, fileIDs *[]uint, files, oldFiles []models.Upload) bool {
+	err := repository.NewStudentAnswerRepository().WithContext(c.Request.Context()).Save(answer, fileIDs)
+	if errors.Is(err, repository.ErrFileAttached) {
+		c.JSON(http.StatusConflict, gin.H{"error": "File sudah dipakai pada jawaban lain"})
+		return false
+	}
+	if err != nil {
+		requestLog(c).Error("Error saving answer", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan jawaban"})
+		return false
+	}
+
+	if fileIDs != nil {
+		var removed []models.Upload
+		for _, old := range oldFiles {
+			if !slices.Contains(*fileIDs, old.ID) {
+				removed = append(removed, old)
+			}
+		}
+		deleteUploads(c, removed)
+	}
+
+	answer.Files = files
+	return true
+}
+
+// answerFiles mengambil file jawaban beserta link baru untuk membukanya, dikelompokkan per ID jawaban
+func answerFiles(ctx context.Context, answerIDs []uint) (map[uint][]models.Upload, error) {
+	files, err := repository.NewStudentAnswerRepository().WithContext(ctx).FindFiles(answerIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range files {
+		for i := range list {
+			list[i].URL = uploadURL(&list[i])
+		}
+	}
+	return files, nil
+}
+
+// deleteUploads menghapus file yang tidak lagi dilampirkan; kegagalan hanya dicatat
+func deleteUploads(c *gin.Context, uploads []models.Upload) {
+	uploadRepo := repository.NewUploadRepository().WithContext(c.Request.Context())
+	for _, upload := range uploads {
+		if err := uploadRepo.Delete(upload.ID); err != nil {
+			requestLog(c).Error("Error deleting upload", "upload_id", upload.ID, "error", err)
+			continue
+		}
+		if err := fileStore.Delete(c.Request.Context(), upload.StorageKey); err != nil {
+			requestLog(c).Error("Error deleting stored file", "key", upload.StorageKey, "error", err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/models"
+)
+
+func TestCheckAnswerFilesRejectsBeforeLookup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rule := &models.FileRule{Types: []string{"application/pdf"}, MaxSizeMB: 5, MaxFiles: 2}
+
+	cases := []struct {
+		name     string
+		rule     *models.FileRule
+		fileIDs  []uint
+		wantOK   bool
+		wantCode int
+		wantErr  string
+	}{
+		{name: "no files", rule: nil, fileIDs: nil, wantOK: true},
+		{name: "question without file rule", rule: nil, fileIDs: []uint{1}, wantCode: http.StatusBadRequest, wantErr: "tidak menerima lampiran"},
+		{name: "too many files", rule: rule, fileIDs: []uint{1, 2, 3}, wantCode: http.StatusBadRequest, wantErr: "Maksimal 2 file"},
+		{name: "duplicate file", rule: rule, fileIDs: []uint{4, 4}, wantCode: http.StatusBadRequest, wantErr: "lebih dari sekali"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/answers/submit", nil)
+
+			files, ok := checkAnswerFiles(c, &models.Question{FileRule: tc.rule}, 7, 0, tc.fileIDs)
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %v, want %v (response %s)", ok, tc.wantOK, w.Body.String())
+			}
+			if tc.wantOK {
+				if len(files) != 0 || w.Body.Len() != 0 {
+					t.Errorf("expected no files and no response, got %v and %s", files, w.Body.String())
+				}
+				return
+			}
+			if w.Code != tc.wantCode || !strings.Contains(w.Body.String(), tc.wantErr) {
+				t.Errorf("response %d %s, want %d containing %q", w.Code, w.Body.String(), tc.wantCode, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnswerFileError(t *testing.T) {
+	rule := &models.FileRule{Types: []string{"application/pdf", "image/*"}, MaxSizeMB: 1, MaxFiles: 3}
+	upload := func(mutate func(u *models.Upload)) *models.Upload {
+		u := &models.Upload{ID: 5, Filename: "tugas.pdf", ContentType: "application/pdf", Size: 1000, Purpose: models.UploadAnswerFile, UploadedBy: 7}
+		if mutate != nil {
+			mutate(u)
+		}
+		return u
+	}
+
+	cases := []struct {
+		name       string
+		upload     *models.Upload
+		attachedTo uint
+		answerID   uint
+		wantErr    string
+	}{
+		{name: "new file", upload: upload(nil)},
+		{name: "image matches wildcard", upload: upload(func(u *models.Upload) { u.ContentType = "image/png" })},
+		{name: "file of the answer being edited", upload: upload(nil), attachedTo: 9, answerID: 9},
+		{name: "unknown file", upload: nil, wantErr: "tidak ditemukan"},
+		{name: "file of another user", upload: upload(func(u *models.Upload) { u.UploadedBy = 8 }), wantErr: "tidak ditemukan"},
+		{name: "question image", upload: upload(func(u *models.Upload) { u.Purpose = models.UploadQuestionImage }), wantErr: "tidak ditemukan"},
+		{name: "attached to another answer", upload: upload(nil), attachedTo: 3, answerID: 9, wantErr: "jawaban lain"},
+		{name: "attached while creating a new answer", upload: upload(nil), attachedTo: 3, wantErr: "jawaban lain"},
+		{name: "type not allowed", upload: upload(func(u *models.Upload) { u.ContentType = "application/zip" }), wantErr: "tidak sesuai aturan"},
+		{name: "too large", upload: upload(func(u *models.Upload) { u.Size = 2 << 20 }), wantErr: "maksimal 1 MB"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := answerFileError(rule, tc.upload, 5, tc.attachedTo, 7, tc.answerID)
+			if tc.wantErr == "" && got != "" || !strings.Contains(got, tc.wantErr) {
+				t.Errorf("answerFileError = %q, want %q", got, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUploadDeletableKeepsAttachedAnswerFiles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer func(find func(context.Context, uint) (uint, error)) { answerIDByFile = find }(answerIDByFile)
+	attached := map[uint]uint{5: 9}
+	answerIDByFile = func(_ context.Context, uploadID uint) (uint, error) { return attached[uploadID], nil }
+
+	cases := []struct {
+		name     string
+		upload   *models.Upload
+		wantOK   bool
+		wantCode int
+	}{
+		{name: "attached answer file", upload: &models.Upload{ID: 5, Purpose: models.UploadAnswerFile}, wantCode: http.StatusConflict},
+		{name: "answer file not yet submitted", upload: &models.Upload{ID: 6, Purpose: models.UploadAnswerFile}, wantOK: true},
+		{name: "question image", upload: &models.Upload{ID: 5, Purpose: models.UploadQuestionImage}, wantOK: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/uploads/5", nil)
+
+			if ok := uploadDeletable(c, tc.upload); ok != tc.wantOK {
+				t.Fatalf("uploadDeletable = %v, want %v", ok, tc.wantOK)
+			}
+			if !tc.wantOK && w.Code != tc.wantCode {
+				t.Errorf("response %d %s, want %d", w.Code, w.Body.String(), tc.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Batas token API: panjang nama, masa berlaku maksimum, dan jumlah token aktif per pengguna
+const (
+	maxAPITokenNameLength = 100
+	maxAPITokenLifetime   = 365 * 24 * time.Hour
+	maxAPITokensPerUser   = 20
+)
+
+// CreateAPITokenRequest adalah struktur untuk membuat token API
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1"`
+}
+
+// ListAPITokens mengembalikan token API milik pengguna yang sedang login (tanpa nilai token)
+func ListAPITokens(c *gin.Context) {
+	tokenRepo := repository.NewAPITokenRepository().WithContext(c.Request.Context())
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tidak terautentikasi"})
+		return
+	}
+
+	tokens, err := tokenRepo.FindByUser(userID.(uint))
+	if err != nil {
+		requestLog(c).Error("Error listing API tokens", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil token API"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tokens})
+}
+
+// CreateAPIToken membuat token API baru. Nilai token hanya ditampilkan sekali di response ini.
+func CreateAPIToken(c *gin.Context) {
+	tokenRepo := repository.NewAPITokenRepository().WithContext(c.Request.Context())
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tidak terautentikasi"})
+		return
+	}
+
+	var req CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxAPITokenNameLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nama token tidak valid"})
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !models.IsValidScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Scope tidak valid: " + scope})
+			return
+		}
+	}
+
+	// Token yang sudah dicabut atau kedaluwarsa tidak dihitung
+	existing, err := tokenRepo.FindByUser(userID.(uint))
+	if err != nil {
+		requestLog(c).Error("Error listing API tokens", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token API"})
+		return
+	}
+	active := 0
+	for i := range existing {
+		if existing[i].IsUsable(time.Now()) {
+			active++
+		}
+	}
+	if active >= maxAPITokensPerUser {
+		c.JSON(http.StatusConflict, gin.H{"error": "Maksimal " + strconv.Itoa(maxAPITokensPerUser) + " token aktif per pengguna, cabut token lama terlebih dahulu"})
+		return
+	}
+
+	token := models.APIToken{
+		UserID: userID.(uint),
+		Name:   req.Name,
+		Scopes: req.Scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		lifetime := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+		if lifetime > maxAPITokenLifetime {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Masa berlaku token maksimal 365 hari"})
+			return
+		}
+		expiresAt := time.Now().Add(lifetime)
+		token.ExpiresAt = &expiresAt
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token API"})
+		return
+	}
+	rawToken := models.APITokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	token.Prefix = rawToken[:len(models.APITokenPrefix)+6]
+
+	if err := tokenRepo.Create(&token, rawToken); err != nil {
+		requestLog(c).Error("Error creating API token", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token API"})
+		return
+	}
+	token.CreatedAt = time.Now()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    token,
+		"token":   rawToken,
+		"message": "Simpan token ini sekarang, token tidak akan ditampilkan lagi",
+	})
+}
+
+// RevokeAPIToken mencabut token API milik pengguna yang sedang login
+func RevokeAPIToken(c *gin.Context) {
+	tokenRepo := repository.NewAPITokenRepository().WithContext(c.Request.Context())
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tidak terautentikasi"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	revoked, err := tokenRepo.Revoke(uint(id), userID.(uint))
+	if err != nil {
+		requestLog(c).Error("Error revoking API token", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut token API"})
+		return
+	}
+
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token API berhasil dicabut"})
+}
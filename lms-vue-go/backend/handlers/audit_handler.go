@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/audit"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+)
+
+// auditVerifyBatch adalah jumlah catatan yang dibaca sekaligus saat memeriksa rantai hash
+const auditVerifyBatch = 1000
+
+// RecordAudit mencatat perubahan data ke log audit. before dan after adalah nilai
+// sebelum dan sesudah perubahan (nil untuk data baru atau data yang dihapus) dan
+// tidak boleh memuat password atau token. Perubahan sudah tersimpan saat fungsi
+// ini dipanggil, jadi kegagalan tidak membatalkannya: catatan lengkap ditulis ke log
+// server agar dapat dimasukkan ulang, dan response diberi header Warning.
+// Perubahan nilai memakai newAuditEntry agar tersimpan dalam transaksi yang sama.
+func RecordAudit(c *gin.Context, action models.AuditAction, entity models.AuditEntity, entityID uint, before, after interface{}) {
+	entry, err := newAuditEntry(c, action, entity, entityID, before, after)
+	if err == nil {
+		err = repository.NewAuditRepository().WithContext(c.Request.Context()).Append(entry)
+	}
+	if err != nil {
+		requestLog(c).Error("Error writing audit log, entry lost",
+			"action", action,
+			"entity", entity,
+			"entity_id", entityID,
+			"before", before,
+			"after", after,
+			"error", err,
+		)
+		c.Header("Warning", `199 - "Perubahan tersimpan tetapi gagal dicatat di log audit"`)
+	}
+}
+
+// newAuditEntry menyusun catatan audit untuk perubahan oleh pengguna request c
+func newAuditEntry(c *gin.Context, action models.AuditAction, entity models.AuditEntity, entityID uint, before, after interface{}) (*models.AuditLog, error) {
+	entry := models.AuditLog{
+		Action:     action,
+		EntityType: entity,
+		EntityID:   entityID,
+		IP:         c.ClientIP(),
+	}
+	if userID, ok := c.Get("userID"); ok {
+		id := userID.(uint)
+		entry.ActorID = &id
+	}
+	if role, ok := c.Get("userRole"); ok {
+		entry.ActorRole, _ = role.(models.Role)
+	}
+
+	var err error
+	if entry.Before, err = auditValue(before); err != nil {
+		return nil, err
+	}
+	if entry.After, err = auditValue(after); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// auditValue mengubah nilai menjadi JSON; nil tetap kosong
+func auditValue(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// ListAuditLogs mengembalikan log audit per halaman (hanya admin), terbaru lebih dulu.
+// Filter: actor_id, action, entity_type, entity_id, ip, from dan to (RFC 3339 atau YYYY-MM-DD).
+func ListAuditLogs(c *gin.Context) {
+	query, ok := parseListQuery(c, repository.AuditListSpec)
+	if !ok {
+		return
+	}
+
+	entries, total, err := repository.NewAuditRepository().WithContext(c.Request.Context()).List(query)
+	if err != nil {
+		requestLog(c).Error("Error listing audit logs", "error", err)
+		respondListError(c, err, "Gagal mengambil log audit")
+		return
+	}
+
+	respondList(c, entries, total, query)
+}
+
+// VerifyAuditLog memeriksa seluruh rantai hash log audit (hanya admin). Catatan yang
+// diubah, disisipkan atau dihapus memutus rantai; broken_id menunjuk catatan pertama
+// yang tidak cocok. head_hash dapat disimpan di luar sistem sebagai pembanding.
+func VerifyAuditLog(c *gin.Context) {
+	auditRepo := repository.NewAuditRepository().WithContext(c.Request.Context())
+
+	head, err := auditRepo.Head()
+	if err != nil {
+		requestLog(c).Error("Error reading audit log head", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa log audit"})
+		return
+	}
+
+	// Catatan yang ditambahkan selama pemeriksaan diabaikan: rantai diperiksa sampai head
+	prevHash := audit.GenesisHash
+	var lastID uint64
+	checked := 0
+	for prevHash != head {
+		entries, err := auditRepo.FindAfter(lastID, auditVerifyBatch)
+		if err != nil {
+			requestLog(c).Error("Error reading audit logs", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa log audit"})
+			return
+		}
+		if len(entries) == 0 {
+			// Catatan terakhir yang dihapus tidak memutus rantai, tetapi head tidak pernah tercapai
+			c.JSON(http.StatusOK, gin.H{"data": gin.H{
+				"valid":     false,
+				"checked":   checked,
+				"head_hash": head,
+				"error":     "Catatan terakhir log audit tidak ditemukan",
+			}})
+			return
+		}
+
+		if i := audit.Verify(entries, prevHash); i >= 0 {
+			c.JSON(http.StatusOK, gin.H{"data": gin.H{
+				"valid":     false,
+				"checked":   checked + i,
+				"broken_id": entries[i].ID,
+				"head_hash": head,
+			}})
+			return
+		}
+		for _, entry := range entries {
+			checked++
+			prevHash, lastID = entry.Hash, entry.ID
+			if prevHash == head {
+				break
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"valid": true, "checked": checked, "head_hash": head}})
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"lms-vue-go/backend/auth"
+	"lms-vue-go/backend/config"
 	"lms-vue-go/backend/models"
 	"lms-vue-go/backend/repository"
 
@@ -18,6 +21,19 @@ var jwtSecret = []byte("lms-secret-key-change-in-production")
 // Durasi token JWT
 const tokenDuration = 24 * time.Hour
 
+// loginAuthenticator memverifikasi password saat login, sesuai AUTH_BACKENDS
+var loginAuthenticator = newLoginAuthenticator()
+
+// newLoginAuthenticator membuat rantai autentikasi; kembali ke autentikasi lokal jika konfigurasi salah
+func newLoginAuthenticator() auth.Authenticator {
+	authenticator, err := auth.NewFromConfig(config.DefaultAuthConfig())
+	if err != nil {
+		slog.Warn("Invalid AUTH_BACKENDS, falling back to local authentication", "error", err)
+		return auth.NewLocalAuthenticator()
+	}
+	return authenticator
+}
+
 // userRepo adalah repository untuk operasi user
 // Akan diinisialisasi di setiap handler untuk memastikan koneksi DB sudah ada
 
@@ -42,33 +58,54 @@ type JWTClaims struct {
 
 // Login menangani proses login pengguna
 func Login(c *gin.Context) {
-	// Inisialisasi repository
-	userRepo := repository.NewUserRepository()
-
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
 		return
 	}
 
-	// Cari pengguna berdasarkan username
-	user, err := userRepo.FindByUsername(req.Username)
-	if err != nil {
-		log.Printf("Error in Login: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencari pengguna: " + err.Error()})
+	// Tolak jika username atau IP sedang dikunci karena terlalu banyak login gagal
+	if throttled(c, req.Username) {
 		return
 	}
 
-	// Jika pengguna tidak ditemukan atau password salah
-	if user == nil || req.Password != user.Password {
+	// Verifikasi username dan password melalui backend autentikasi (lokal dan/atau LDAP).
+	// Pengguna tidak ditemukan dan password salah menghasilkan error yang sama.
+	user, err := loginAuthenticator.Authenticate(c.Request.Context(), req.Username, req.Password)
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		if err := recordLoginFailure(c.Request.Context(), req.Username, c.ClientIP()); err != nil {
+			requestLog(c).Error("Error recording login failure", "error", err)
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Username atau password salah"})
 		return
 	}
+	if err != nil {
+		requestLog(c).Error("Error in Login", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memverifikasi pengguna"})
+		return
+	}
+
+	// Akun yang dinonaktifkan admin tidak boleh login
+	if !user.IsActive {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Akun Anda telah dinonaktifkan"})
+		return
+	}
+
+	// Jika 2FA aktif atau diwajibkan, kirim challenge langkah kedua alih-alih token
+	if startTwoFactorChallenge(c, user, http.StatusOK) {
+		return
+	}
+
+	// Hitungan gagal baru dihapus saat token diterbitkan; jika ada langkah 2FA,
+	// VerifyTwoFactor yang menghapusnya setelah kode benar
+	if err := resetLoginFailures(c.Request.Context(), req.Username); err != nil {
+		requestLog(c).Error("Error resetting login failures", "error", err)
+	}
 
 	// Buat token JWT
 	token, err := generateJWT(user)
 	if err != nil {
-		log.Printf("Error generating JWT: %v", err)
+		requestLog(c).Error("Error generating JWT", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token"})
 		return
 	}
@@ -83,9 +120,9 @@ func Login(c *gin.Context) {
 // Register menangani pendaftaran pengguna baru
 func Register(c *gin.Context) {
 	// Inisialisasi repository
-	userRepo := repository.NewUserRepository()
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
 	// Inisialisasi student repository
-	studentRepo := repository.NewStudentRepository()
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
 
 	// Struktur untuk binding request
 	type RegisterRequest struct {
@@ -114,7 +151,7 @@ func Register(c *gin.Context) {
 	// Cek apakah username sudah digunakan
 	existingUser, err := userRepo.FindByUsername(user.Username)
 	if err != nil {
-		log.Printf("Error in Register: %v", err)
+		requestLog(c).Error("Error in Register", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa username: " + err.Error()})
 		return
 	}
@@ -132,7 +169,7 @@ func Register(c *gin.Context) {
 	// Simpan user ke database
 	err = userRepo.Create(&user)
 	if err != nil {
-		log.Printf("Error creating user: %v", err)
+		requestLog(c).Error("Error creating user", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mendaftarkan pengguna: " + err.Error()})
 		return
 	}
@@ -161,12 +198,22 @@ func Register(c *gin.Context) {
 		// Simpan student ke database
 		err = studentRepo.Create(&student, user.ID)
 		if err != nil {
-			log.Printf("Error creating student record: %v", err)
+			requestLog(c).Error("Error creating student record", "error", err)
 			// Tidak mengembalikan error ke client karena user sudah dibuat
 			// Hanya log error untuk admin
 		}
 	}
 
+	// Kirim email verifikasi (kegagalan tidak menggagalkan pendaftaran)
+	if err := sendVerificationEmail(c.Request.Context(), &user); err != nil {
+		requestLog(c).Error("Error sending verification email", "error", err)
+	}
+
+	// Role yang wajib 2FA harus mendaftar 2FA sebelum mendapatkan token
+	if startTwoFactorChallenge(c, &user, http.StatusCreated) {
+		return
+	}
+
 	// Buat token JWT
 	token, err := generateJWT(&user)
 	if err != nil {
@@ -184,7 +231,7 @@ func Register(c *gin.Context) {
 // GetCurrentUser mengembalikan data pengguna yang sedang login
 func GetCurrentUser(c *gin.Context) {
 	// Inisialisasi repository
-	userRepo := repository.NewUserRepository()
+	userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
 
 	// Ambil user dari context yang sudah diset oleh middleware
 	userID, exists := c.Get("userID")
@@ -196,7 +243,7 @@ func GetCurrentUser(c *gin.Context) {
 	// Cari user berdasarkan ID
 	user, err := userRepo.FindByID(userID.(uint))
 	if err != nil {
-		log.Printf("Error in GetCurrentUser: %v", err)
+		requestLog(c).Error("Error in GetCurrentUser", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pengguna: " + err.Error()})
 		return
 	}
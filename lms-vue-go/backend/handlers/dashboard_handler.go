@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/repository"
+)
+
+// Jumlah baris daftar jawaban terbaru dan siswa dengan tugas tertinggal
+const (
+	defaultDashboardLimit = 10
+	maxDashboardLimit     = 50
+)
+
+// GetTeacherDashboard mengembalikan ringkasan dashboard guru: jawaban esai yang belum
+// dinilai, jawaban terbaru, rata-rata per kelas, siswa yang belum menjawab semua soal dan
+// batas waktu ujian yang akan datang. Parameter limit mengatur panjang daftar (bawaan 10, maksimal 50).
+func GetTeacherDashboard(c *gin.Context) {
+	limit := defaultDashboardLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxDashboardLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter limit harus antara 1 dan " + strconv.Itoa(maxDashboardLimit)})
+			return
+		}
+		limit = n
+	}
+
+	summary, err := repository.NewDashboardRepository().WithContext(c.Request.Context()).Summary(limit)
+	if err != nil {
+		requestLog(c).Error("Error computing dashboard", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data dashboard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
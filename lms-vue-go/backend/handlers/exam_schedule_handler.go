@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+)
+
+// Batas panjang judul dan nama kelas jadwal ujian, sesuai kolom tabel
+const (
+	maxExamTitleLength = 150
+	maxExamClassLength = 20
+)
+
+// ExamScheduleRequest adalah struktur untuk membuat atau mengubah jadwal ujian
+type ExamScheduleRequest struct {
+	Title string    `json:"title" binding:"required"`
+	Class string    `json:"class"` // Kosong untuk semua kelas
+	DueAt time.Time `json:"due_at" binding:"required"`
+}
+
+// ListExamSchedules mengembalikan semua jadwal ujian, batas waktu terdekat lebih dulu
+func ListExamSchedules(c *gin.Context) {
+	schedules, err := repository.NewExamScheduleRepository().WithContext(c.Request.Context()).FindAll()
+	if err != nil {
+		requestLog(c).Error("Error listing exam schedules", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil jadwal ujian"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": schedules})
+}
+
+// CreateExamSchedule menambahkan jadwal ujian; batas waktunya tampil di dashboard guru
+func CreateExamSchedule(c *gin.Context) {
+	req, ok := bindExamSchedule(c)
+	if !ok {
+		return
+	}
+
+	schedule := models.ExamSchedule{Title: req.Title, Class: req.Class, DueAt: req.DueAt}
+	if userID, ok := c.Get("userID"); ok {
+		id := userID.(uint)
+		schedule.CreatedBy = &id
+	}
+
+	if err := repository.NewExamScheduleRepository().WithContext(c.Request.Context()).Create(&schedule); err != nil {
+		requestLog(c).Error("Error creating exam schedule", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan jadwal ujian"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": schedule, "message": "Jadwal ujian berhasil ditambahkan"})
+}
+
+// UpdateExamSchedule mengubah judul, kelas dan batas waktu jadwal ujian
+func UpdateExamSchedule(c *gin.Context) {
+	scheduleRepo := repository.NewExamScheduleRepository().WithContext(c.Request.Context())
+
+	schedule, ok := findExamSchedule(c, scheduleRepo)
+	if !ok {
+		return
+	}
+	req, ok := bindExamSchedule(c)
+	if !ok {
+		return
+	}
+
+	schedule.Title, schedule.Class, schedule.DueAt = req.Title, req.Class, req.DueAt
+	if err := scheduleRepo.Update(schedule); err != nil {
+		requestLog(c).Error("Error updating exam schedule", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah jadwal ujian"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": schedule, "message": "Jadwal ujian berhasil diubah"})
+}
+
+// DeleteExamSchedule menghapus jadwal ujian
+func DeleteExamSchedule(c *gin.Context) {
+	scheduleRepo := repository.NewExamScheduleRepository().WithContext(c.Request.Context())
+
+	schedule, ok := findExamSchedule(c, scheduleRepo)
+	if !ok {
+		return
+	}
+
+	if err := scheduleRepo.Delete(schedule.ID); err != nil {
+		requestLog(c).Error("Error deleting exam schedule", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus jadwal ujian"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Jadwal ujian berhasil dihapus"})
+}
+
+// bindExamSchedule membaca dan memeriksa body jadwal ujian.
+// Jika gagal, response error sudah dikirim.
+func bindExamSchedule(c *gin.Context) (*ExamScheduleRequest, bool) {
+	var req ExamScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return nil, false
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	req.Class = strings.TrimSpace(req.Class)
+	if req.Title == "" || len([]rune(req.Title)) > maxExamTitleLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Judul ujian wajib diisi, maksimal " + strconv.Itoa(maxExamTitleLength) + " karakter"})
+		return nil, false
+	}
+	if len([]rune(req.Class)) > maxExamClassLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nama kelas maksimal " + strconv.Itoa(maxExamClassLength) + " karakter"})
+		return nil, false
+	}
+	return &req, true
+}
+
+// findExamSchedule mencari jadwal ujian dari parameter :id.
+// Jika gagal, response error sudah dikirim.
+func findExamSchedule(c *gin.Context, scheduleRepo *repository.ExamScheduleRepository) (*models.ExamSchedule, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return nil, false
+	}
+
+	schedule, err := scheduleRepo.FindByID(uint(id))
+	if err != nil {
+		requestLog(c).Error("Error finding exam schedule", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil jadwal ujian"})
+		return nil, false
+	}
+	if schedule == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal ujian tidak ditemukan"})
+		return nil, false
+	}
+	return schedule, true
+}
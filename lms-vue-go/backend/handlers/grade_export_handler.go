@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/gradebook"
+	"lms-vue-go/backend/repository"
+)
+
+// reportCardTitle adalah judul rapor nilai
+const reportCardTitle = "Rapor Nilai Ujian"
+
+// ExportGrades mengekspor nilai seluruh siswa untuk setiap soal ujian ke CSV atau XLSX.
+// Parameter format=csv|xlsx (default xlsx) dan class untuk membatasi satu kelas.
+func ExportGrades(c *gin.Context) {
+	format := c.DefaultQuery("format", "xlsx")
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format ekspor tidak valid, gunakan csv atau xlsx"})
+		return
+	}
+
+	book, ok := loadGradebook(c)
+	if !ok {
+		return
+	}
+	class := strings.TrimSpace(c.Query("class"))
+	rows := book.Filter(func(row gradebook.Row) bool { return class == "" || row.Student.Class == class })
+	if len(rows) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tidak ada siswa untuk diekspor"})
+		return
+	}
+
+	var data []byte
+	var err error
+	contentType := "text/csv; charset=utf-8"
+	if format == "csv" {
+		data, err = book.CSV(rows)
+	} else {
+		sheet := "Nilai"
+		if class != "" {
+			sheet = "Nilai " + class
+		}
+		data, err = book.XLSX(sheet, rows)
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	if err != nil {
+		requestLog(c).Error("Error exporting grades", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengekspor nilai"})
+		return
+	}
+
+	sendGradeFile(c, "nilai", class, format, contentType, data)
+}
+
+// GetReportCards membuat rapor nilai PDF dengan satu halaman per siswa.
+// Parameter class membatasi satu kelas, student_id hanya untuk satu siswa.
+func GetReportCards(c *gin.Context) {
+	var studentID uint
+	if raw := c.Query("student_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID siswa tidak valid"})
+			return
+		}
+		studentID = uint(id)
+	}
+
+	book, ok := loadGradebook(c)
+	if !ok {
+		return
+	}
+	class := strings.TrimSpace(c.Query("class"))
+	rows := book.Filter(func(row gradebook.Row) bool {
+		return (class == "" || row.Student.Class == class) && (studentID == 0 || row.Student.ID == studentID)
+	})
+	if len(rows) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Siswa tidak ditemukan"})
+		return
+	}
+
+	name := class
+	if studentID != 0 {
+		name = rows[0].Student.Name
+	}
+	sendGradeFile(c, "rapor", name, "pdf", "application/pdf", book.ReportCards(reportCardTitle, rows))
+}
+
+// GetMyReportCard membuat rapor nilai PDF untuk siswa yang sedang login
+func GetMyReportCard(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tidak terautentikasi"})
+		return
+	}
+
+	student, err := repository.NewStudentRepository().WithContext(c.Request.Context()).FindByUserID(userID.(uint))
+	if err != nil {
+		requestLog(c).Error("Error fetching student for report card", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data siswa"})
+		return
+	}
+	if student == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profil siswa tidak ditemukan"})
+		return
+	}
+
+	book, ok := loadGradebook(c)
+	if !ok {
+		return
+	}
+	rows := book.Filter(func(row gradebook.Row) bool { return row.Student.ID == student.ID })
+	if len(rows) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profil siswa tidak ditemukan"})
+		return
+	}
+
+	sendGradeFile(c, "rapor", student.Name, "pdf", "application/pdf", book.ReportCards(reportCardTitle, rows))
+}
+
+// loadGradebook menyusun tabel nilai dari semua siswa, soal ujian dan jawaban.
+// Rata-rata kelas selalu dihitung dari seluruh siswa kelas tersebut.
+// Jika gagal, response error sudah dikirim.
+func loadGradebook(c *gin.Context) (*gradebook.Gradebook, bool) {
+	students, err := repository.NewStudentRepository().WithContext(c.Request.Context()).FindAll()
+	if err != nil {
+		requestLog(c).Error("Error fetching students for gradebook", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data siswa"})
+		return nil, false
+	}
+
+	questions, err := repository.NewQuestionRepository().WithContext(c.Request.Context()).FindAll()
+	if err != nil {
+		requestLog(c).Error("Error fetching questions for gradebook", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data soal"})
+		return nil, false
+	}
+
+	answers, err := repository.NewStudentAnswerRepository().WithContext(c.Request.Context()).FindAll()
+	if err != nil {
+		requestLog(c).Error("Error fetching answers for gradebook", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil jawaban siswa"})
+		return nil, false
+	}
+
+	return gradebook.Build(students, questions, answers), true
+}
+
+// sendGradeFile mengirim file ekspor nilai sebagai lampiran
+func sendGradeFile(c *gin.Context, prefix, name, extension, contentType string, data []byte) {
+	filename := prefix
+	if slug := fileSlug(name); slug != "" {
+		filename += "-" + slug
+	}
+	filename = fmt.Sprintf("%s-%s.%s", filename, time.Now().Format("20060102"), extension)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// fileSlug mengubah nama kelas atau siswa menjadi bagian nama file yang aman
+func fileSlug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"lms-vue-go/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Ukuran halaman untuk endpoint daftar
+const (
+	defaultListPageSize = 20
+	maxListPageSize     = 100
+)
+
+// listReservedParams adalah parameter query yang tidak dianggap sebagai filter
+var listReservedParams = map[string]bool{
+	"q": true, "sort": true, "limit": true, "page": true, "offset": true, "cursor": true,
+}
+
+// parseListQuery membaca parameter paginasi, sorting, filter, dan pencarian dari query string:
+//
+//	?q=teks&sort=-created_at&limit=20&page=2 (atau offset=20 atau cursor=...)&type=essay
+//
+// Sort diawali "-" untuk urutan menurun. Parameter lain yang dikenal spec menjadi filter.
+// Jika gagal, response error sudah dikirim.
+func parseListQuery(c *gin.Context, spec repository.ListSpec) (repository.ListQuery, bool) {
+	query := repository.ListQuery{
+		Search:  strings.TrimSpace(c.Query("q")),
+		Filters: map[string]string{},
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultListPageSize)))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter limit tidak valid"})
+		return query, false
+	}
+	if limit > maxListPageSize {
+		limit = maxListPageSize
+	}
+	query.Limit = limit
+
+	switch {
+	case c.Query("cursor") != "":
+		offset, ok := decodeListCursor(c.Query("cursor"))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter cursor tidak valid"})
+			return query, false
+		}
+		query.Offset = offset
+	case c.Query("offset") != "":
+		offset, err := strconv.Atoi(c.Query("offset"))
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter offset tidak valid"})
+			return query, false
+		}
+		query.Offset = offset
+	case c.Query("page") != "":
+		page, err := strconv.Atoi(c.Query("page"))
+		if err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter page tidak valid"})
+			return query, false
+		}
+		query.Offset = (page - 1) * limit
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		query.Desc = strings.HasPrefix(sort, "-")
+		query.Sort = strings.TrimPrefix(sort, "-")
+		if !spec.HasSort(query.Sort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter sort tidak valid: " + query.Sort})
+			return query, false
+		}
+	}
+
+	for name, values := range c.Request.URL.Query() {
+		if listReservedParams[name] || !spec.HasFilter(name) || len(values) == 0 {
+			continue
+		}
+		query.Filters[name] = values[0]
+	}
+
+	return query, true
+}
+
+// respondList mengirim satu halaman data beserta total dan tautan halaman berikutnya/sebelumnya
+func respondList(c *gin.Context, data interface{}, total int, query repository.ListQuery) {
+	meta := gin.H{
+		"total":  total,
+		"limit":  query.Limit,
+		"offset": query.Offset,
+		"page":   query.Offset/query.Limit + 1,
+	}
+	links := gin.H{"self": c.Request.URL.RequestURI()}
+
+	if next := query.Offset + query.Limit; next < total {
+		cursor := encodeListCursor(next)
+		meta["next_cursor"] = cursor
+		links["next"] = listPageURL(c, cursor)
+	}
+	if query.Offset > 0 {
+		prev := query.Offset - query.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		links["prev"] = listPageURL(c, encodeListCursor(prev))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  data,
+		"meta":  meta,
+		"links": links,
+	})
+}
+
+// respondListError mengirim response untuk error dari repository List
+func respondListError(c *gin.Context, err error, message string) {
+	if errors.Is(err, repository.ErrInvalidListQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter filter tidak valid"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
+
+// listPageURL membuat URL request saat ini dengan cursor lain
+func listPageURL(c *gin.Context, cursor string) string {
+	u := *c.Request.URL
+	params := u.Query()
+	params.Del("page")
+	params.Del("offset")
+	params.Set("cursor", cursor)
+	u.RawQuery = params.Encode()
+	return u.RequestURI()
+}
+
+// encodeListCursor membungkus offset menjadi cursor opaque
+func encodeListCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+// decodeListCursor membaca offset dari cursor
+func decodeListCursor(cursor string) (int, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	value, ok := strings.CutPrefix(string(raw), "o:")
+	if !ok {
+		return 0, false
+	}
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, false
+	}
+	return offset, true
+}
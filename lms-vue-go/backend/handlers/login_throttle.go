@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+)
+
+// throttlePolicy mengatur kapan login gagal mulai dikunci dan berapa lama
+type throttlePolicy struct {
+	FreeAttempts int           // Jumlah kegagalan sebelum penguncian dimulai
+	BaseLockout  time.Duration // Lama kunci pertama, berlipat ganda setiap kegagalan berikutnya
+	MaxLockout   time.Duration // Batas atas lama kunci
+	ResetAfter   time.Duration // Hitungan dimulai ulang jika tidak ada kegagalan selama durasi ini
+}
+
+// Kebijakan per username dan per IP. IP diberi batas lebih longgar karena
+// banyak siswa bisa login dari jaringan sekolah yang sama.
+var (
+	usernameThrottle = throttlePolicy{FreeAttempts: 5, BaseLockout: 30 * time.Second, MaxLockout: time.Hour, ResetAfter: 24 * time.Hour}
+	ipThrottle       = throttlePolicy{FreeAttempts: 20, BaseLockout: 30 * time.Second, MaxLockout: time.Hour, ResetAfter: 24 * time.Hour}
+)
+
+// loginAttemptStore menyimpan hitungan login gagal; diimplementasikan oleh repository.LoginAttemptRepository
+type loginAttemptStore interface {
+	Find(kind models.LoginAttemptKind, key string) (*models.LoginAttempt, error)
+	RecordFailure(kind models.LoginAttemptKind, key string, next func(existing *models.LoginAttempt) *models.LoginAttempt) error
+	Reset(kind models.LoginAttemptKind, key string) error
+}
+
+// loginAttempts membuka penyimpanan hitungan login gagal untuk request ctx; diganti dalam test
+var loginAttempts = func(ctx context.Context) loginAttemptStore {
+	return repository.NewLoginAttemptRepository().WithContext(ctx)
+}
+
+// lockoutFor menghitung lama kunci untuk jumlah kegagalan tertentu (exponential backoff)
+func (p throttlePolicy) lockoutFor(failures int) time.Duration {
+	if failures < p.FreeAttempts {
+		return 0
+	}
+
+	lockout := p.BaseLockout
+	for i := p.FreeAttempts; i < failures; i++ {
+		lockout *= 2
+		if lockout >= p.MaxLockout {
+			return p.MaxLockout
+		}
+	}
+	return lockout
+}
+
+// nextAttempt mengembalikan catatan kegagalan setelah satu login gagal lagi
+func (p throttlePolicy) nextAttempt(existing *models.LoginAttempt, kind models.LoginAttemptKind, key string, now time.Time) *models.LoginAttempt {
+	attempt := &models.LoginAttempt{Kind: kind, Key: key}
+	if existing != nil && now.Sub(existing.LastFailureAt) < p.ResetAfter {
+		attempt.Failures = existing.Failures
+	}
+
+	attempt.Failures++
+	attempt.LastFailureAt = now
+
+	if lockout := p.lockoutFor(attempt.Failures); lockout > 0 {
+		lockedUntil := now.Add(lockout)
+		attempt.LockedUntil = &lockedUntil
+	}
+
+	return attempt
+}
+
+// normalizeUsername menyamakan username agar variasi huruf besar tidak melewati batas
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// loginRetryAfter mengembalikan sisa waktu kunci untuk username atau IP (0 jika tidak dikunci)
+func loginRetryAfter(ctx context.Context, username, ip string) (time.Duration, error) {
+	attemptRepo := loginAttempts(ctx)
+	now := time.Now()
+
+	var retryAfter time.Duration
+	keys := []struct {
+		kind models.LoginAttemptKind
+		key  string
+	}{
+		{models.LoginAttemptUsername, normalizeUsername(username)},
+		{models.LoginAttemptIP, ip},
+	}
+
+	for _, k := range keys {
+		attempt, err := attemptRepo.Find(k.kind, k.key)
+		if err != nil {
+			return 0, err
+		}
+		if attempt != nil && attempt.IsLocked(now) {
+			if remaining := attempt.LockedUntil.Sub(now); remaining > retryAfter {
+				retryAfter = remaining
+			}
+		}
+	}
+
+	return retryAfter, nil
+}
+
+// recordLoginFailure mencatat login gagal untuk username dan IP
+func recordLoginFailure(ctx context.Context, username, ip string) error {
+	attemptRepo := loginAttempts(ctx)
+	now := time.Now()
+
+	username = normalizeUsername(username)
+	err := attemptRepo.RecordFailure(models.LoginAttemptUsername, username, func(existing *models.LoginAttempt) *models.LoginAttempt {
+		return usernameThrottle.nextAttempt(existing, models.LoginAttemptUsername, username, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	return attemptRepo.RecordFailure(models.LoginAttemptIP, ip, func(existing *models.LoginAttempt) *models.LoginAttempt {
+		return ipThrottle.nextAttempt(existing, models.LoginAttemptIP, ip, now)
+	})
+}
+
+// resetLoginFailures menghapus hitungan gagal untuk username setelah token login diterbitkan.
+// Password yang benar saja tidak cukup, agar kode 2FA yang salah tetap menambah hitungan.
+// Hitungan IP tidak dihapus agar penyerang tidak bisa mereset dengan akunnya sendiri.
+func resetLoginFailures(ctx context.Context, username string) error {
+	return loginAttempts(ctx).Reset(models.LoginAttemptUsername, normalizeUsername(username))
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"lms-vue-go/backend/auth"
+	"lms-vue-go/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLockoutForBacksOffExponentially(t *testing.T) {
+	policy := throttlePolicy{FreeAttempts: 3, BaseLockout: 10 * time.Second, MaxLockout: time.Minute, ResetAfter: time.Hour}
+
+	cases := map[int]time.Duration{
+		1: 0,
+		2: 0,
+		3: 10 * time.Second,
+		4: 20 * time.Second,
+		5: 40 * time.Second,
+		6: time.Minute,
+		9: time.Minute,
+	}
+	for failures, want := range cases {
+		if got := policy.lockoutFor(failures); got != want {
+			t.Errorf("lockoutFor(%d) = %v, want %v", failures, got, want)
+		}
+	}
+}
+
+func TestNextAttemptResetsAfterQuietPeriod(t *testing.T) {
+	policy := throttlePolicy{FreeAttempts: 3, BaseLockout: 10 * time.Second, MaxLockout: time.Minute, ResetAfter: time.Hour}
+	now := time.Now()
+
+	recent := &models.LoginAttempt{Failures: 2, LastFailureAt: now.Add(-time.Minute)}
+	attempt := policy.nextAttempt(recent, models.LoginAttemptUsername, "admin", now)
+	if attempt.Failures != 3 || attempt.LockedUntil == nil {
+		t.Fatalf("expected third failure to lock, got %+v", attempt)
+	}
+
+	old := &models.LoginAttempt{Failures: 10, LastFailureAt: now.Add(-2 * time.Hour)}
+	attempt = policy.nextAttempt(old, models.LoginAttemptUsername, "admin", now)
+	if attempt.Failures != 1 || attempt.LockedUntil != nil {
+		t.Fatalf("expected count to restart, got %+v", attempt)
+	}
+}
+
+// memoryAttempts menyimpan hitungan login gagal di memori untuk test
+type memoryAttempts map[string]*models.LoginAttempt
+
+func (m memoryAttempts) Find(kind models.LoginAttemptKind, key string) (*models.LoginAttempt, error) {
+	return m[string(kind)+":"+key], nil
+}
+
+func (m memoryAttempts) RecordFailure(kind models.LoginAttemptKind, key string, next func(existing *models.LoginAttempt) *models.LoginAttempt) error {
+	m[string(kind)+":"+key] = next(m[string(kind)+":"+key])
+	return nil
+}
+
+func (m memoryAttempts) Reset(kind models.LoginAttemptKind, key string) error {
+	delete(m, string(kind)+":"+key)
+	return nil
+}
+
+// passwordAuthenticator menerima satu password untuk satu pengguna
+type passwordAuthenticator struct {
+	user     *models.User
+	password string
+}
+
+func (a passwordAuthenticator) Name() string { return "test" }
+
+func (a passwordAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	if username != a.user.Username || password != a.password {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return a.user, nil
+}
+
+func TestPasswordLoginDoesNotResetTwoFactorFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	attempts := memoryAttempts{}
+	user := &models.User{ID: 7, Username: "guru", Role: models.RoleTeacher, IsActive: true}
+	enabledAt := time.Now()
+	settings := &models.UserTOTP{UserID: user.ID, EnabledAt: &enabledAt}
+
+	defer func(store func(context.Context) loginAttemptStore, find func(context.Context, uint) (*models.UserTOTP, error), authenticator auth.Authenticator) {
+		loginAttempts, findTwoFactorSettings, loginAuthenticator = store, find, authenticator
+	}(loginAttempts, findTwoFactorSettings, loginAuthenticator)
+	loginAttempts = func(context.Context) loginAttemptStore { return attempts }
+	findTwoFactorSettings = func(context.Context, uint) (*models.UserTOTP, error) { return settings, nil }
+	loginAuthenticator = passwordAuthenticator{user: user, password: "rahasia"}
+
+	router := gin.New()
+	router.POST("/api/auth/login", Login)
+	login := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"username":"guru","password":"rahasia"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	usernameKey := string(models.LoginAttemptUsername) + ":guru"
+
+	// Setiap putaran menunggu kunci habis, login dengan password benar, lalu salah menebak kode 2FA
+	const rounds, wrongCodes = 3, 5
+	for round := 0; round < rounds; round++ {
+		if attempt := attempts[usernameKey]; attempt != nil && attempt.LockedUntil != nil {
+			expired := time.Now().Add(-time.Second)
+			attempt.LockedUntil = &expired
+		}
+
+		w := login()
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"two_factor_required":true`) {
+			t.Fatalf("round %d: login = %d %s, want a 2FA challenge", round, w.Code, w.Body.String())
+		}
+		for i := 0; i < wrongCodes; i++ {
+			// VerifyTwoFactor mencatat kode yang salah dengan cara yang sama
+			if err := recordLoginFailure(context.Background(), user.Username, "192.0.2.1"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	attempt := attempts[usernameKey]
+	if attempt == nil || attempt.Failures != rounds*wrongCodes {
+		t.Fatalf("username failures = %+v, want %d", attempt, rounds*wrongCodes)
+	}
+	if lockout := attempt.LockedUntil.Sub(attempt.LastFailureAt); lockout != usernameThrottle.lockoutFor(rounds*wrongCodes) || lockout <= usernameThrottle.lockoutFor(wrongCodes) {
+		t.Errorf("lockout = %v, want it to keep escalating to %v", lockout, usernameThrottle.lockoutFor(rounds*wrongCodes))
+	}
+	if w := login(); w.Code != http.StatusTooManyRequests {
+		t.Errorf("login while locked = %d, want 429", w.Code)
+	}
+
+	// Login yang menerbitkan token menghapus hitungan username
+	settings = nil
+	attempt.LockedUntil = nil
+	if w := login(); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"token"`) {
+		t.Fatalf("login without 2FA = %d %s, want a token", w.Code, w.Body.String())
+	}
+	if attempts[usernameKey] != nil {
+		t.Errorf("username failures were not reset after a token was issued: %+v", attempts[usernameKey])
+	}
+}
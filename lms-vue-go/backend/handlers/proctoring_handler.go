@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/proctoring"
+	"lms-vue-go/backend/repository"
+)
+
+const (
+	// maxProctoringBatch adalah jumlah kejadian paling banyak dalam satu kiriman
+	maxProctoringBatch = 100
+	// maxProctoringEventAge adalah umur kejadian paling lama yang masih diterima,
+	// agar kejadian yang tertahan saat koneksi putus tetap dapat dikirim
+	maxProctoringEventAge = 24 * time.Hour
+	// proctoringClockSkew adalah selisih jam browser ke depan yang masih diterima
+	proctoringClockSkew = 5 * time.Minute
+)
+
+// proctoringSessionPattern adalah format ID sesi ujian yang dibuat browser, misalnya UUID
+var proctoringSessionPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+// ProctoringEventInput adalah satu kejadian yang dikirim browser
+type ProctoringEventInput struct {
+	Type       models.ProctoringEventType `json:"type" binding:"required"`
+	QuestionID *uint                      `json:"question_id"`
+	Length     int                        `json:"length"`      // Jumlah karakter untuk paste dan copy
+	OccurredAt time.Time                  `json:"occurred_at"` // Bawaan: waktu diterima
+}
+
+// ProctoringBatchRequest adalah kiriman kejadian dari satu sesi ujian
+type ProctoringBatchRequest struct {
+	SessionID string                 `json:"session_id" binding:"required"`
+	Events    []ProctoringEventInput `json:"events" binding:"required"`
+}
+
+// proctoringSession adalah ringkasan satu sesi ujian tanpa linimasa
+type proctoringSession struct {
+	SessionID string                   `json:"session_id"`
+	StartedAt time.Time                `json:"started_at"`
+	EndedAt   time.Time                `json:"ended_at"`
+	Summary   models.ProctoringSummary `json:"summary"`
+}
+
+// sessionAnswer adalah jawaban siswa untuk soal yang dikerjakan dalam sesi beserta
+// ringkasan kejadian saat soal itu dikerjakan
+type sessionAnswer struct {
+	QuestionID uint                     `json:"question_id"`
+	Answer     *models.StudentAnswer    `json:"answer"` // nil jika belum dijawab
+	Proctoring models.ProctoringSummary `json:"proctoring"`
+}
+
+// RecordProctoringEvents menyimpan kejadian pengawasan (pindah tab, kehilangan fokus,
+// paste) yang dikirim browser siswa selama ujian. Browser membuat session_id saat
+// ujian dimulai dan mengirim kejadian secara berkelompok.
+func RecordProctoringEvents(c *gin.Context) {
+	var req ProctoringBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+	if !proctoringSessionPattern.MatchString(req.SessionID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id harus 8-64 karakter huruf, angka, - atau _"})
+		return
+	}
+	if len(req.Events) == 0 || len(req.Events) > maxProctoringBatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah kejadian harus 1-" + strconv.Itoa(maxProctoringBatch)})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	student, err := repository.NewStudentRepository().WithContext(c.Request.Context()).FindByUserID(userID.(uint))
+	if err != nil {
+		requestLog(c).Error("Error finding student", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data siswa"})
+		return
+	}
+	if student == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profil siswa tidak ditemukan"})
+		return
+	}
+
+	now := time.Now()
+	events := make([]models.ProctoringEvent, len(req.Events))
+	for i, input := range req.Events {
+		if !slices.Contains(models.ProctoringEventTypes, input.Type) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Jenis kejadian tidak dikenal: " + string(input.Type)})
+			return
+		}
+		occurredAt := input.OccurredAt
+		if occurredAt.IsZero() {
+			occurredAt = now
+		}
+		if occurredAt.After(now.Add(proctoringClockSkew)) || occurredAt.Before(now.Add(-maxProctoringEventAge)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Waktu kejadian tidak valid"})
+			return
+		}
+		events[i] = models.ProctoringEvent{
+			StudentID:  student.ID,
+			SessionID:  req.SessionID,
+			Type:       input.Type,
+			QuestionID: input.QuestionID,
+			Length:     max(input.Length, 0),
+			OccurredAt: occurredAt,
+		}
+	}
+
+	if err := repository.NewProctoringRepository().WithContext(c.Request.Context()).CreateBatch(events); err != nil {
+		requestLog(c).Error("Error saving proctoring events", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan kejadian"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Kejadian berhasil disimpan", "data": gin.H{"accepted": len(events)}})
+}
+
+// GetProctoringSessions mengembalikan sesi ujian seorang siswa beserta skor kecurigaannya,
+// skor tertinggi lebih dulu
+func GetProctoringSessions(c *gin.Context) {
+	student, ok := proctoredStudent(c)
+	if !ok {
+		return
+	}
+
+	events, err := repository.NewProctoringRepository().WithContext(c.Request.Context()).FindByStudent(student.ID)
+	if err != nil {
+		requestLog(c).Error("Error finding proctoring events", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil kejadian pengawasan"})
+		return
+	}
+
+	// Kejadian berurutan per sesi
+	sessions := []proctoringSession{}
+	for start := 0; start < len(events); {
+		end := start
+		for end < len(events) && events[end].SessionID == events[start].SessionID {
+			end++
+		}
+		report := proctoring.Analyze(events[start:end])
+		sessions = append(sessions, proctoringSession{
+			SessionID: report.SessionID,
+			StartedAt: report.StartedAt,
+			EndedAt:   report.EndedAt,
+			Summary:   report.Summary,
+		})
+		start = end
+	}
+	slices.SortStableFunc(sessions, func(a, b proctoringSession) int {
+		if a.Summary.SuspicionScore != b.Summary.SuspicionScore {
+			return b.Summary.SuspicionScore - a.Summary.SuspicionScore
+		}
+		return b.StartedAt.Compare(a.StartedAt)
+	})
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"student": student, "sessions": sessions}})
+}
+
+// GetProctoringSession mengembalikan linimasa satu sesi ujian, skor kecurigaannya dan
+// jawaban siswa untuk soal yang dikerjakan dalam sesi itu
+func GetProctoringSession(c *gin.Context) {
+	student, ok := proctoredStudent(c)
+	if !ok {
+		return
+	}
+
+	events, err := repository.NewProctoringRepository().WithContext(c.Request.Context()).FindBySession(student.ID, c.Param("session"))
+	if err != nil {
+		requestLog(c).Error("Error finding proctoring events", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil kejadian pengawasan"})
+		return
+	}
+	if len(events) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sesi ujian tidak ditemukan"})
+		return
+	}
+
+	studentAnswers, err := repository.NewStudentAnswerRepository().WithContext(c.Request.Context()).FindByStudent(student.ID)
+	if err != nil {
+		requestLog(c).Error("Error finding student answers", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil jawaban siswa"})
+		return
+	}
+	byQuestion := map[uint]*models.StudentAnswer{}
+	for i := range studentAnswers {
+		byQuestion[studentAnswers[i].QuestionID] = &studentAnswers[i]
+	}
+
+	var questionIDs []uint
+	questionEvents := map[uint][]models.ProctoringEvent{}
+	for _, e := range events {
+		if e.QuestionID == nil {
+			continue
+		}
+		if _, ok := questionEvents[*e.QuestionID]; !ok {
+			questionIDs = append(questionIDs, *e.QuestionID)
+		}
+		questionEvents[*e.QuestionID] = append(questionEvents[*e.QuestionID], e)
+	}
+	answers := []sessionAnswer{}
+	for _, id := range questionIDs {
+		answers = append(answers, sessionAnswer{
+			QuestionID: id,
+			Answer:     byQuestion[id],
+			Proctoring: proctoring.Summarize(questionEvents[id]),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"student": student, "report": proctoring.Analyze(events), "answers": answers}})
+}
+
+// proctoredStudent mencari siswa dari parameter :id.
+// Jika gagal, response error sudah dikirim.
+func proctoredStudent(c *gin.Context) (*models.Student, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return nil, false
+	}
+
+	student, err := repository.NewStudentRepository().WithContext(c.Request.Context()).FindByID(uint(id))
+	if err != nil {
+		requestLog(c).Error("Error finding student", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data siswa"})
+		return nil, false
+	}
+	if student == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Siswa tidak ditemukan"})
+		return nil, false
+	}
+	return student, true
+}
+
+// attachProctoring melengkapi jawaban dengan ringkasan kejadian pengawasan saat
+// soalnya dikerjakan
+func attachProctoring(ctx context.Context, answers []models.StudentAnswerWithDetails) error {
+	var studentIDs []uint
+	for _, a := range answers {
+		if !slices.Contains(studentIDs, a.StudentID) {
+			studentIDs = append(studentIDs, a.StudentID)
+		}
+	}
+
+	events, err := repository.NewProctoringRepository().WithContext(ctx).FindByQuestions(studentIDs)
+	if err != nil {
+		return err
+	}
+
+	type key struct{ student, question uint }
+	grouped := map[key][]models.ProctoringEvent{}
+	for _, e := range events {
+		k := key{e.StudentID, *e.QuestionID}
+		grouped[k] = append(grouped[k], e)
+	}
+	for i := range answers {
+		if list, ok := grouped[key{answers[i].StudentID, answers[i].QuestionID}]; ok {
+			summary := proctoring.Summarize(list)
+			answers[i].Proctoring = &summary
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/questionio"
+	"lms-vue-go/backend/repository"
+)
+
+// maxExportIDs adalah jumlah maksimum ID soal pada parameter ids
+const maxExportIDs = 1000
+
+// ExportQuestions mengekspor soal ke file Moodle XML, QTI 2.1 (zip) atau JSON.
+// Parameter format=json|moodle|qti (default json). Dengan ids=1,2,3 hanya soal
+// tersebut yang diekspor sesuai urutan; tanpa ids seluruh soal ujian diekspor,
+// bisa dipersempit dengan tag.
+func ExportQuestions(c *gin.Context) {
+	format := questionio.FormatJSON
+	if name := c.Query("format"); name != "" {
+		var err error
+		format, err = questionio.ParseFormat(name)
+		if err != nil || format == questionio.FormatGIFT {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format ekspor tidak valid, gunakan json, moodle atau qti"})
+			return
+		}
+	}
+
+	ids, ok := parseExportIDs(c)
+	if !ok {
+		return
+	}
+
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+	var questions []models.Question
+	var err error
+	if len(ids) > 0 {
+		questions, err = questionRepo.FindByIDs(ids)
+	} else {
+		questions, err = questionRepo.FindAll()
+	}
+	if err != nil {
+		requestLog(c).Error("Error fetching questions for export", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data soal"})
+		return
+	}
+
+	if tag := strings.ToLower(strings.TrimSpace(c.Query("tag"))); tag != "" {
+		questions = questionsWithTag(questions, tag)
+	}
+
+	if len(questions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tidak ada soal untuk diekspor"})
+		return
+	}
+
+	data, err := questionio.Export(format, questions)
+	if err != nil {
+		requestLog(c).Error("Error exporting questions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengekspor soal"})
+		return
+	}
+
+	// Format selain json tidak dapat menyimpan semua data soal, misalnya aturan file jawaban
+	if issues := questionio.ExportIssues(format, questions); len(issues) > 0 {
+		ids := make([]string, len(issues))
+		for i, issue := range issues {
+			ids[i] = issue.Name
+		}
+		c.Header("Warning", fmt.Sprintf(`199 - "Aturan file jawaban tidak ikut diekspor untuk soal %s; gunakan format json"`, strings.Join(ids, ", ")))
+	}
+
+	filename := fmt.Sprintf("soal-%s%s", time.Now().Format("20060102-150405"), format.Extension())
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, format.ContentType(), data)
+}
+
+// parseExportIDs membaca parameter ids (dipisah koma). Jika gagal, response error sudah dikirim.
+func parseExportIDs(c *gin.Context) ([]uint, bool) {
+	raw := strings.TrimSpace(c.Query("ids"))
+	if raw == "" {
+		return nil, true
+	}
+
+	var ids []uint
+	seen := map[uint]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID soal tidak valid: " + part})
+			return nil, false
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			ids = append(ids, uint(id))
+		}
+	}
+
+	if len(ids) > maxExportIDs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Maksimal %d soal per ekspor", maxExportIDs)})
+		return nil, false
+	}
+	return ids, true
+}
+
+// questionsWithTag menyaring soal yang memiliki tag tertentu
+func questionsWithTag(questions []models.Question, tag string) []models.Question {
+	var filtered []models.Question
+	for _, q := range questions {
+		for _, t := range q.Tags {
+			if t == tag {
+				filtered = append(filtered, q)
+				break
+			}
+		}
+	}
+	return filtered
+}
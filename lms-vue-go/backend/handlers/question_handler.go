@@ -1,48 +1,169 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/config"
 	"lms-vue-go/backend/models"
 	"lms-vue-go/backend/repository"
+	"lms-vue-go/backend/search"
 )
 
-// questionRepo adalah repository untuk operasi question
-var questionRepo = repository.NewQuestionRepository()
-
-// GetAllQuestions mengembalikan semua soal (tanpa jawaban untuk non-admin)
+// GetAllQuestions mengembalikan daftar soal per halaman (tanpa jawaban untuk non-admin)
 func GetAllQuestions(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
 	// Cek apakah user adalah admin dari context yang diset oleh middleware
 	userRole, exists := c.Get("userRole")
 	isAdmin := exists && userRole == models.RoleAdmin
 
-	// Ambil semua soal dari database
-	questions, err := questionRepo.FindAll()
+	query, ok := parseListQuery(c, repository.QuestionListSpec)
+	if !ok {
+		return
+	}
+
+	questions, total, err := questionRepo.List(query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data soal"})
+		requestLog(c).Error("Error listing questions", "error", err)
+		respondListError(c, err, "Gagal mengambil data soal")
 		return
 	}
 
 	// Jika bukan admin, hapus jawaban dari response
 	if !isAdmin {
-		// Buat salinan questions tanpa jawaban
-		questionsWithoutAnswers := make([]models.Question, len(questions))
-		for i, q := range questions {
-			questionsWithoutAnswers[i] = q
-			questionsWithoutAnswers[i].HideAnswer()
+		for i := range questions {
+			questions[i].HideAnswer()
 		}
-		c.JSON(http.StatusOK, gin.H{"data": questionsWithoutAnswers})
+	}
+
+	respondList(c, questions, total, query)
+}
+
+// searchConfig menentukan apakah pencarian soal memakai indeks FULLTEXT MySQL
+var searchConfig = config.DefaultSearchConfig()
+
+// SearchQuestions mencari soal berdasarkan teks soal, opsi, dan tag.
+// Kata kunci yang cocok ditandai di field highlights.
+func SearchQuestions(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
+	userRole, exists := c.Get("userRole")
+	isAdmin := exists && userRole == models.RoleAdmin
+
+	query, ok := parseListQuery(c, repository.QuestionSearchSpec)
+	if !ok {
+		return
+	}
+	terms := search.Terms(c.Query("q"))
+	if len(terms) == 0 && len(query.Filters) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Masukkan kata kunci atau filter pencarian"})
 		return
 	}
 
-	// Jika admin, kirim semua data termasuk jawaban
-	c.JSON(http.StatusOK, gin.H{"data": questions})
+	results, total, err := questionRepo.Search(terms, query, searchConfig.FullText())
+	if err != nil {
+		requestLog(c).Error("Error searching questions", "error", err)
+		respondListError(c, err, "Gagal mencari soal")
+		return
+	}
+
+	for i := range results {
+		result := &results[i]
+		if !isAdmin {
+			result.HideAnswer()
+		}
+
+		highlights := &models.QuestionHighlights{}
+		highlights.Question, _ = search.Highlight(result.Question.Question, terms)
+		for _, option := range result.Options {
+			highlighted, _ := search.Highlight(option, terms)
+			highlights.Options = append(highlights.Options, highlighted)
+		}
+		for _, tag := range result.Tags {
+			highlighted, _ := search.Highlight(tag, terms)
+			highlights.Tags = append(highlights.Tags, highlighted)
+		}
+		result.Highlights = highlights
+	}
+
+	respondList(c, results, total, query)
+}
+
+// normalizeQuestionTags merapikan dan memvalidasi tag soal. Jika gagal, response error sudah dikirim.
+func normalizeQuestionTags(c *gin.Context, question *models.Question) bool {
+	question.Tags = models.NormalizeTags(question.Tags)
+	for _, tag := range question.Tags {
+		if len(tag) > models.MaxTagLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tag terlalu panjang: " + tag})
+			return false
+		}
+	}
+	return true
+}
+
+// maxAnswerFiles adalah batas jumlah file per jawaban yang dapat diatur guru
+const maxAnswerFiles = 10
+
+// normalizeFileRule merapikan aturan file jawaban dan mengisi nilai bawaan: semua tipe
+// lampiran, ukuran maksimal lampiran, dan satu file. Jika gagal, response error sudah dikirim.
+func normalizeFileRule(c *gin.Context, question *models.Question) bool {
+	rule := question.FileRule
+	if rule == nil {
+		return true
+	}
+	if question.Type != models.Essay {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Lampiran file hanya dapat diatur untuk soal esai"})
+		return false
+	}
+
+	policy := uploadPolicy(models.UploadAttachment)
+	types := make([]string, 0, len(rule.Types))
+	for _, t := range rule.Types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || slices.Contains(types, t) {
+			continue
+		}
+		if t != "image/*" && !policy.Allows(t) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Tipe file %s tidak didukung, gunakan %s atau image/*", t, strings.Join(policy.Types, ", ")),
+			})
+			return false
+		}
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		types = policy.Types
+	}
+	rule.Types = types
+
+	maxSizeMB := int(policy.MaxSize >> 20)
+	if rule.MaxSizeMB == 0 {
+		rule.MaxSizeMB = maxSizeMB
+	}
+	if rule.MaxSizeMB < 1 || rule.MaxSizeMB > maxSizeMB {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Ukuran file maksimal harus antara 1 dan %d MB", maxSizeMB)})
+		return false
+	}
+
+	if rule.MaxFiles == 0 {
+		rule.MaxFiles = 1
+	}
+	if rule.MaxFiles < 1 || rule.MaxFiles > maxAnswerFiles {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Jumlah file maksimal harus antara 1 dan %d", maxAnswerFiles)})
+		return false
+	}
+	return true
 }
 
 // GetQuestionByID mengembalikan soal berdasarkan ID
 func GetQuestionByID(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
@@ -84,6 +205,8 @@ func GetQuestionByID(c *gin.Context) {
 
 // CreateQuestion menambahkan soal baru
 func CreateQuestion(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
 	var question models.Question
 	if err := c.ShouldBindJSON(&question); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
@@ -96,18 +219,25 @@ func CreateQuestion(c *gin.Context) {
 		return
 	}
 
+	if !normalizeQuestionTags(c, &question) || !normalizeFileRule(c, &question) {
+		return
+	}
+
 	// Simpan soal ke database
-	err := questionRepo.Create(&question)
+	err := questionRepo.Create(&question, c.GetUint("userID"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menambahkan soal"})
 		return
 	}
+	RecordAudit(c, models.AuditQuestionCreate, models.AuditEntityQuestion, question.ID, nil, question)
 
 	c.JSON(http.StatusCreated, gin.H{"data": question})
 }
 
-// UpdateQuestion mengupdate soal
+// UpdateQuestion mengupdate soal. Setiap perubahan disimpan sebagai revisi baru.
 func UpdateQuestion(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
@@ -133,19 +263,26 @@ func UpdateQuestion(c *gin.Context) {
 		return
 	}
 
+	if !normalizeQuestionTags(c, &updatedQuestion) || !normalizeFileRule(c, &updatedQuestion) {
+		return
+	}
+
 	// Update data
 	updatedQuestion.ID = uint(id)
-	err = questionRepo.Update(&updatedQuestion)
+	err = questionRepo.Update(&updatedQuestion, c.GetUint("userID"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate soal"})
 		return
 	}
+	RecordAudit(c, models.AuditQuestionUpdate, models.AuditEntityQuestion, question.ID, question, updatedQuestion)
 
 	c.JSON(http.StatusOK, gin.H{"data": updatedQuestion})
 }
 
-// DeleteQuestion menghapus soal
+// DeleteQuestion memindahkan soal ke tempat sampah
 func DeleteQuestion(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
@@ -170,6 +307,9 @@ func DeleteQuestion(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus soal"})
 		return
 	}
+	RecordAudit(c, models.AuditQuestionDelete, models.AuditEntityQuestion, question.ID, question, nil)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Soal berhasil dihapus"})
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Soal dipindahkan ke tempat sampah dan dapat dipulihkan selama " + strconv.Itoa(trashRetentionDays()) + " hari",
+	})
 }
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/questionio"
+	"lms-vue-go/backend/repository"
+)
+
+// maxImportSize adalah ukuran maksimum file import soal (10 MB)
+const maxImportSize = 10 << 20
+
+// ImportQuestions mengimpor soal dari file Moodle XML, GIFT, QTI 2.1 atau JSON hasil ekspor.
+// File dikirim sebagai multipart field "file" atau langsung sebagai body request.
+// Format dideteksi otomatis kecuali ditentukan lewat ?format=moodle|gift|qti|json.
+// Dengan ?dry_run=true hasil konversi hanya ditampilkan tanpa disimpan.
+func ImportQuestions(c *gin.Context) {
+	filename, data, ok := readImportFile(c)
+	if !ok {
+		return
+	}
+
+	var format questionio.Format
+	var err error
+	if name := c.Query("format"); name != "" {
+		format, err = questionio.ParseFormat(name)
+	} else {
+		format, err = questionio.DetectFormat(filename, data)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format file tidak dikenali, gunakan parameter format=moodle|gift|qti|json"})
+		return
+	}
+
+	result, err := questionio.Import(format, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak dapat dibaca: " + err.Error()})
+		return
+	}
+
+	// Aturan file dari JSON dinormalisasi dan diperiksa seperti saat soal dibuat
+	for i := range result.Questions {
+		if !normalizeFileRule(c, &result.Questions[i]) {
+			return
+		}
+	}
+
+	response := gin.H{
+		"format":    format,
+		"questions": result.Questions,
+		"issues":    result.Issues,
+		"skipped":   result.Skipped,
+		"imported":  0,
+	}
+	if result.Questions == nil {
+		response["questions"] = []interface{}{}
+	}
+	if result.Issues == nil {
+		response["issues"] = []interface{}{}
+	}
+
+	if c.Query("dry_run") == "true" {
+		c.JSON(http.StatusOK, gin.H{"data": response, "message": "Pratinjau import, belum ada soal yang disimpan"})
+		return
+	}
+
+	if len(result.Questions) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Tidak ada soal yang dapat diimpor", "data": response})
+		return
+	}
+
+	// Semua soal disimpan dalam satu transaksi
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+	if err := questionRepo.CreateBatch(result.Questions, c.GetUint("userID")); err != nil {
+		requestLog(c).Error("Error importing questions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan soal, tidak ada soal yang diimpor"})
+		return
+	}
+
+	for _, question := range result.Questions {
+		RecordAudit(c, models.AuditQuestionCreate, models.AuditEntityQuestion, question.ID, nil, question)
+	}
+
+	response["imported"] = len(result.Questions)
+	c.JSON(http.StatusCreated, gin.H{"data": response, "message": "Soal berhasil diimpor"})
+}
+
+// readImportFile membaca file import dari multipart form atau body request.
+// Jika gagal, response error sudah dikirim.
+func readImportFile(c *gin.Context) (string, []byte, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var filename string
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			if isTooLarge(err) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Ukuran file maksimal 10 MB"})
+				return "", nil, false
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak ditemukan pada field 'file'"})
+			return "", nil, false
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak dapat dibuka"})
+			return "", nil, false
+		}
+		defer file.Close()
+		filename, reader = fileHeader.Filename, file
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		if isTooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Ukuran file maksimal 10 MB"})
+			return "", nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak dapat dibaca"})
+		return "", nil, false
+	}
+	if len(data) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File kosong"})
+		return "", nil, false
+	}
+
+	return filename, data, true
+}
+
+// isTooLarge memeriksa apakah error berasal dari batas ukuran body
+func isTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListQuestionVersions mengembalikan riwayat revisi soal, dari yang terbaru
+func ListQuestionVersions(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
+	question, ok := findQuestionParam(c, questionRepo)
+	if !ok {
+		return
+	}
+
+	versions, err := questionRepo.ListVersions(question.ID)
+	if err != nil {
+		requestLog(c).Error("Error listing question versions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil riwayat soal"})
+		return
+	}
+
+	if !isAdminRole(c) {
+		for i := range versions {
+			versions[i].HideAnswer()
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":            versions,
+		"current_version": question.Version,
+	})
+}
+
+// GetQuestionVersion mengembalikan satu revisi soal
+func GetQuestionVersion(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
+	question, ok := findQuestionParam(c, questionRepo)
+	if !ok {
+		return
+	}
+
+	version, ok := findVersionParam(c, questionRepo, question.ID, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	if !isAdminRole(c) {
+		version.HideAnswer()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": version})
+}
+
+// DiffQuestionVersions membandingkan dua revisi soal: ?from=1&to=3.
+// Tanpa parameter, "to" adalah revisi saat ini dan "from" revisi sebelumnya.
+func DiffQuestionVersions(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
+	question, ok := findQuestionParam(c, questionRepo)
+	if !ok {
+		return
+	}
+
+	toParam := c.DefaultQuery("to", strconv.Itoa(question.Version))
+	to, ok := findVersionParam(c, questionRepo, question.ID, toParam)
+	if !ok {
+		return
+	}
+
+	fromParam := c.DefaultQuery("from", strconv.Itoa(to.Version-1))
+	from, ok := findVersionParam(c, questionRepo, question.ID, fromParam)
+	if !ok {
+		return
+	}
+
+	changes := models.DiffQuestionVersions(from, to)
+	if !isAdminRole(c) {
+		// Jangan bocorkan kunci jawaban melalui diff
+		filtered := changes[:0]
+		for _, change := range changes {
+			if change.Field != "answer" {
+				filtered = append(filtered, change)
+			}
+		}
+		changes = filtered
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"question_id":  question.ID,
+			"from_version": from.Version,
+			"to_version":   to.Version,
+			"changes":      changes,
+		},
+	})
+}
+
+// RestoreQuestionVersion memulihkan isi revisi lama. Pemulihan disimpan sebagai revisi baru
+// sehingga riwayat tidak pernah berubah.
+func RestoreQuestionVersion(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
+	question, ok := findQuestionParam(c, questionRepo)
+	if !ok {
+		return
+	}
+
+	version, ok := findVersionParam(c, questionRepo, question.ID, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	if version.Version == question.Version {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Revisi ini adalah revisi saat ini"})
+		return
+	}
+
+	restored := version.ToQuestion()
+	if err := questionRepo.Update(&restored, c.GetUint("userID")); err != nil {
+		requestLog(c).Error("Error restoring question version", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulihkan revisi soal"})
+		return
+	}
+	RecordAudit(c, models.AuditQuestionUpdate, models.AuditEntityQuestion, question.ID, question, restored)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    restored,
+		"message": "Revisi " + strconv.Itoa(version.Version) + " dipulihkan sebagai revisi " + strconv.Itoa(restored.Version),
+	})
+}
+
+// findQuestionParam mengambil soal dari parameter :id. Jika gagal, response error sudah dikirim.
+func findQuestionParam(c *gin.Context, questionRepo *repository.QuestionRepository) (*models.Question, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return nil, false
+	}
+
+	question, err := questionRepo.FindByID(uint(id))
+	if err != nil {
+		requestLog(c).Error("Error finding question", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data soal"})
+		return nil, false
+	}
+
+	if question == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Soal tidak ditemukan"})
+		return nil, false
+	}
+
+	return question, true
+}
+
+// findVersionParam mengambil revisi soal dari nomor revisi. Jika gagal, response error sudah dikirim.
+func findVersionParam(c *gin.Context, questionRepo *repository.QuestionRepository, questionID uint, param string) (*models.QuestionVersion, bool) {
+	number, err := strconv.Atoi(param)
+	if err != nil || number <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nomor revisi tidak valid"})
+		return nil, false
+	}
+
+	version, err := questionRepo.FindVersion(questionID, number)
+	if err != nil {
+		requestLog(c).Error("Error finding question version", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil revisi soal"})
+		return nil, false
+	}
+
+	if version == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revisi " + param + " tidak ditemukan"})
+		return nil, false
+	}
+
+	return version, true
+}
+
+// isAdminRole memeriksa apakah pengguna yang sedang login adalah admin
+func isAdminRole(c *gin.Context) bool {
+	userRole, exists := c.Get("userRole")
+	return exists && userRole == models.RoleAdmin
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/logging"
+)
+
+// requestLog mengembalikan logger yang mencantumkan request_id dari request c,
+// sehingga error database dapat dicocokkan dengan request yang memicunya
+func requestLog(c *gin.Context) *slog.Logger {
+	return logging.FromContext(c.Request.Context())
+}
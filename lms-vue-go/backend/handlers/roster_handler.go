@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/logging"
+	"lms-vue-go/backend/mailer"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+	"lms-vue-go/backend/roster"
+	"lms-vue-go/backend/spreadsheet"
+)
+
+// Masa berlaku link undangan dan panjang password awal siswa hasil import
+const (
+	inviteTTL             = 7 * 24 * time.Hour
+	initialPasswordLength = 10
+)
+
+// Cara memberikan akses akun baru hasil import
+const (
+	credentialsInvite   = "invite"
+	credentialsPassword = "password"
+)
+
+// Aksi yang dilakukan untuk satu baris roster
+const (
+	rosterCreate    = "create"
+	rosterUpdate    = "update"
+	rosterUnchanged = "unchanged"
+	rosterError     = "error"
+)
+
+// RosterRowResult adalah hasil import satu baris roster
+type RosterRowResult struct {
+	Row        int               `json:"row"`
+	Action     string            `json:"action"` // create, update, unchanged atau error
+	Name       string            `json:"name,omitempty"`
+	Class      string            `json:"class,omitempty"`
+	Email      string            `json:"email,omitempty"`
+	Username   string            `json:"username,omitempty"`
+	UserID     uint              `json:"user_id,omitempty"`
+	StudentID  uint              `json:"student_id,omitempty"`
+	Password   string            `json:"password,omitempty"`    // Password awal, hanya untuk akun baru
+	InviteLink string            `json:"invite_link,omitempty"` // Link untuk membuat password, hanya untuk akun baru
+	EmailSent  *bool             `json:"email_sent,omitempty"`
+	Errors     []roster.RowError `json:"errors,omitempty"`
+	previous   *models.Student   // Data siswa sebelum diperbarui, untuk log audit
+}
+
+// rosterSummary menghitung jumlah baris per aksi
+type rosterSummary struct {
+	Total     int `json:"total"`
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Failed    int `json:"failed"`
+}
+
+// ImportStudents mengimpor roster siswa dari file CSV atau XLSX dengan kolom
+// name, class, email dan username (opsional). Akun dicocokkan berdasarkan email
+// atau username sehingga import yang sama dapat diulang tanpa membuat duplikat.
+// Parameter:
+//   - dry_run=true: hanya menampilkan rencana tanpa menyimpan
+//   - credentials=invite|password: akun baru mendapat link undangan (default) atau password awal
+//   - send_email=true: kirim link undangan atau password ke email siswa
+func ImportStudents(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+	sendEmail := c.Query("send_email") == "true"
+	credentials := c.DefaultQuery("credentials", credentialsInvite)
+	if credentials != credentialsInvite && credentials != credentialsPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter credentials harus invite atau password"})
+		return
+	}
+
+	filename, data, ok := readImportFile(c)
+	if !ok {
+		return
+	}
+
+	rows, err := spreadsheet.Read(filename, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak dapat dibaca, gunakan CSV atau XLSX: " + err.Error()})
+		return
+	}
+
+	entries, rowErrors, err := roster.Parse(rows)
+	if err != nil {
+		if errors.Is(err, roster.ErrMissingColumns) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kolom wajib tidak ditemukan (name, class, email): " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	importer := &rosterImport{
+		ctx:         c.Request.Context(),
+		userRepo:    repository.NewUserRepository().WithContext(c.Request.Context()),
+		studentRepo: repository.NewStudentRepository().WithContext(c.Request.Context()),
+		dryRun:      dryRun,
+		credentials: credentials,
+		sendEmail:   sendEmail,
+		claimed:     map[string]bool{},
+		matched:     map[uint]int{},
+	}
+
+	var results []RosterRowResult
+	for _, e := range rowErrors {
+		if n := len(results); n > 0 && results[n-1].Row == e.Row {
+			results[n-1].Errors = append(results[n-1].Errors, e)
+			continue
+		}
+		results = append(results, RosterRowResult{Row: e.Row, Action: rosterError, Errors: []roster.RowError{e}})
+	}
+
+	for _, entry := range entries {
+		result, err := importer.entry(entry)
+		if err != nil {
+			requestLog(c).Error("Error importing roster row", "row", entry.Row, "error", err)
+			result.Action = rosterError
+			result.Errors = []roster.RowError{{Row: entry.Row, Message: "database error, row was not saved"}}
+		}
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Row < results[j].Row })
+
+	if !dryRun {
+		for _, r := range results {
+			if (r.Action != rosterCreate && r.Action != rosterUpdate) || r.StudentID == 0 {
+				continue
+			}
+			after := models.Student{ID: r.StudentID, UserID: r.UserID, Name: r.Name, Class: r.Class, Email: r.Email}
+			if r.previous == nil {
+				RecordAudit(c, models.AuditStudentCreate, models.AuditEntityStudent, r.StudentID, nil, after)
+			} else {
+				RecordAudit(c, models.AuditStudentUpdate, models.AuditEntityStudent, r.StudentID, r.previous, after)
+			}
+		}
+	}
+
+	summary := rosterSummary{Total: len(results)}
+	for _, r := range results {
+		switch r.Action {
+		case rosterCreate:
+			summary.Created++
+		case rosterUpdate:
+			summary.Updated++
+		case rosterUnchanged:
+			summary.Unchanged++
+		default:
+			summary.Failed++
+		}
+	}
+
+	message := "Import roster selesai"
+	if dryRun {
+		message = "Pratinjau import roster, belum ada data yang disimpan"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"dry_run":     dryRun,
+			"credentials": credentials,
+			"summary":     summary,
+			"rows":        results,
+		},
+		"message": message,
+	})
+}
+
+// rosterImport menyimpan pengaturan dan status satu proses import roster
+type rosterImport struct {
+	ctx         context.Context
+	userRepo    *repository.UserRepository
+	studentRepo *repository.StudentRepository
+	dryRun      bool
+	credentials string
+	sendEmail   bool
+	claimed     map[string]bool // Username (huruf kecil) yang sudah dipakai baris sebelumnya
+	matched     map[uint]int    // ID akun yang sudah dicocokkan dengan baris sebelumnya
+}
+
+// entry mencocokkan satu baris dengan akun yang ada lalu membuat atau memperbaruinya.
+// Error yang dikembalikan adalah kesalahan database; kesalahan data dilaporkan di hasil baris.
+func (imp *rosterImport) entry(entry roster.Entry) (RosterRowResult, error) {
+	userRepo, studentRepo, claimed := imp.userRepo, imp.studentRepo, imp.claimed
+	result := RosterRowResult{Row: entry.Row, Name: entry.Name, Class: entry.Class, Email: entry.Email, Username: entry.Username}
+	fail := func(field, message string) (RosterRowResult, error) {
+		result.Action = rosterError
+		result.Errors = append(result.Errors, roster.RowError{Row: entry.Row, Field: field, Message: message})
+		return result, nil
+	}
+
+	byEmail, err := userRepo.FindByEmail(entry.Email)
+	if err != nil {
+		return result, err
+	}
+	var byUsername *models.User
+	if entry.Username != "" {
+		if byUsername, err = userRepo.FindByUsername(entry.Username); err != nil {
+			return result, err
+		}
+	}
+	if byEmail != nil && byUsername != nil && byEmail.ID != byUsername.ID {
+		return fail("username", "email and username belong to different accounts")
+	}
+
+	user := byEmail
+	if user == nil {
+		user = byUsername
+	}
+	if user != nil {
+		if row, ok := imp.matched[user.ID]; ok {
+			return fail("email", fmt.Sprintf("account was already matched by row %d", row))
+		}
+		imp.matched[user.ID] = entry.Row
+	}
+
+	// Akun baru
+	if user == nil {
+		if entry.Username != "" && claimed[strings.ToLower(entry.Username)] {
+			return fail("username", "username is already used by an earlier row")
+		}
+		if entry.Username == "" {
+			if entry.Username, err = uniqueUsername(userRepo, roster.UsernameFromEmail(entry.Email), claimed); err != nil {
+				return result, err
+			}
+		}
+		claimed[strings.ToLower(entry.Username)] = true
+		result.Username = entry.Username
+		result.Action = rosterCreate
+		if imp.dryRun {
+			return result, nil
+		}
+
+		newUser := models.User{Username: entry.Username, Email: entry.Email}
+		if imp.credentials == credentialsPassword {
+			if result.Password, err = roster.GeneratePassword(initialPasswordLength); err != nil {
+				return result, err
+			}
+			newUser.Password = result.Password
+		} else {
+			// Password acak yang tidak diketahui siapa pun; siswa membuat password lewat link undangan
+			buf := make([]byte, 24)
+			if _, err := rand.Read(buf); err != nil {
+				return result, err
+			}
+			newUser.Password = base64.RawURLEncoding.EncodeToString(buf)
+		}
+
+		student := models.Student{Name: entry.Name, Class: entry.Class}
+		if err := studentRepo.SaveWithUser(&newUser, &student); err != nil {
+			return result, err
+		}
+		result.UserID, result.StudentID = newUser.ID, student.ID
+
+		if imp.credentials == credentialsInvite {
+			rawToken, err := issueToken(imp.ctx, newUser.ID, models.TokenPasswordReset, inviteTTL)
+			if err != nil {
+				return result, err
+			}
+			result.InviteLink = mailConfig.AppURL + "/reset-password?token=" + url.QueryEscape(rawToken)
+		}
+		if imp.sendEmail {
+			sent := sendRosterEmail(imp.ctx, &newUser, entry.Name, result) == nil
+			result.EmailSent = &sent
+		}
+		return result, nil
+	}
+
+	// Akun yang sudah ada
+	if user.Role != models.RoleStudent {
+		return fail("email", fmt.Sprintf("account belongs to a %s, not a student", user.Role))
+	}
+	result.UserID = user.ID
+	if entry.Username == "" {
+		entry.Username = user.Username
+		result.Username = user.Username
+	}
+	if !strings.EqualFold(entry.Username, user.Username) && claimed[strings.ToLower(entry.Username)] {
+		return fail("username", "username is already used by an earlier row")
+	}
+
+	student, err := studentRepo.FindByUserID(user.ID)
+	if err != nil {
+		return result, err
+	}
+	if student != nil {
+		result.StudentID = student.ID
+		result.previous = student
+	}
+
+	changed := student == nil || student.Name != entry.Name || student.Class != entry.Class ||
+		user.Email != entry.Email || user.Username != entry.Username
+	if !changed {
+		result.Action = rosterUnchanged
+		return result, nil
+	}
+
+	claimed[strings.ToLower(entry.Username)] = true
+	result.Action = rosterUpdate
+	if imp.dryRun {
+		return result, nil
+	}
+
+	user.Email, user.Username = entry.Email, entry.Username
+	updated := models.Student{Name: entry.Name, Class: entry.Class}
+	if err := studentRepo.SaveWithUser(user, &updated); err != nil {
+		return result, err
+	}
+	result.StudentID = updated.ID
+	return result, nil
+}
+
+// uniqueUsername menambahkan angka di belakang username sampai tidak dipakai akun lain
+func uniqueUsername(userRepo *repository.UserRepository, base string, claimed map[string]bool) (string, error) {
+	for i := 1; i < 1000; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s%d", base, i)
+		}
+		if claimed[strings.ToLower(candidate)] {
+			continue
+		}
+		existing, err := userRepo.FindByUsername(candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free username for %q", base)
+}
+
+// sendRosterEmail mengirim data login akun baru ke email siswa
+func sendRosterEmail(ctx context.Context, user *models.User, name string, result RosterRowResult) error {
+	var access string
+	if result.Password != "" {
+		access = fmt.Sprintf("Username: %s\nPassword: %s\n\nSilakan ganti password setelah login pertama.", user.Username, result.Password)
+	} else {
+		access = fmt.Sprintf("Username: %s\n\nBuat password Anda melalui link berikut:\n%s\n\nLink berlaku selama %d hari.",
+			user.Username, result.InviteLink, int(inviteTTL.Hours()/24))
+	}
+
+	err := appMailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Akun LMS Anda",
+		Body:    fmt.Sprintf("Halo %s,\n\nAkun LMS Anda telah dibuat.\n\n%s", name, access),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error sending roster email", "user_id", user.ID, "error", err)
+	}
+	return err
+}
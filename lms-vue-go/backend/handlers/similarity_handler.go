@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/analytics"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+	"lms-vue-go/backend/similarity"
+)
+
+// answerRef menunjuk jawaban siswa dalam pasangan jawaban yang mirip
+type answerRef struct {
+	AnswerID     uint   `json:"answer_id"`
+	StudentID    uint   `json:"student_id"`
+	StudentName  string `json:"student_name"`
+	StudentClass string `json:"student_class"`
+}
+
+// similarPair adalah dua jawaban esai yang memuat bagian teks yang sama
+type similarPair struct {
+	similarity.Pair
+	AnswerA answerRef `json:"answer_a"`
+	AnswerB answerRef `json:"answer_b"`
+}
+
+// questionSimilarity adalah hasil pemeriksaan kemiripan jawaban satu soal
+type questionSimilarity struct {
+	QuestionID uint          `json:"question_id"`
+	Question   string        `json:"question"`
+	Answers    int           `json:"answers"`
+	Pairs      []similarPair `json:"pairs"`
+}
+
+// similarityCache menyimpan hasil per soal dan ambang batas selama jawaban tidak berubah
+var similarityCache = analytics.NewCache[[]questionSimilarity](analyticsConfig.CacheTTL)
+
+// GetAnswerSimilarity membandingkan jawaban esai antar siswa untuk soal yang sama dan
+// menandai pasangan yang kemiripannya mencapai ambang batas, lengkap dengan bagian
+// teks yang sama. Parameter question_id membatasi satu soal (bawaan: semua soal esai),
+// threshold mengganti ambang batas (0-1).
+func GetAnswerSimilarity(c *gin.Context) {
+	var questionID uint
+	if raw := c.Query("question_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter question_id tidak valid"})
+			return
+		}
+		questionID = uint(id)
+	}
+
+	threshold := analyticsConfig.SimilarityThreshold
+	if raw := c.Query("threshold"); raw != "" {
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || value <= 0 || value > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter threshold harus lebih dari 0 dan paling besar 1"})
+			return
+		}
+		threshold = value
+	}
+
+	key := fmt.Sprintf("%d|%g", questionID, threshold)
+	results, cached, err := similarityCache.Get(key, analyticsDataVersion(c.Request.Context()), func() ([]questionSimilarity, error) {
+		answers, err := repository.NewStudentAnswerRepository().WithContext(c.Request.Context()).FindEssayAnswers(questionID)
+		if err != nil {
+			return nil, err
+		}
+		return compareAnswers(answers, threshold), nil
+	})
+	if err != nil {
+		requestLog(c).Error("Error comparing essay answers", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa kemiripan jawaban"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"threshold": threshold, "questions": results}, "cached": cached})
+}
+
+// compareAnswers membandingkan jawaban per soal; answers harus berurutan per soal.
+// Hanya soal yang memiliki pasangan mirip yang dikembalikan.
+func compareAnswers(answers []models.StudentAnswerWithDetails, threshold float64) []questionSimilarity {
+	results := []questionSimilarity{}
+	for start := 0; start < len(answers); {
+		end := start
+		for end < len(answers) && answers[end].QuestionID == answers[start].QuestionID {
+			end++
+		}
+		group := answers[start:end]
+		start = end
+
+		refs := map[uint]answerRef{}
+		docs := make([]similarity.Document, len(group))
+		for i, a := range group {
+			docs[i] = similarity.Document{ID: a.ID, Text: a.Answer}
+			refs[a.ID] = answerRef{AnswerID: a.ID, StudentID: a.StudentID, StudentName: a.StudentName, StudentClass: a.StudentClass}
+		}
+
+		pairs := similarity.Compare(docs, threshold)
+		if len(pairs) == 0 {
+			continue
+		}
+		result := questionSimilarity{QuestionID: group[0].QuestionID, Question: group[0].QuestionText, Answers: len(group)}
+		for _, pair := range pairs {
+			result.Pairs = append(result.Pairs, similarPair{Pair: pair, AnswerA: refs[pair.A], AnswerB: refs[pair.B]})
+		}
+		results = append(results, result)
+	}
+	return results
+}
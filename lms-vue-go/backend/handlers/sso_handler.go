@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"lms-vue-go/backend/auth"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+	"lms-vue-go/backend/sso"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Nama dan masa berlaku cookie yang menyimpan state login OIDC
+const (
+	oidcStateCookie   = "lms_oidc_state"
+	oidcStateDuration = 10 * time.Minute
+)
+
+// oidcConfig adalah konfigurasi SSO OpenID Connect
+var oidcConfig = config.DefaultOIDCConfig()
+
+// oidcStateSecret berbeda dari jwtSecret sehingga cookie state tidak dapat dipakai sebagai token login
+var oidcStateSecret = append(append([]byte{}, jwtSecret...), []byte(":oidc-state")...)
+
+// oidcProvider di-cache setelah discovery berhasil
+var (
+	oidcProviderMu sync.Mutex
+	oidcProvider   *sso.Provider
+)
+
+// OIDCStateClaims menyimpan state, nonce, dan PKCE verifier di cookie bertanda tangan
+type OIDCStateClaims struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	jwt.RegisteredClaims
+}
+
+// SetOIDCProvider mengganti provider OIDC (misalnya mock IdP saat pengujian)
+func SetOIDCProvider(provider *sso.Provider) {
+	oidcProviderMu.Lock()
+	defer oidcProviderMu.Unlock()
+
+	oidcProvider = provider
+	oidcConfig = provider.Config
+}
+
+// getOIDCProvider mengembalikan provider OIDC, menjalankan discovery saat pertama kali dipakai
+func getOIDCProvider(ctx context.Context) (*sso.Provider, error) {
+	oidcProviderMu.Lock()
+	defer oidcProviderMu.Unlock()
+
+	if oidcProvider != nil {
+		return oidcProvider, nil
+	}
+	if !oidcConfig.Enabled() {
+		return nil, errors.New("oidc is not configured")
+	}
+
+	provider, err := sso.NewProvider(ctx, oidcConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+	oidcProvider = provider
+	return provider, nil
+}
+
+// OIDCLogin mengarahkan pengguna ke identity provider sekolah
+func OIDCLogin(c *gin.Context) {
+	provider, err := getOIDCProvider(c.Request.Context())
+	if err != nil {
+		requestLog(c).Error("Error loading OIDC provider", "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Login SSO tidak tersedia"})
+		return
+	}
+
+	claims := OIDCStateClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(oidcStateDuration)),
+		},
+	}
+	for _, value := range []*string{&claims.State, &claims.Nonce, &claims.CodeVerifier} {
+		if *value, err = sso.RandomString(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulai login SSO"})
+			return
+		}
+	}
+
+	stateCookie, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(oidcStateSecret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulai login SSO"})
+		return
+	}
+
+	// SameSite=Lax agar cookie tetap terkirim saat IdP mengarahkan kembali ke callback
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, stateCookie, int(oidcStateDuration.Seconds()), "/api/auth/oidc", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, provider.AuthCodeURL(claims.State, claims.Nonce, claims.CodeVerifier))
+}
+
+// OIDCCallback menyelesaikan login SSO lalu mengarahkan kembali ke frontend dengan token di fragment URL
+func OIDCCallback(c *gin.Context) {
+	provider, err := getOIDCProvider(c.Request.Context())
+	if err != nil {
+		requestLog(c).Error("Error loading OIDC provider", "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Login SSO tidak tersedia"})
+		return
+	}
+
+	// Cookie state hanya boleh dipakai sekali
+	c.SetCookie(oidcStateCookie, "", -1, "/api/auth/oidc", "", c.Request.TLS != nil, true)
+
+	if idpError := c.Query("error"); idpError != "" {
+		redirectSSOError(c, "Login dibatalkan oleh identity provider: "+idpError)
+		return
+	}
+
+	state, err := parseOIDCState(c)
+	if err != nil || c.Query("state") == "" || c.Query("state") != state.State {
+		redirectSSOError(c, "Sesi login SSO tidak valid atau sudah kedaluwarsa")
+		return
+	}
+
+	claims, err := provider.Exchange(c.Request.Context(), c.Query("code"), state.CodeVerifier, state.Nonce)
+	if err != nil {
+		requestLog(c).Error("Error exchanging OIDC code", "error", err)
+		redirectSSOError(c, "Gagal memverifikasi login SSO")
+		return
+	}
+
+	user, err := findOrProvisionSSOUser(c.Request.Context(), provider.Config.Issuer, sso.MapClaims(claims, provider.Config))
+	if err != nil {
+		requestLog(c).Error("Error provisioning SSO user", "error", err)
+		redirectSSOError(c, "Gagal membuat akun dari data SSO")
+		return
+	}
+
+	if !user.IsActive {
+		redirectSSOError(c, "Akun Anda telah dinonaktifkan")
+		return
+	}
+
+	// SSO tidak menggantikan 2FA: kirim challenge langkah kedua alih-alih token login,
+	// lalu frontend melanjutkan ke /api/auth/2fa/verify atau /api/auth/2fa/enroll/setup
+	challenge, err := newTwoFactorChallenge(c.Request.Context(), user)
+	if err != nil {
+		requestLog(c).Error("Error starting 2FA challenge", "error", err)
+		redirectSSOError(c, "Gagal membuat challenge 2FA")
+		return
+	}
+	if challenge != nil {
+		fragment := url.Values{}
+		fragment.Set("challenge_token", challenge.ChallengeToken)
+		fragment.Set("expires_in", strconv.Itoa(challenge.ExpiresIn))
+		if challenge.TwoFactorRequired {
+			fragment.Set("two_factor_required", "true")
+		}
+		if challenge.EnrollmentRequired {
+			fragment.Set("enrollment_required", "true")
+		}
+		c.Redirect(http.StatusFound, mailConfig.AppURL+"/sso/callback#"+fragment.Encode())
+		return
+	}
+
+	token, err := generateJWT(user)
+	if err != nil {
+		requestLog(c).Error("Error generating JWT", "error", err)
+		redirectSSOError(c, "Gagal membuat token")
+		return
+	}
+
+	// Token dikirim lewat fragment agar tidak tercatat di log server maupun header Referer
+	c.Redirect(http.StatusFound, mailConfig.AppURL+"/sso/callback#token="+url.QueryEscape(token))
+}
+
+// findOrProvisionSSOUser mencari pengguna yang terhubung dengan subject IdP, menghubungkan
+// berdasarkan email yang sudah diverifikasi IdP, atau membuat pengguna baru seperti Register
+func findOrProvisionSSOUser(ctx context.Context, issuer string, profile sso.Profile) (*models.User, error) {
+	userRepo := repository.NewUserRepository().WithContext(ctx)
+	identityRepo := repository.NewIdentityRepository().WithContext(ctx)
+
+	identity, err := identityRepo.FindByIssuerAndSubject(issuer, profile.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := userRepo.FindByID(identity.UserID)
+		if err != nil || user != nil {
+			return user, err
+		}
+		return nil, errors.New("linked user no longer exists")
+	}
+
+	if profile.Email == "" {
+		return nil, errors.New("identity provider did not return an email claim")
+	}
+
+	// Akun lokal dengan email yang sama hanya dihubungkan jika IdP menjamin email tersebut
+	user, err := userRepo.FindByEmail(profile.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil && !profile.EmailVerified {
+		return nil, fmt.Errorf("email %s already belongs to a local account and is not verified by the IdP", profile.Email)
+	}
+
+	if user == nil {
+		user, err = auth.ProvisionUser(ctx, auth.ExternalProfile{
+			Username:      profile.Username,
+			Email:         profile.Email,
+			EmailVerified: profile.EmailVerified,
+			Name:          profile.Name,
+			Class:         profile.Class,
+			Role:          profile.Role,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	link := models.UserIdentity{UserID: user.ID, Issuer: issuer, Subject: profile.Subject}
+	if err := identityRepo.Create(&link); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// parseOIDCState membaca dan memverifikasi cookie state
+func parseOIDCState(c *gin.Context) (*OIDCStateClaims, error) {
+	raw, err := c.Cookie(oidcStateCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &OIDCStateClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		return oidcStateSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// redirectSSOError mengarahkan kembali ke frontend dengan pesan error
+func redirectSSOError(c *gin.Context, message string) {
+	c.Redirect(http.StatusFound, mailConfig.AppURL+"/sso/callback#error="+url.QueryEscape(message))
+}
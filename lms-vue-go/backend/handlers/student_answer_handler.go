@@ -3,6 +3,8 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"lms-vue-go/backend/models"
 	"lms-vue-go/backend/repository"
@@ -10,6 +12,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// maxFeedbackLength adalah panjang maksimal catatan guru pada satu jawaban
+const maxFeedbackLength = 2000
+
 // GetStudentAnswers mengembalikan semua jawaban siswa yang sedang login
 func GetStudentAnswers(c *gin.Context) {
 	// Set CORS headers
@@ -24,8 +29,8 @@ func GetStudentAnswers(c *gin.Context) {
 	}
 
 	// Inisialisasi repository
-	studentRepo := repository.NewStudentRepository()
-	studentAnswerRepo := repository.NewStudentAnswerRepository()
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
+	studentAnswerRepo := repository.NewStudentAnswerRepository().WithContext(c.Request.Context())
 
 	// Ambil user ID dari context
 	userID, exists := c.Get("userID")
@@ -53,6 +58,20 @@ func GetStudentAnswers(c *gin.Context) {
 		return
 	}
 
+	answerIDs := make([]uint, len(answers))
+	for i := range answers {
+		answerIDs[i] = answers[i].ID
+	}
+	files, err := answerFiles(c.Request.Context(), answerIDs)
+	if err != nil {
+		requestLog(c).Error("Error finding answer files", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil file jawaban"})
+		return
+	}
+	for i := range answers {
+		answers[i].Files = files[answers[i].ID]
+	}
+
 	c.JSON(http.StatusOK, gin.H{"data": answers})
 }
 
@@ -70,9 +89,9 @@ func GetStudentAnswerByQuestion(c *gin.Context) {
 	}
 
 	// Inisialisasi repository
-	studentRepo := repository.NewStudentRepository()
-	studentAnswerRepo := repository.NewStudentAnswerRepository()
-	questionRepo := repository.NewQuestionRepository()
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
+	studentAnswerRepo := repository.NewStudentAnswerRepository().WithContext(c.Request.Context())
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
 
 	// Ambil user ID dari context
 	userID, exists := c.Get("userID")
@@ -124,6 +143,14 @@ func GetStudentAnswerByQuestion(c *gin.Context) {
 		return
 	}
 
+	files, err := answerFiles(c.Request.Context(), []uint{answer.ID})
+	if err != nil {
+		requestLog(c).Error("Error finding answer files", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil file jawaban"})
+		return
+	}
+	answer.Files = files[answer.ID]
+
 	c.JSON(http.StatusOK, gin.H{"data": answer})
 }
 
@@ -141,9 +168,9 @@ func SubmitStudentAnswer(c *gin.Context) {
 	}
 
 	// Inisialisasi repository
-	studentRepo := repository.NewStudentRepository()
-	studentAnswerRepo := repository.NewStudentAnswerRepository()
-	questionRepo := repository.NewQuestionRepository()
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
+	studentAnswerRepo := repository.NewStudentAnswerRepository().WithContext(c.Request.Context())
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
 
 	// Ambil user ID dari context
 	userID, exists := c.Get("userID")
@@ -154,8 +181,9 @@ func SubmitStudentAnswer(c *gin.Context) {
 
 	// Struktur untuk binding request
 	type SubmitAnswerRequest struct {
-		QuestionID uint   `json:"question_id" binding:"required"`
-		Answer     string `json:"answer" binding:"required"`
+		QuestionID uint    `json:"question_id" binding:"required"`
+		Answer     string  `json:"answer"`
+		FileIDs    *[]uint `json:"file_ids"` // nil berarti file jawaban sebelumnya tidak diubah
 	}
 
 	var req SubmitAnswerRequest
@@ -195,6 +223,37 @@ func SubmitStudentAnswer(c *gin.Context) {
 		return
 	}
 
+	// File lama tetap dipakai jika file_ids tidak dikirim
+	var answerID uint
+	var oldFiles []models.Upload
+	if existingAnswer != nil {
+		answerID = existingAnswer.ID
+		existing, err := answerFiles(c.Request.Context(), []uint{answerID})
+		if err != nil {
+			requestLog(c).Error("Error finding answer files", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa jawaban sebelumnya"})
+			return
+		}
+		oldFiles = existing[answerID]
+	}
+	files := oldFiles
+	if req.FileIDs != nil {
+		var ok bool
+		if files, ok = checkAnswerFiles(c, question, userID.(uint), answerID, *req.FileIDs); !ok {
+			return
+		}
+	}
+
+	// Jawaban teks boleh kosong hanya untuk esai yang menyertakan file
+	if strings.TrimSpace(req.Answer) == "" && (question.Type != models.Essay || len(files) == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jawaban tidak boleh kosong"})
+		return
+	}
+	if question.FileRule != nil && question.FileRule.Required && len(files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jawaban harus menyertakan file"})
+		return
+	}
+
 	// Hitung skor otomatis untuk soal pilihan ganda
 	var score *int
 	if question.Type == models.MultipleChoice {
@@ -213,9 +272,8 @@ func SubmitStudentAnswer(c *gin.Context) {
 	if existingAnswer != nil {
 		existingAnswer.Answer = req.Answer
 		existingAnswer.Score = score
-		err = studentAnswerRepo.Update(existingAnswer)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate jawaban"})
+		existingAnswer.QuestionVersion = question.Version
+		if !saveAnswer(c, existingAnswer, req.FileIDs, files, oldFiles) {
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"data": existingAnswer, "message": "Jawaban berhasil diupdate"})
@@ -224,15 +282,14 @@ func SubmitStudentAnswer(c *gin.Context) {
 
 	// Jika belum ada jawaban, buat baru
 	newAnswer := models.StudentAnswer{
-		StudentID:  student.ID,
-		QuestionID: req.QuestionID,
-		Answer:     req.Answer,
-		Score:      score,
+		StudentID:       student.ID,
+		QuestionID:      req.QuestionID,
+		Answer:          req.Answer,
+		Score:           score,
+		QuestionVersion: question.Version,
 	}
 
-	err = studentAnswerRepo.Create(&newAnswer)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan jawaban"})
+	if !saveAnswer(c, &newAnswer, req.FileIDs, files, nil) {
 		return
 	}
 
@@ -242,7 +299,7 @@ func SubmitStudentAnswer(c *gin.Context) {
 // GradeStudentAnswer memberikan nilai untuk jawaban siswa (hanya untuk guru dan admin)
 func GradeStudentAnswer(c *gin.Context) {
 	// Inisialisasi repository
-	studentAnswerRepo := repository.NewStudentAnswerRepository()
+	studentAnswerRepo := repository.NewStudentAnswerRepository().WithContext(c.Request.Context())
 
 	// Ambil role dari context
 	userRole, exists := c.Get("userRole")
@@ -260,7 +317,8 @@ func GradeStudentAnswer(c *gin.Context) {
 
 	// Struktur untuk binding request
 	type GradeRequest struct {
-		Score int `json:"score" binding:"required"`
+		Score    int     `json:"score" binding:"required"`
+		Feedback *string `json:"feedback"` // Catatan guru untuk rapor; tidak dikirim berarti tidak diubah
 	}
 
 	var req GradeRequest
@@ -268,6 +326,10 @@ func GradeStudentAnswer(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
 		return
 	}
+	if req.Feedback != nil && utf8.RuneCountInString(*req.Feedback) > maxFeedbackLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Catatan guru maksimal " + strconv.Itoa(maxFeedbackLength) + " karakter"})
+		return
+	}
 
 	// Cari jawaban berdasarkan ID
 	answer, err := studentAnswerRepo.FindByID(uint(answerID))
@@ -282,9 +344,24 @@ func GradeStudentAnswer(c *gin.Context) {
 	}
 
 	// Update skor
+	// Nilai dan catatan auditnya disimpan dalam satu transaksi
+	feedback := answer.Feedback
+	if req.Feedback != nil {
+		feedback = strings.TrimSpace(*req.Feedback)
+	}
+	entry, err := newAuditEntry(c, models.AuditGradeUpdate, models.AuditEntityAnswer, answer.ID,
+		gin.H{"score": answer.Score, "feedback": answer.Feedback},
+		gin.H{"score": req.Score, "feedback": feedback})
+	if err != nil {
+		requestLog(c).Error("Error building audit entry", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate nilai"})
+		return
+	}
 	answer.Score = &req.Score
-	err = studentAnswerRepo.Update(answer)
+	answer.Feedback = feedback
+	err = studentAnswerRepo.Grade(answer, entry)
 	if err != nil {
+		requestLog(c).Error("Error grading answer", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate nilai"})
 		return
 	}
@@ -292,10 +369,10 @@ func GradeStudentAnswer(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": answer, "message": "Nilai berhasil diupdate"})
 }
 
-// GetAllStudentAnswers mengembalikan semua jawaban siswa (hanya untuk admin dan guru)
+// GetAllStudentAnswers mengembalikan daftar jawaban siswa per halaman (hanya untuk admin dan guru)
 func GetAllStudentAnswers(c *gin.Context) {
 	// Inisialisasi repository
-	studentAnswerRepo := repository.NewStudentAnswerRepository()
+	studentAnswerRepo := repository.NewStudentAnswerRepository().WithContext(c.Request.Context())
 
 	// Ambil role dari context
 	userRole, exists := c.Get("userRole")
@@ -304,12 +381,39 @@ func GetAllStudentAnswers(c *gin.Context) {
 		return
 	}
 
-	// Ambil semua jawaban
-	answers, err := studentAnswerRepo.FindAll()
+	query, ok := parseListQuery(c, repository.AnswerListSpec)
+	if !ok {
+		return
+	}
+
+	answers, total, err := studentAnswerRepo.List(query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data jawaban"})
+		requestLog(c).Error("Error listing student answers", "error", err)
+		respondListError(c, err, "Gagal mengambil data jawaban")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": answers})
+	// File jawaban esai disertakan agar guru dapat mengunduhnya saat menilai
+	answerIDs := make([]uint, len(answers))
+	for i := range answers {
+		answerIDs[i] = answers[i].ID
+	}
+	files, err := answerFiles(c.Request.Context(), answerIDs)
+	if err != nil {
+		requestLog(c).Error("Error finding answer files", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil file jawaban"})
+		return
+	}
+	for i := range answers {
+		answers[i].Files = files[answers[i].ID]
+	}
+
+	// Ringkasan pengawasan ujian membantu guru menilai kewajaran jawaban
+	if err := attachProctoring(c.Request.Context(), answers); err != nil {
+		requestLog(c).Error("Error finding proctoring events", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil kejadian pengawasan"})
+		return
+	}
+
+	respondList(c, answers, total, query)
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -13,26 +14,30 @@ import (
 // studentRepo adalah repository untuk operasi student
 // Akan diinisialisasi di setiap handler untuk memastikan koneksi DB sudah ada
 
-// GetAllStudents mengembalikan daftar semua siswa
+// GetAllStudents mengembalikan daftar siswa per halaman
 func GetAllStudents(c *gin.Context) {
 	// Inisialisasi repository
-	studentRepo := repository.NewStudentRepository()
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
 
-	students, err := studentRepo.FindAll()
+	query, ok := parseListQuery(c, repository.StudentListSpec)
+	if !ok {
+		return
+	}
+
+	students, total, err := studentRepo.List(query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data siswa"})
+		requestLog(c).Error("Error listing students", "error", err)
+		respondListError(c, err, "Gagal mengambil data siswa")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data": students,
-	})
+	respondList(c, students, total, query)
 }
 
 // GetStudentByID mengembalikan data siswa berdasarkan ID
 func GetStudentByID(c *gin.Context) {
 	// Inisialisasi repository
-	studentRepo := repository.NewStudentRepository()
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
@@ -83,7 +88,7 @@ func GetStudentByID(c *gin.Context) {
 // CreateStudent menambahkan data siswa baru
 func CreateStudent(c *gin.Context) {
 	// Inisialisasi repository
-	studentRepo := repository.NewStudentRepository()
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
 	var student models.Student
 	if err := c.ShouldBindJSON(&student); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
@@ -99,10 +104,15 @@ func CreateStudent(c *gin.Context) {
 
 	// Simpan student ke database
 	err := studentRepo.Create(&student, userID.(uint))
+	if errors.Is(err, repository.ErrStudentInTrash) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Data siswa berada di tempat sampah, pulihkan melalui menu sampah"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menambahkan data siswa"})
 		return
 	}
+	RecordAudit(c, models.AuditStudentCreate, models.AuditEntityStudent, student.ID, nil, student)
 
 	c.JSON(http.StatusCreated, gin.H{"data": student})
 }
@@ -110,7 +120,7 @@ func CreateStudent(c *gin.Context) {
 // UpdateStudent mengupdate data siswa
 func UpdateStudent(c *gin.Context) {
 	// Inisialisasi repository
-	studentRepo := repository.NewStudentRepository()
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
@@ -143,14 +153,15 @@ func UpdateStudent(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate data siswa"})
 		return
 	}
+	RecordAudit(c, models.AuditStudentUpdate, models.AuditEntityStudent, student.ID, student, updatedStudent)
 
 	c.JSON(http.StatusOK, gin.H{"data": updatedStudent})
 }
 
-// DeleteStudent menghapus data siswa
+// DeleteStudent memindahkan data siswa ke tempat sampah
 func DeleteStudent(c *gin.Context) {
 	// Inisialisasi repository
-	studentRepo := repository.NewStudentRepository()
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
@@ -175,14 +186,17 @@ func DeleteStudent(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus data siswa"})
 		return
 	}
+	RecordAudit(c, models.AuditStudentDelete, models.AuditEntityStudent, student.ID, student, nil)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Siswa berhasil dihapus"})
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Siswa dipindahkan ke tempat sampah dan dapat dipulihkan selama " + strconv.Itoa(trashRetentionDays()) + " hari",
+	})
 }
 
 // GetCurrentStudentProfile mengembalikan profil siswa untuk user yang sedang login
 func GetCurrentStudentProfile(c *gin.Context) {
 	// Inisialisasi repository
-	studentRepo := repository.NewStudentRepository()
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
 
 	// Ambil user ID dari context
 	userID, exists := c.Get("userID")
@@ -208,7 +222,7 @@ func GetCurrentStudentProfile(c *gin.Context) {
 	if student == nil {
 		// Jika tidak ada profil siswa, buat profil default
 		// Ambil data user untuk mendapatkan email
-		userRepo := repository.NewUserRepository()
+		userRepo := repository.NewUserRepository().WithContext(c.Request.Context())
 		user, err := userRepo.FindByID(userID.(uint))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pengguna"})
@@ -229,6 +243,10 @@ func GetCurrentStudentProfile(c *gin.Context) {
 
 		// Simpan student ke database
 		err = studentRepo.Create(&newStudent, userID.(uint))
+		if errors.Is(err, repository.ErrStudentInTrash) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Profil siswa Anda telah dihapus, hubungi admin untuk memulihkannya"})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat profil siswa"})
 			return
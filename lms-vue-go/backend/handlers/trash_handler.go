@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trashConfig menentukan berapa lama data di tempat sampah dapat dipulihkan
+var trashConfig = config.DefaultTrashConfig()
+
+// ListTrashedQuestions mengembalikan soal di tempat sampah (hanya admin)
+func ListTrashedQuestions(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
+	query, ok := parseListQuery(c, repository.QuestionListSpec)
+	if !ok {
+		return
+	}
+
+	questions, total, err := questionRepo.ListDeleted(query)
+	if err != nil {
+		requestLog(c).Error("Error listing trashed questions", "error", err)
+		respondListError(c, err, "Gagal mengambil data soal yang dihapus")
+		return
+	}
+
+	respondList(c, questions, total, query)
+}
+
+// ListTrashedStudents mengembalikan siswa di tempat sampah (hanya admin)
+func ListTrashedStudents(c *gin.Context) {
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
+
+	query, ok := parseListQuery(c, repository.StudentListSpec)
+	if !ok {
+		return
+	}
+
+	students, total, err := studentRepo.ListDeleted(query)
+	if err != nil {
+		requestLog(c).Error("Error listing trashed students", "error", err)
+		respondListError(c, err, "Gagal mengambil data siswa yang dihapus")
+		return
+	}
+
+	respondList(c, students, total, query)
+}
+
+// RestoreQuestion mengembalikan soal dari tempat sampah
+func RestoreQuestion(c *gin.Context) {
+	questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	restored, err := questionRepo.Restore(uint(id))
+	if err != nil {
+		requestLog(c).Error("Error restoring question", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulihkan soal"})
+		return
+	}
+
+	if !restored {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Soal tidak ada di tempat sampah"})
+		return
+	}
+	RecordAudit(c, models.AuditQuestionRestore, models.AuditEntityQuestion, uint(id), gin.H{"deleted": true}, gin.H{"deleted": false})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Soal berhasil dipulihkan"})
+}
+
+// RestoreStudent mengembalikan siswa dari tempat sampah
+func RestoreStudent(c *gin.Context) {
+	studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	restored, err := studentRepo.Restore(uint(id))
+	if err != nil {
+		requestLog(c).Error("Error restoring student", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulihkan data siswa"})
+		return
+	}
+
+	if !restored {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Siswa tidak ada di tempat sampah"})
+		return
+	}
+	RecordAudit(c, models.AuditStudentRestore, models.AuditEntityStudent, uint(id), gin.H{"deleted": true}, gin.H{"deleted": false})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Siswa berhasil dipulihkan"})
+}
+
+// trashRetentionDays mengembalikan masa simpan tempat sampah dalam hari untuk pesan response
+func trashRetentionDays() int {
+	return int(trashConfig.Retention.Hours() / 24)
+}
@@ -0,0 +1,637 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+	"lms-vue-go/backend/totp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Tujuan challenge token 2FA
+const (
+	challengeVerify = "2fa_verify" // Pengguna sudah mendaftar 2FA dan harus memasukkan kode
+	challengeEnroll = "2fa_enroll" // Role pengguna mewajibkan 2FA tetapi belum mendaftar
+)
+
+// Masa berlaku challenge token dan jumlah recovery code
+const (
+	challengeDuration = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+// twoFactorConfig adalah kebijakan 2FA yang berlaku
+var twoFactorConfig = config.DefaultTwoFactorConfig()
+
+// challengeSecret berbeda dari jwtSecret sehingga challenge token tidak dapat dipakai sebagai token login
+var challengeSecret = append(append([]byte{}, jwtSecret...), []byte(":2fa-challenge")...)
+
+// ChallengeClaims adalah claims untuk challenge token langkah kedua login
+type ChallengeClaims struct {
+	UserID  uint   `json:"uid"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// TwoFactorChallengeResponse dikirim oleh Login jika langkah kedua diperlukan
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired  bool   `json:"two_factor_required,omitempty"`
+	EnrollmentRequired bool   `json:"enrollment_required,omitempty"`
+	ChallengeToken     string `json:"challenge_token"`
+	ExpiresIn          int    `json:"expires_in"`
+}
+
+// TwoFactorVerifyRequest adalah struktur untuk request verifikasi langkah kedua
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// TwoFactorEnrollRequest adalah struktur untuk pendaftaran 2FA menggunakan challenge token
+type TwoFactorEnrollRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+}
+
+// TwoFactorCodeRequest adalah struktur untuk request yang hanya berisi kode TOTP
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableRequest adalah struktur untuk menonaktifkan 2FA
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// isTwoFactorRequired memeriksa apakah role wajib menggunakan 2FA
+func isTwoFactorRequired(role models.Role) bool {
+	for _, required := range twoFactorConfig.RequiredRoles {
+		if models.Role(required) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// startTwoFactorChallenge mengirim challenge jika pengguna harus melewati langkah kedua.
+// Mengembalikan true jika response sudah dikirim sehingga token login tidak boleh diterbitkan.
+func startTwoFactorChallenge(c *gin.Context, user *models.User, status int) bool {
+	response, err := newTwoFactorChallenge(c.Request.Context(), user)
+	if err != nil {
+		requestLog(c).Error("Error starting 2FA challenge", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat challenge 2FA"})
+		return true
+	}
+	if response == nil {
+		return false
+	}
+
+	c.JSON(status, response)
+	return true
+}
+
+// findTwoFactorSettings mengambil pengaturan TOTP pengguna; diganti dalam test
+var findTwoFactorSettings = func(ctx context.Context, userID uint) (*models.UserTOTP, error) {
+	return repository.NewTwoFactorRepository().WithContext(ctx).FindByUserID(userID)
+}
+
+// newTwoFactorChallenge membuat challenge langkah kedua untuk pengguna yang sudah mengaktifkan
+// 2FA atau yang role-nya mewajibkan 2FA. Mengembalikan nil jika langkah kedua tidak diperlukan.
+func newTwoFactorChallenge(ctx context.Context, user *models.User) (*TwoFactorChallengeResponse, error) {
+	settings, err := findTwoFactorSettings(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response TwoFactorChallengeResponse
+	var purpose string
+	switch {
+	case settings.IsEnabled():
+		response.TwoFactorRequired = true
+		purpose = challengeVerify
+	case isTwoFactorRequired(user.Role):
+		response.EnrollmentRequired = true
+		purpose = challengeEnroll
+	default:
+		return nil, nil
+	}
+
+	challenge, err := generateChallengeToken(user.ID, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	response.ChallengeToken = challenge
+	response.ExpiresIn = int(challengeDuration.Seconds())
+	return &response, nil
+}
+
+// VerifyTwoFactor menyelesaikan login dengan kode TOTP atau recovery code
+func VerifyTwoFactor(c *gin.Context) {
+	twoFactorRepo := repository.NewTwoFactorRepository().WithContext(c.Request.Context())
+
+	var req TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Code == "" && req.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	user, ok := userFromChallenge(c, req.ChallengeToken, challengeVerify)
+	if !ok {
+		return
+	}
+
+	// Kode 2FA dibatasi dengan aturan yang sama seperti password
+	if throttled(c, user.Username) {
+		return
+	}
+
+	settings, err := twoFactorRepo.FindByUserID(user.ID)
+	if err != nil {
+		requestLog(c).Error("Error loading 2FA settings", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa pengaturan 2FA"})
+		return
+	}
+	if !settings.IsEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA tidak aktif untuk akun ini"})
+		return
+	}
+
+	var valid bool
+	if req.RecoveryCode != "" {
+		valid, err = twoFactorRepo.UseRecoveryCode(user.ID, hashToken(normalizeRecoveryCode(req.RecoveryCode)))
+	} else {
+		valid, err = consumeTOTPCode(c.Request.Context(), settings, req.Code)
+	}
+	if err != nil {
+		requestLog(c).Error("Error verifying 2FA code", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memverifikasi kode"})
+		return
+	}
+
+	if !valid {
+		if err := recordLoginFailure(c.Request.Context(), user.Username, c.ClientIP()); err != nil {
+			requestLog(c).Error("Error recording login failure", "error", err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Kode verifikasi salah"})
+		return
+	}
+
+	if err := resetLoginFailures(c.Request.Context(), user.Username); err != nil {
+		requestLog(c).Error("Error resetting login failures", "error", err)
+	}
+
+	respondWithLoginToken(c, user, http.StatusOK, nil)
+}
+
+// EnrollTwoFactorSetup membuat secret TOTP untuk pengguna yang wajib 2FA saat login
+func EnrollTwoFactorSetup(c *gin.Context) {
+	var req TwoFactorEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	user, ok := userFromChallenge(c, req.ChallengeToken, challengeEnroll)
+	if !ok {
+		return
+	}
+
+	setupTOTP(c, user)
+}
+
+// EnrollTwoFactorActivate mengaktifkan 2FA dari challenge pendaftaran lalu menerbitkan token login
+func EnrollTwoFactorActivate(c *gin.Context) {
+	var req TwoFactorEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	user, ok := userFromChallenge(c, req.ChallengeToken, challengeEnroll)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, ok := enableTOTP(c, user, req.Code)
+	if !ok {
+		return
+	}
+
+	if err := resetLoginFailures(c.Request.Context(), user.Username); err != nil {
+		requestLog(c).Error("Error resetting login failures", "error", err)
+	}
+
+	respondWithLoginToken(c, user, http.StatusOK, recoveryCodes)
+}
+
+// GetTwoFactorStatus mengembalikan status 2FA pengguna yang sedang login
+func GetTwoFactorStatus(c *gin.Context) {
+	twoFactorRepo := repository.NewTwoFactorRepository().WithContext(c.Request.Context())
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	settings, err := twoFactorRepo.FindByUserID(user.ID)
+	if err != nil {
+		requestLog(c).Error("Error loading 2FA settings", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa pengaturan 2FA"})
+		return
+	}
+
+	remaining := 0
+	if settings.IsEnabled() {
+		remaining, err = twoFactorRepo.CountUnusedRecoveryCodes(user.ID)
+		if err != nil {
+			requestLog(c).Error("Error counting recovery codes", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa pengaturan 2FA"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"enabled":                  settings.IsEnabled(),
+		"required":                 isTwoFactorRequired(user.Role),
+		"recovery_codes_remaining": remaining,
+	}})
+}
+
+// SetupTwoFactor membuat secret TOTP baru untuk pengguna yang sedang login
+func SetupTwoFactor(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	setupTOTP(c, user)
+}
+
+// EnableTwoFactor mengaktifkan 2FA setelah pengguna memasukkan kode pertama
+func EnableTwoFactor(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, ok := enableTOTP(c, user, req.Code)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "2FA berhasil diaktifkan. Simpan recovery code di tempat yang aman",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// DisableTwoFactor menonaktifkan 2FA (tidak diizinkan untuk role yang wajib 2FA)
+func DisableTwoFactor(c *gin.Context) {
+	twoFactorRepo := repository.NewTwoFactorRepository().WithContext(c.Request.Context())
+
+	var req TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if isTwoFactorRequired(user.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "2FA wajib untuk role Anda"})
+		return
+	}
+
+	settings, ok := requireEnabledTOTP(c, user, req.Code)
+	if !ok {
+		return
+	}
+
+	if _, err := loginAuthenticator.Authenticate(c.Request.Context(), user.Username, req.Password); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password salah"})
+		return
+	}
+
+	if err := twoFactorRepo.Disable(settings.UserID); err != nil {
+		requestLog(c).Error("Error disabling 2FA", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menonaktifkan 2FA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "2FA berhasil dinonaktifkan"})
+}
+
+// RegenerateRecoveryCodes membuat recovery code baru dan membatalkan yang lama
+func RegenerateRecoveryCodes(c *gin.Context) {
+	twoFactorRepo := repository.NewTwoFactorRepository().WithContext(c.Request.Context())
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data tidak valid"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := requireEnabledTOTP(c, user, req.Code); !ok {
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat recovery code"})
+		return
+	}
+
+	if err := twoFactorRepo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		requestLog(c).Error("Error saving recovery codes", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan recovery code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// setupTOTP membuat secret baru yang belum aktif dan mengirim URI provisioning untuk QR code
+func setupTOTP(c *gin.Context, user *models.User) {
+	twoFactorRepo := repository.NewTwoFactorRepository().WithContext(c.Request.Context())
+
+	settings, err := twoFactorRepo.FindByUserID(user.ID)
+	if err != nil {
+		requestLog(c).Error("Error loading 2FA settings", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa pengaturan 2FA"})
+		return
+	}
+	if settings.IsEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA sudah aktif"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat secret 2FA"})
+		return
+	}
+
+	if err := twoFactorRepo.SavePendingSecret(user.ID, secret); err != nil {
+		requestLog(c).Error("Error saving 2FA secret", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan secret 2FA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"secret":           secret,
+		"provisioning_uri": totp.ProvisioningURI(twoFactorConfig.Issuer, user.Username, secret),
+	}})
+}
+
+// enableTOTP memverifikasi kode pertama, mengaktifkan 2FA, dan mengembalikan recovery code.
+// Jika gagal, response error sudah dikirim.
+func enableTOTP(c *gin.Context, user *models.User, code string) ([]string, bool) {
+	twoFactorRepo := repository.NewTwoFactorRepository().WithContext(c.Request.Context())
+
+	settings, err := twoFactorRepo.FindByUserID(user.ID)
+	if err != nil {
+		requestLog(c).Error("Error loading 2FA settings", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa pengaturan 2FA"})
+		return nil, false
+	}
+	if settings == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jalankan setup 2FA terlebih dahulu"})
+		return nil, false
+	}
+	if settings.IsEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA sudah aktif"})
+		return nil, false
+	}
+
+	step, valid := totp.Validate(settings.Secret, code, time.Now())
+	if !valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Kode verifikasi salah"})
+		return nil, false
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat recovery code"})
+		return nil, false
+	}
+
+	if err := twoFactorRepo.Enable(user.ID, step, hashes); err != nil {
+		requestLog(c).Error("Error enabling 2FA", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengaktifkan 2FA"})
+		return nil, false
+	}
+
+	return codes, true
+}
+
+// requireEnabledTOTP memastikan 2FA aktif dan kode TOTP benar. Jika gagal, response error sudah dikirim.
+func requireEnabledTOTP(c *gin.Context, user *models.User, code string) (*models.UserTOTP, bool) {
+	twoFactorRepo := repository.NewTwoFactorRepository().WithContext(c.Request.Context())
+
+	settings, err := twoFactorRepo.FindByUserID(user.ID)
+	if err != nil {
+		requestLog(c).Error("Error loading 2FA settings", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa pengaturan 2FA"})
+		return nil, false
+	}
+	if !settings.IsEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA tidak aktif untuk akun ini"})
+		return nil, false
+	}
+
+	valid, err := consumeTOTPCode(c.Request.Context(), settings, code)
+	if err != nil {
+		requestLog(c).Error("Error verifying 2FA code", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memverifikasi kode"})
+		return nil, false
+	}
+	if !valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Kode verifikasi salah"})
+		return nil, false
+	}
+
+	return settings, true
+}
+
+// consumeTOTPCode memvalidasi kode dan menandai langkah waktunya sebagai terpakai
+func consumeTOTPCode(ctx context.Context, settings *models.UserTOTP, code string) (bool, error) {
+	step, valid := totp.Validate(settings.Secret, code, time.Now())
+	if !valid {
+		return false, nil
+	}
+	return repository.NewTwoFactorRepository().WithContext(ctx).ConsumeStep(settings.UserID, step)
+}
+
+// respondWithLoginToken menerbitkan JWT dan mengirim LoginResponse, disertai recovery code jika ada
+func respondWithLoginToken(c *gin.Context, user *models.User, status int, recoveryCodes []string) {
+	token, err := generateJWT(user)
+	if err != nil {
+		requestLog(c).Error("Error generating JWT", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token"})
+		return
+	}
+
+	if recoveryCodes != nil {
+		c.JSON(status, gin.H{
+			"token":          token,
+			"user":           user.ToResponse(),
+			"recovery_codes": recoveryCodes,
+		})
+		return
+	}
+
+	c.JSON(status, LoginResponse{
+		Token: token,
+		User:  user.ToResponse(),
+	})
+}
+
+// throttled mengirim 429 jika username atau IP sedang dikunci
+func throttled(c *gin.Context, username string) bool {
+	retryAfter, err := loginRetryAfter(c.Request.Context(), username, c.ClientIP())
+	if err != nil {
+		requestLog(c).Error("Error checking login throttle", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa percobaan login"})
+		return true
+	}
+	if retryAfter <= 0 {
+		return false
+	}
+
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Terlalu banyak percobaan login gagal. Coba lagi nanti",
+		"retry_after": seconds,
+	})
+	return true
+}
+
+// currentUser mengambil pengguna yang sedang login. Jika gagal, response error sudah dikirim.
+func currentUser(c *gin.Context) (*models.User, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Tidak terautentikasi"})
+		return nil, false
+	}
+
+	user, err := repository.NewUserRepository().WithContext(c.Request.Context()).FindByID(userID.(uint))
+	if err != nil {
+		requestLog(c).Error("Error loading current user", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pengguna"})
+		return nil, false
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pengguna tidak ditemukan"})
+		return nil, false
+	}
+
+	return user, true
+}
+
+// userFromChallenge memvalidasi challenge token dan mengambil penggunanya. Jika gagal, response error sudah dikirim.
+func userFromChallenge(c *gin.Context, challengeToken, purpose string) (*models.User, bool) {
+	userID, err := parseChallengeToken(challengeToken, purpose)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Challenge token tidak valid atau sudah kedaluwarsa"})
+		return nil, false
+	}
+
+	user, err := repository.NewUserRepository().WithContext(c.Request.Context()).FindByID(userID)
+	if err != nil {
+		requestLog(c).Error("Error loading challenge user", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pengguna"})
+		return nil, false
+	}
+	if user == nil || !user.IsActive {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Akun tidak aktif atau tidak ditemukan"})
+		return nil, false
+	}
+
+	return user, true
+}
+
+// generateChallengeToken membuat challenge token berumur pendek untuk langkah kedua login
+func generateChallengeToken(userID uint, purpose string) (string, error) {
+	now := time.Now()
+	claims := &ChallengeClaims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(challengeDuration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(challengeSecret)
+}
+
+// parseChallengeToken memvalidasi challenge token dan tujuannya
+func parseChallengeToken(tokenString, purpose string) (uint, error) {
+	claims := &ChallengeClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return challengeSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return 0, err
+	}
+
+	if !token.Valid || claims.Purpose != purpose || claims.UserID == 0 {
+		return 0, errors.New("invalid challenge token")
+	}
+
+	return claims.UserID, nil
+}
+
+// generateRecoveryCodes membuat recovery code baru beserta hash yang disimpan
+func generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(buf))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashToken(raw)
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode menghapus tanda hubung dan spasi dari recovery code
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
+	"lms-vue-go/backend/storage"
+)
+
+// storageConfig, fileStore dan fileSigner digunakan untuk menyimpan dan membagikan file unggahan
+var (
+	storageConfig                   = config.DefaultStorageConfig()
+	fileStore     storage.BlobStore = storage.New(storageConfig)
+	// Kunci fileSigner berbeda dari jwtSecret sehingga tanda tangan link file tidak dapat dipakai sebagai token login
+	fileSigner = storage.NewSigner(append(append([]byte{}, jwtSecret...), []byte(":file-url")...))
+)
+
+// SetFileStore mengganti blob store yang digunakan handler (misalnya untuk pengujian)
+func SetFileStore(s storage.BlobStore) {
+	fileStore = s
+}
+
+// maxFilenameLength sesuai dengan kolom uploads.filename
+const maxFilenameLength = 255
+
+// uploadPolicy mengembalikan batas ukuran dan tipe file untuk kegunaan upload
+func uploadPolicy(purpose models.UploadPurpose) storage.Policy {
+	if purpose == models.UploadQuestionImage {
+		return storage.Policy{MaxSize: storageConfig.MaxImageSize, Types: storage.ImageTypes}
+	}
+	return storage.Policy{
+		MaxSize: storageConfig.MaxAttachmentSize,
+		Types:   append(append([]string{}, storage.ImageTypes...), storage.DocumentTypes...),
+	}
+}
+
+// UploadFile mengunggah gambar soal (purpose=question_image, hanya admin dan guru)
+// atau lampiran (purpose=attachment) dari field multipart 'file'. Tipe file
+// dideteksi dari isinya, bukan dari nama file.
+func UploadFile(c *gin.Context) {
+	purpose := models.UploadPurpose(c.DefaultQuery("purpose", string(models.UploadAttachment)))
+	switch purpose {
+	case models.UploadQuestionImage:
+		userRole, _ := c.Get("userRole")
+		if userRole != models.RoleAdmin && userRole != models.RoleTeacher {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Hanya admin dan guru yang dapat mengunggah gambar soal"})
+			return
+		}
+	case models.UploadAttachment:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter purpose harus question_image atau attachment"})
+		return
+	}
+
+	upload, ok := storeUpload(c, purpose, uploadPolicy(purpose))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": upload, "message": "File berhasil diunggah"})
+}
+
+// GetUpload menampilkan data file beserta link baru untuk membukanya
+func GetUpload(c *gin.Context) {
+	upload, ok := findUploadParam(c)
+	if !ok {
+		return
+	}
+	if !canAccessUpload(c, upload) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki akses ke file ini"})
+		return
+	}
+
+	upload.URL = uploadURL(upload)
+	c.JSON(http.StatusOK, gin.H{"data": upload})
+}
+
+// DeleteUpload menghapus file; hanya pengunggah dan admin
+func DeleteUpload(c *gin.Context) {
+	upload, ok := findUploadParam(c)
+	if !ok {
+		return
+	}
+	if !isAdminRole(c) && !isCurrentUser(c, upload.UploadedBy) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki akses ke file ini"})
+		return
+	}
+	if !uploadDeletable(c, upload) {
+		return
+	}
+
+	if err := repository.NewUploadRepository().WithContext(c.Request.Context()).Delete(upload.ID); err != nil {
+		requestLog(c).Error("Error deleting upload", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus file"})
+		return
+	}
+	if err := fileStore.Delete(c.Request.Context(), upload.StorageKey); err != nil {
+		requestLog(c).Error("Error deleting stored file", "key", upload.StorageKey, "error", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "File berhasil dihapus"})
+}
+
+// uploadDeletable menolak penghapusan file jawaban yang sudah terlampir pada jawaban, karena
+// file tersebut adalah bahan penilaian. File jawaban hanya dilepas dengan mengirim ulang
+// jawaban tanpa file itu di file_ids. Jika gagal, response error sudah dikirim.
+func uploadDeletable(c *gin.Context, upload *models.Upload) bool {
+	if upload.Purpose != models.UploadAnswerFile {
+		return true
+	}
+
+	answerID, err := answerIDByFile(c.Request.Context(), upload.ID)
+	if err != nil {
+		requestLog(c).Error("Error finding answer of upload", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa file"})
+		return false
+	}
+	if answerID != 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "File sudah terlampir pada jawaban. Kirim ulang jawaban tanpa file ini untuk melepasnya"})
+		return false
+	}
+	return true
+}
+
+// ServeFile mengirim isi file. Gambar soal dapat dibuka siapa saja seperti soal
+// publik; file lain memerlukan parameter expires dan signature dari link bertanda tangan.
+func ServeFile(c *gin.Context) {
+	upload, ok := findUploadParam(c)
+	if !ok {
+		return
+	}
+
+	path := "/api/files/" + strconv.Itoa(int(upload.ID))
+	cacheControl := "public, max-age=86400"
+	if upload.Purpose != models.UploadQuestionImage {
+		if err := fileSigner.Verify(path, c.Query("expires"), c.Query("signature")); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Link tidak valid atau sudah kedaluwarsa"})
+			return
+		}
+		cacheControl = "private, no-store"
+	}
+
+	reader, err := fileStore.Open(c.Request.Context(), upload.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File tidak ditemukan"})
+			return
+		}
+		requestLog(c).Error("Error opening stored file", "key", upload.StorageKey, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuka file"})
+		return
+	}
+	defer reader.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(upload.ContentType, "image/") || upload.ContentType == "application/pdf" {
+		disposition = "inline"
+	}
+	c.DataFromReader(http.StatusOK, upload.Size, upload.ContentType, reader, map[string]string{
+		"Content-Disposition": mime.FormatMediaType(disposition, map[string]string{"filename": upload.Filename}),
+		"Cache-Control":       cacheControl,
+		// File unggahan tidak boleh menjalankan skrip meskipun dibuka langsung
+		"Content-Security-Policy": "sandbox; default-src 'none'; img-src 'self'; style-src 'unsafe-inline'",
+	})
+}
+
+// storeUpload membaca field multipart 'file', memeriksa ukuran dan tipenya, lalu
+// menyimpan isi file di blob store dan datanya di database. Jika gagal, response error sudah dikirim.
+func storeUpload(c *gin.Context, purpose models.UploadPurpose, policy storage.Policy) (*models.Upload, bool) {
+	limitMB := fmt.Sprintf("%.0f MB", float64(policy.MaxSize)/(1<<20))
+	// Ruang tambahan untuk header multipart
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, policy.MaxSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		if isTooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Ukuran file maksimal " + limitMB})
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak ditemukan pada field 'file'"})
+		return nil, false
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak dapat dibuka"})
+		return nil, false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, policy.MaxSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak dapat dibaca"})
+		return nil, false
+	}
+
+	contentType, err := policy.Check(data)
+	switch {
+	case errors.Is(err, storage.ErrEmptyFile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File kosong"})
+		return nil, false
+	case errors.Is(err, storage.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Ukuran file maksimal " + limitMB})
+		return nil, false
+	case errors.Is(err, storage.ErrTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": fmt.Sprintf("Tipe file %s tidak diizinkan, gunakan %s", contentType, strings.Join(policy.Types, ", ")),
+		})
+		return nil, false
+	}
+
+	key, err := storage.NewKey(string(purpose), storage.Extension(contentType))
+	if err != nil {
+		requestLog(c).Error("Error generating storage key", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan file"})
+		return nil, false
+	}
+	if err := fileStore.Put(c.Request.Context(), key, data, contentType); err != nil {
+		requestLog(c).Error("Error storing file", "key", key, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan file"})
+		return nil, false
+	}
+
+	userID, _ := c.Get("userID")
+	uploadedBy, _ := userID.(uint)
+	upload := &models.Upload{
+		StorageKey:  key,
+		Filename:    cleanFilename(fileHeader.Filename, contentType),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Purpose:     purpose,
+		UploadedBy:  uploadedBy,
+	}
+	if err := repository.NewUploadRepository().WithContext(c.Request.Context()).Create(upload); err != nil {
+		requestLog(c).Error("Error saving upload", "error", err)
+		if err := fileStore.Delete(c.Request.Context(), key); err != nil {
+			requestLog(c).Error("Error deleting stored file", "key", key, "error", err)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan file"})
+		return nil, false
+	}
+
+	upload.URL = uploadURL(upload)
+	return upload, true
+}
+
+// findUploadParam mengambil data file dari parameter :id. Jika gagal, response error sudah dikirim.
+func findUploadParam(c *gin.Context) (*models.Upload, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return nil, false
+	}
+
+	upload, err := repository.NewUploadRepository().WithContext(c.Request.Context()).FindByID(uint(id))
+	if err != nil {
+		requestLog(c).Error("Error finding upload", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data file"})
+		return nil, false
+	}
+
+	if upload == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File tidak ditemukan"})
+		return nil, false
+	}
+
+	return upload, true
+}
+
+// canAccessUpload memeriksa apakah pengguna boleh membuka file: pengunggah, guru atau admin
+func canAccessUpload(c *gin.Context, upload *models.Upload) bool {
+	userRole, _ := c.Get("userRole")
+	return userRole == models.RoleAdmin || userRole == models.RoleTeacher || isCurrentUser(c, upload.UploadedBy)
+}
+
+// uploadURL mengembalikan link file. Gambar soal memakai link tetap sehingga dapat
+// disimpan di image_url soal; file lain mendapat link bertanda tangan yang kedaluwarsa.
+func uploadURL(upload *models.Upload) string {
+	path := "/api/files/" + strconv.Itoa(int(upload.ID))
+	if upload.Purpose == models.UploadQuestionImage {
+		return path
+	}
+	return fileSigner.Sign(path, storageConfig.SignedURLTTL)
+}
+
+// cleanFilename membuang path dan karakter kontrol dari nama file kiriman klien
+func cleanFilename(name, contentType string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == "/" {
+		name = "file" + storage.Extension(contentType)
+	}
+	if runes := []rune(name); len(runes) > maxFilenameLength {
+		name = string(runes[len(runes)-maxFilenameLength:])
+	}
+	return name
+}
@@ -0,0 +1,144 @@
+// Package logging sets up structured logging with log/slog. Records are written as
+// JSON (or text) at a configurable level, carry the ID of the request they belong
+// to, and have passwords, tokens and secrets redacted. Output of the standard log
+// package is routed through the same handler, so older log.Printf calls produce
+// structured records too.
+package logging
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+
+	"lms-vue-go/backend/config"
+)
+
+// requestIDKey is the context key of the request ID
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx that carries the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" outside a request
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// FromContext returns the default logger with the request ID of ctx attached
+func FromContext(ctx context.Context) *slog.Logger {
+	if id := RequestID(ctx); id != "" {
+		return slog.Default().With("request_id", id)
+	}
+	return slog.Default()
+}
+
+// ParseLevel turns debug, info, warn or error into a level; anything else is info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Setup creates the logger described by cfg, writing to stdout, and makes it the
+// default for slog and the standard log package
+func Setup(cfg config.LogConfig) *slog.Logger {
+	logger := New(os.Stdout, cfg)
+	slog.SetDefault(logger)
+	log.SetFlags(0)
+	log.SetOutput(Writer(logger, slog.LevelInfo, true))
+	return logger
+}
+
+// New creates a logger writing to w
+func New(w io.Writer, cfg config.LogConfig) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: ParseLevel(cfg.Level)}
+	if cfg.Redact {
+		opts.ReplaceAttr = redactAttr
+	}
+
+	var handler slog.Handler
+	if strings.EqualFold(cfg.Format, "text") {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// contextHandler adds the request ID of the context to every record, so the
+// *Context methods of slog (slog.ErrorContext and so on) are correlated too
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// lineWriter logs every line written to it as one record
+type lineWriter struct {
+	logger *slog.Logger
+	level  slog.Level
+	detect bool
+}
+
+// Writer returns a writer that logs each line as a record at level. With detect,
+// lines starting with "error", "failed" or "fatal" are logged as errors and lines
+// starting with "warning" as warnings, matching the messages of the log package calls.
+func Writer(logger *slog.Logger, level slog.Level, detect bool) io.Writer {
+	return lineWriter{logger: logger, level: level, detect: detect}
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		level := w.level
+		if w.detect {
+			level = detectLevel(line, level)
+		}
+		w.logger.Log(context.Background(), level, line)
+	}
+	return len(p), nil
+}
+
+// detectLevel reads the level from the start of a log line
+func detectLevel(line string, fallback slog.Level) slog.Level {
+	lower := strings.ToLower(strings.TrimSpace(line))
+	switch {
+	case strings.HasPrefix(lower, "error"), strings.HasPrefix(lower, "failed"), strings.HasPrefix(lower, "fatal"):
+		return slog.LevelError
+	case strings.HasPrefix(lower, "warn"):
+		return slog.LevelWarn
+	default:
+		return fallback
+	}
+}
@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"lms-vue-go/backend/config"
+)
+
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, config.LogConfig{Level: "info", Format: "json", Redact: true})
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	logger.ErrorContext(ctx, "Login with password=hunter2 failed",
+		"password", "hunter2",
+		slog.Group("user", "username", "budi", "api_token", "lms_abc"),
+		"error", errors.New(`request {"refresh_token": "r1"} rejected`),
+		"url", "/api/auth/oidc/callback?code=xyz&state=s",
+		"header", "Bearer eyJhbGciOi.eyJzdWIi.c2ln",
+	)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "lms_abc") ||
+		strings.Contains(buf.String(), "r1") || strings.Contains(buf.String(), "xyz") || strings.Contains(buf.String(), "eyJ") {
+		t.Errorf("secret logged: %s", buf.String())
+	}
+	if record["request_id"] != "req-1" || record["level"] != "ERROR" {
+		t.Errorf("record = %v", record)
+	}
+	if user := record["user"].(map[string]interface{}); user["username"] != "budi" {
+		t.Errorf("user = %v", user)
+	}
+	if record["url"] != "/api/auth/oidc/callback?code=[REDACTED]&state=s" {
+		t.Errorf("url = %v", record["url"])
+	}
+}
+
+func TestWriterDetectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, config.LogConfig{Level: "warn"})
+	w := Writer(logger, slog.LevelInfo, true)
+
+	w.Write([]byte("Server started\n"))
+	w.Write([]byte("WARNING: Database connection is nil in UserRepository\n"))
+	w.Write([]byte("Error finding student: timeout\n"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"level":"WARN"`) || !strings.Contains(lines[1], `"level":"ERROR"`) {
+		t.Errorf("output = %s", buf.String())
+	}
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces sensitive values in log records
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are parts of attribute keys whose values are never logged
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "api_key", "apikey", "recovery_code"}
+
+// sensitivePatterns find sensitive values inside free text such as messages,
+// errors and URLs. The first group is kept, the rest is replaced.
+var sensitivePatterns = []*regexp.Regexp{
+	// Query parameters and key=value pairs: token=abc, password=abc, code=abc
+	regexp.MustCompile(`(?i)\b((?:password|passwd|pwd|secret|client_secret|token|access_token|refresh_token|id_token|api_key|code)=)[^&\s"']+`),
+	// JSON fields: "password": "abc"
+	regexp.MustCompile(`(?i)("[a-z_]*(?:password|secret|token)[a-z_]*"\s*:\s*)"[^"]*"`),
+	// Authorization header values
+	regexp.MustCompile(`(?i)\b((?:bearer|basic)\s+)[A-Za-z0-9._~+/=-]+`),
+	// JSON Web Tokens and personal API tokens
+	regexp.MustCompile(`()\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	regexp.MustCompile(`()\blms_[A-Za-z0-9_-]{16,}`),
+}
+
+// IsSensitiveKey reports whether values logged under key must be redacted
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeys {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString replaces the sensitive values found in text
+func RedactString(text string) string {
+	for _, pattern := range sensitivePatterns {
+		text = pattern.ReplaceAllString(text, "${1}"+Redacted)
+	}
+	return text
+}
+
+// redactAttr is the ReplaceAttr function of the handler. It is called for every
+// attribute including the message, and for the members of groups.
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+		return a
+	}
+	if IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, RedactString(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, RedactString(err.Error()))
+		}
+	}
+	return a
+}
@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogMailer writes messages to a file or the standard logger instead of sending them.
+// It is intended for development and tests. Only the file receives the body: bodies
+// hold reset links and initial passwords, so the server log gets recipient and subject.
+type LogMailer struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// NewLogMailer creates a new log mailer; an empty path logs to the standard logger
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{Path: path}
+}
+
+// Send records the message
+func (m *LogMailer) Send(msg Message) error {
+	if m.Path == "" {
+		slog.Info("Mail not sent (log driver), set MAIL_LOG_PATH to keep the body", "to", msg.To, "subject", msg.Subject)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := fmt.Sprintf("=== %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(entry)
+	return err
+}
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"log/slog"
+	"strings"
+
+	"lms-vue-go/backend/config"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends email messages
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New creates the mailer selected by the configuration driver
+func New(cfg config.MailConfig) Mailer {
+	switch strings.ToLower(cfg.Driver) {
+	case "smtp":
+		return NewSMTPMailer(cfg)
+	case "log", "":
+		return NewLogMailer(cfg.LogPath)
+	default:
+		slog.Warn("Unknown mail driver, falling back to log mailer", "driver", cfg.Driver)
+		return NewLogMailer(cfg.LogPath)
+	}
+}
@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogMailerWritesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	m := NewLogMailer(path)
+
+	err := m.Send(Message{To: "siswa@example.com", Subject: "Reset password", Body: "link"})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading mail log: %v", err)
+	}
+	if !strings.Contains(string(content), "To: siswa@example.com") {
+		t.Errorf("mail log does not contain recipient: %s", content)
+	}
+}
+
+func TestLogMailerKeepsBodyOutOfServerLog(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	err := NewLogMailer("").Send(Message{To: "siswa@example.com", Subject: "Akun LMS Anda", Body: "Password: rahasia123"})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "siswa@example.com") {
+		t.Errorf("server log does not contain recipient: %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "rahasia123") {
+		t.Errorf("server log contains the mail body: %s", buf.String())
+	}
+}
+
+func TestBuildMessageStripsHeaderInjection(t *testing.T) {
+	msg := buildMessage("LMS <no-reply@example.com>", Message{
+		To:      "siswa@example.com",
+		Subject: "Halo\r\nBcc: attacker@example.com",
+		Body:    "isi",
+	})
+
+	if strings.Contains(string(msg), "\r\nBcc:") {
+		t.Errorf("subject line break was not stripped: %q", msg)
+	}
+}
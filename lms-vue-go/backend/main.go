@@ -1,29 +1,43 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"log/slog"
+	"os"
 
+	"github.com/gin-gonic/gin"
 	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/logging"
+	"lms-vue-go/backend/maintenance"
 	"lms-vue-go/backend/routes"
 )
 
 func main() {
+	// Log terstruktur (JSON) untuk seluruh server, termasuk output log dan gin
+	logger := logging.Setup(config.DefaultLogConfig())
+	gin.DefaultWriter = logging.Writer(logger, slog.LevelDebug, false)
+	gin.DefaultErrorWriter = logging.Writer(logger, slog.LevelError, false)
+
 	// Initialize database connection
 	dbConfig := config.DefaultConfig()
 	err := config.InitDB(dbConfig)
 	if err != nil {
-		log.Fatal("Error connecting to database:", err)
+		slog.Error("Error connecting to database", "error", err)
+		os.Exit(1)
 	}
 	defer config.CloseDB()
 
+	// Hapus permanen soal dan siswa yang sudah melewati masa simpan di tempat sampah
+	stopPurge := maintenance.StartTrashPurge(config.DefaultTrashConfig())
+	defer stopPurge()
+
 	// Menggunakan router yang sudah dibuat
 	r := routes.SetupRouter()
 
 	// Jalankan server pada port 3001
-	fmt.Println("Server berjalan pada http://localhost:3001")
+	slog.Info("Server berjalan", "address", "http://localhost:3001")
 	err = r.Run(":3001")
 	if err != nil {
-		log.Fatal("Error menjalankan server:", err)
+		slog.Error("Error menjalankan server", "error", err)
+		os.Exit(1)
 	}
 }
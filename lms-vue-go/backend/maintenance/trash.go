@@ -0,0 +1,59 @@
+// Package maintenance runs background jobs that keep the database tidy.
+package maintenance
+
+import (
+	"log/slog"
+	"time"
+
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/repository"
+)
+
+// PurgeTrash permanently deletes questions and students that have been in the trash
+// longer than the retention period
+func PurgeTrash(retention time.Duration) error {
+	before := time.Now().Add(-retention)
+
+	questions, err := repository.NewQuestionRepository().PurgeDeleted(before)
+	if err != nil {
+		return err
+	}
+
+	students, err := repository.NewStudentRepository().PurgeDeleted(before)
+	if err != nil {
+		return err
+	}
+
+	if questions > 0 || students > 0 {
+		slog.Info("Purged trash", "questions", questions, "students", students)
+	}
+	return nil
+}
+
+// StartTrashPurge runs PurgeTrash now and then every cfg.PurgeInterval until the
+// returned stop function is called. It does nothing if the interval is 0.
+func StartTrashPurge(cfg config.TrashConfig) (stop func()) {
+	if cfg.PurgeInterval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.PurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := PurgeTrash(cfg.Retention); err != nil {
+				slog.Error("Error purging trash", "error", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
@@ -1,10 +1,15 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
+	"lms-vue-go/backend/logging"
 	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/repository"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -34,6 +39,12 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Format token: "Bearer <token>"
 		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
 
+		// Token API pribadi diperiksa di database, bukan sebagai JWT
+		if strings.HasPrefix(tokenString, models.APITokenPrefix) {
+			authenticateAPIToken(c, tokenString)
+			return
+		}
+
 		// Parse token
 		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 			return jwtSecret, nil
@@ -46,20 +57,136 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		// Validasi token
-		if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-			// Set user ID dan role ke context
-			c.Set("userID", claims.UserID)
-			c.Set("userRole", claims.Role)
-			c.Next()
-		} else {
+		claims, ok := token.Claims.(*JWTClaims)
+		if !ok || !token.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token tidak valid"})
 			c.Abort()
+			return
+		}
+
+		// Pastikan akun masih ada dan aktif
+		user, err := loadActiveUser(c.Request.Context(), claims.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa pengguna"})
+			c.Abort()
+			return
+		}
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Akun tidak aktif atau tidak ditemukan"})
+			c.Abort()
+			return
 		}
+
+		// Set user ID dan role ke context. Role diambil dari database
+		// agar perubahan role oleh admin langsung berlaku.
+		c.Set("userID", user.ID)
+		c.Set("userRole", user.Role)
+		c.Set("authMethod", AuthMethodJWT)
+		c.Next()
 	}
 }
 
+// Metode autentikasi yang disimpan di context dengan kunci "authMethod"
+const (
+	AuthMethodJWT      = "jwt"
+	AuthMethodAPIToken = "api_token"
+)
+
+// authenticateAPIToken memvalidasi token API pribadi beserta scope-nya untuk request ini
+func authenticateAPIToken(c *gin.Context, rawToken string) {
+	tokenRepo := repository.NewAPITokenRepository().WithContext(c.Request.Context())
+
+	token, err := tokenRepo.FindByRawToken(rawToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa token"})
+		c.Abort()
+		return
+	}
+	if status, message := apiTokenError(token, c.Request.Method, c.Request.URL.Path, time.Now()); status != 0 {
+		c.JSON(status, gin.H{"error": message})
+		c.Abort()
+		return
+	}
+
+	user, err := loadActiveUser(c.Request.Context(), token.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa pengguna"})
+		c.Abort()
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Akun tidak aktif atau tidak ditemukan"})
+		c.Abort()
+		return
+	}
+
+	if err := tokenRepo.TouchLastUsed(token.ID); err != nil {
+		logging.FromContext(c.Request.Context()).Error("Error updating API token last use", "error", err)
+	}
+
+	// Role tetap dibatasi oleh RoleMiddleware; scope hanya mempersempit akses pengguna
+	c.Set("userID", user.ID)
+	c.Set("userRole", user.Role)
+	c.Set("authMethod", AuthMethodAPIToken)
+	c.Set("apiTokenID", token.ID)
+	c.Next()
+}
+
+// apiTokenError memeriksa apakah token API (nil jika tidak ditemukan) boleh dipakai untuk
+// request ini. Mengembalikan status dan pesan error, atau status 0 jika token boleh dipakai.
+func apiTokenError(token *models.APIToken, method, path string, now time.Time) (int, string) {
+	if token == nil || !token.IsUsable(now) {
+		return http.StatusUnauthorized, "Token tidak valid"
+	}
+
+	resource, action := scopeForRequest(method, path)
+	if !token.Allows(resource, action) {
+		return http.StatusForbidden, "Scope token tidak mengizinkan " + resource + ":" + action
+	}
+	return 0, ""
+}
+
+// scopeForRequest menentukan scope yang dibutuhkan: resource adalah segmen path setelah /api/
+// dan aksi read untuk GET/HEAD/OPTIONS, write untuk metode lain
+func scopeForRequest(method, path string) (string, string) {
+	resource := strings.TrimPrefix(path, "/api/")
+	resource, _, _ = strings.Cut(resource, "/")
+
+	action := models.ScopeWrite
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		action = models.ScopeRead
+	}
+
+	return resource, action
+}
+
+// SessionOnly menolak request yang diautentikasi dengan token API, misalnya untuk
+// pengelolaan token itu sendiri agar token tidak dapat membuat token baru
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") == AuthMethodAPIToken {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Endpoint ini hanya dapat diakses dengan login biasa"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// loadActiveUser mengambil pengguna dari database; mengembalikan nil jika tidak ada atau nonaktif
+func loadActiveUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := repository.NewUserRepository().WithContext(ctx).FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, nil
+	}
+	return user, nil
+}
+
 // ValidateToken validates a JWT token and returns the user ID
-func ValidateToken(tokenString string) (uint, error) {
+func ValidateToken(ctx context.Context, tokenString string) (uint, error) {
 	// Parse token
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return jwtSecret, nil
@@ -70,11 +197,21 @@ func ValidateToken(tokenString string) (uint, error) {
 	}
 
 	// Validate token
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims.UserID, nil
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid {
+		return 0, jwt.ErrSignatureInvalid
+	}
+
+	// Disabled accounts cannot use their remaining tokens
+	user, err := loadActiveUser(ctx, claims.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, errors.New("user is inactive or not found")
 	}
 
-	return 0, jwt.ErrSignatureInvalid
+	return claims.UserID, nil
 }
 
 // RoleMiddleware adalah middleware untuk memeriksa peran pengguna
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"lms-vue-go/backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestScopeForRequest(t *testing.T) {
+	cases := []struct {
+		method, path     string
+		resource, action string
+	}{
+		{http.MethodGet, "/api/questions", "questions", models.ScopeRead},
+		{http.MethodHead, "/api/questions/5", "questions", models.ScopeRead},
+		{http.MethodOptions, "/api/answers", "answers", models.ScopeRead},
+		{http.MethodPost, "/api/answers/submit", "answers", models.ScopeWrite},
+		{http.MethodPut, "/api/students/3", "students", models.ScopeWrite},
+		{http.MethodDelete, "/api/questions/5", "questions", models.ScopeWrite},
+	}
+	for _, tc := range cases {
+		resource, action := scopeForRequest(tc.method, tc.path)
+		if resource != tc.resource || action != tc.action {
+			t.Errorf("scopeForRequest(%s %s) = %s:%s, want %s:%s", tc.method, tc.path, resource, action, tc.resource, tc.action)
+		}
+	}
+}
+
+func TestAPITokenError(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	readOnly := &models.APIToken{Scopes: []string{"questions:read"}}
+	writer := &models.APIToken{Scopes: []string{"answers:write"}}
+	wildcard := &models.APIToken{Scopes: []string{"*:read"}}
+	revoked := &models.APIToken{Scopes: []string{"*:write"}, RevokedAt: &past}
+	expired := &models.APIToken{Scopes: []string{"*:write"}, ExpiresAt: &past}
+	notExpired := &models.APIToken{Scopes: []string{"questions:read"}, ExpiresAt: &future}
+
+	cases := []struct {
+		name         string
+		token        *models.APIToken
+		method, path string
+		want         int
+	}{
+		{"read scope allows GET", readOnly, http.MethodGet, "/api/questions/1", 0},
+		{"read scope refuses write", readOnly, http.MethodPost, "/api/questions", http.StatusForbidden},
+		{"read scope is limited to its resource", readOnly, http.MethodGet, "/api/students", http.StatusForbidden},
+		{"write scope allows write", writer, http.MethodPost, "/api/answers/submit", 0},
+		{"write scope implies read", writer, http.MethodGet, "/api/answers", 0},
+		{"wildcard read allows any resource", wildcard, http.MethodGet, "/api/students", 0},
+		{"wildcard read refuses write", wildcard, http.MethodDelete, "/api/students/1", http.StatusForbidden},
+		{"unknown token", nil, http.MethodGet, "/api/questions", http.StatusUnauthorized},
+		{"revoked token", revoked, http.MethodGet, "/api/questions", http.StatusUnauthorized},
+		{"expired token", expired, http.MethodGet, "/api/questions", http.StatusUnauthorized},
+		{"token before expiry", notExpired, http.MethodGet, "/api/questions", 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, message := apiTokenError(tc.token, tc.method, tc.path, now); got != tc.want {
+				t.Errorf("apiTokenError = %d (%q), want %d", got, message, tc.want)
+			}
+		})
+	}
+}
+
+func TestSessionOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := map[string]int{
+		AuthMethodJWT:      http.StatusOK,
+		AuthMethodAPIToken: http.StatusForbidden,
+	}
+	for method, want := range cases {
+		router := gin.New()
+		router.GET("/api/auth/tokens", func(c *gin.Context) { c.Set("authMethod", method) }, SessionOnly(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/tokens", nil))
+		if w.Code != want {
+			t.Errorf("SessionOnly with %s = %d, want %d", method, w.Code, want)
+		}
+	}
+}
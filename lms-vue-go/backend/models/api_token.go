@@ -0,0 +1,60 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APITokenPrefix menandai token API sehingga middleware dapat membedakannya dari JWT
+const APITokenPrefix = "lms_"
+
+// Aksi pada scope token API. Scope berbentuk "<resource>:<aksi>", misalnya "questions:read",
+// dengan resource adalah segmen path setelah /api/. Resource "*" berlaku untuk semua endpoint.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// APIToken merepresentasikan token API pribadi milik pengguna
+type APIToken struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Awal token untuk membantu pengguna mengenali token
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+}
+
+// IsUsable memeriksa apakah token belum dicabut dan belum kedaluwarsa
+func (t *APIToken) IsUsable(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+// Allows memeriksa apakah scope token mengizinkan aksi pada resource. Scope write juga mencakup read.
+func (t *APIToken) Allows(resource, action string) bool {
+	for _, scope := range t.Scopes {
+		scopeResource, scopeAction, ok := strings.Cut(scope, ":")
+		if !ok || (scopeResource != "*" && scopeResource != resource) {
+			continue
+		}
+		if scopeAction == action || scopeAction == ScopeWrite {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidScope memeriksa format scope token API
+func IsValidScope(scope string) bool {
+	resource, action, ok := strings.Cut(scope, ":")
+	if !ok || resource == "" || strings.ContainsAny(resource, "/ ") {
+		return false
+	}
+	return action == ScopeRead || action == ScopeWrite
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction adalah jenis perubahan yang dicatat di log audit
+type AuditAction string
+
+const (
+	AuditGradeUpdate     AuditAction = "grade.update"
+	AuditQuestionCreate  AuditAction = "question.create"
+	AuditQuestionUpdate  AuditAction = "question.update"
+	AuditQuestionDelete  AuditAction = "question.delete"
+	AuditQuestionRestore AuditAction = "question.restore"
+	AuditQuestionPurge   AuditAction = "question.purge"
+	AuditStudentCreate   AuditAction = "student.create"
+	AuditStudentUpdate   AuditAction = "student.update"
+	AuditStudentDelete   AuditAction = "student.delete"
+	AuditStudentRestore  AuditAction = "student.restore"
+	AuditStudentPurge    AuditAction = "student.purge"
+	AuditUserRoleUpdate  AuditAction = "user.role_update"
+	AuditUserStatus      AuditAction = "user.status_update"
+	AuditUserPassword    AuditAction = "user.password_reset"
+	AuditUserDelete      AuditAction = "user.delete"
+)
+
+// AuditEntity adalah jenis data yang diubah
+type AuditEntity string
+
+const (
+	AuditEntityAnswer   AuditEntity = "answer"
+	AuditEntityQuestion AuditEntity = "question"
+	AuditEntityStudent  AuditEntity = "student"
+	AuditEntityUser     AuditEntity = "user"
+)
+
+// AuditLog merepresentasikan satu catatan log audit. Catatan tidak pernah diubah atau
+// dihapus; setiap catatan memuat hash catatan sebelumnya sehingga perubahan dapat dideteksi.
+type AuditLog struct {
+	ID         uint64          `json:"id"`
+	ActorID    *uint           `json:"actor_id"` // nil untuk perubahan oleh sistem, misalnya pembersihan tempat sampah
+	ActorRole  Role            `json:"actor_role,omitempty"`
+	Action     AuditAction     `json:"action"`
+	EntityType AuditEntity     `json:"entity_type"`
+	EntityID   uint            `json:"entity_id"`
+	Before     json.RawMessage `json:"before"` // Nilai sebelum perubahan; null untuk data baru
+	After      json.RawMessage `json:"after"`  // Nilai sesudah perubahan; null untuk data yang dihapus
+	IP         string          `json:"ip"`
+	CreatedAt  time.Time       `json:"created_at"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
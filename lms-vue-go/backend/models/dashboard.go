@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// DashboardSummary adalah ringkasan dashboard guru yang dihitung langsung di database
+type DashboardSummary struct {
+	Students            int                  `json:"students"`
+	Questions           int                  `json:"questions"`
+	PendingGrading      int                  `json:"pending_grading"`       // Jawaban esai yang belum dinilai
+	OldestPendingAt     *time.Time           `json:"oldest_pending_at"`     // Waktu kirim jawaban belum dinilai paling lama
+	SubmissionsLastWeek int                  `json:"submissions_last_week"` // Jawaban yang dikirim dalam 7 hari terakhir
+	RecentSubmissions   []RecentSubmission   `json:"recent_submissions"`    // Jawaban terbaru lebih dulu
+	ClassAverages       []ClassAverage       `json:"class_averages"`        // Urut nama kelas
+	MissingWorkCount    int                  `json:"missing_work_count"`    // Siswa yang belum menjawab semua soal
+	MissingWork         []StudentMissingWork `json:"missing_work"`          // Siswa dengan soal belum dijawab terbanyak lebih dulu
+	UpcomingDeadlines   []ExamDeadline       `json:"upcoming_deadlines"`    // Jadwal ujian yang belum lewat, terdekat lebih dulu
+	GeneratedAt         time.Time            `json:"generated_at"`
+}
+
+// RecentSubmission adalah satu jawaban yang baru dikirim
+type RecentSubmission struct {
+	AnswerID     uint         `json:"answer_id"`
+	StudentID    uint         `json:"student_id"`
+	StudentName  string       `json:"student_name"`
+	StudentClass string       `json:"student_class"`
+	QuestionID   uint         `json:"question_id"`
+	Question     string       `json:"question"` // Teks soal, dipotong 120 karakter
+	QuestionType QuestionType `json:"question_type"`
+	Score        *int         `json:"score"` // Nil jika belum dinilai
+	SubmittedAt  time.Time    `json:"submitted_at"`
+}
+
+// ClassAverage adalah rata-rata nilai satu kelas
+type ClassAverage struct {
+	Class    string   `json:"class"`
+	Students int      `json:"students"`
+	Graded   int      `json:"graded"`  // Jawaban yang sudah dinilai
+	Pending  int      `json:"pending"` // Jawaban yang belum dinilai
+	Average  *float64 `json:"average"` // Persentase poin yang diperoleh dari jawaban yang sudah dinilai; nil jika belum ada
+}
+
+// StudentMissingWork adalah siswa yang belum menjawab semua soal
+type StudentMissingWork struct {
+	StudentID uint   `json:"student_id"`
+	Name      string `json:"name"`
+	Class     string `json:"class"`
+	Answered  int    `json:"answered"`
+	Missing   int    `json:"missing"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ExamSchedule adalah jadwal ujian dengan batas waktu pengerjaan
+type ExamSchedule struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Class     string    `json:"class"` // Kosong berarti berlaku untuk semua kelas
+	DueAt     time.Time `json:"due_at"`
+	CreatedBy *uint     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// ExamDeadline adalah batas waktu ujian yang akan datang di dashboard guru
+type ExamDeadline struct {
+	ID       uint      `json:"id"`
+	Title    string    `json:"title"`
+	Class    string    `json:"class"`
+	DueAt    time.Time `json:"due_at"`
+	Students int       `json:"students"` // Siswa di kelas tersebut (semua siswa jika kelas kosong)
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// UserIdentity menghubungkan pengguna dengan akun di identity provider eksternal (SSO)
+type UserIdentity struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
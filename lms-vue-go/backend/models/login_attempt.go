@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// LoginAttemptKind adalah jenis kunci pelacakan login gagal
+type LoginAttemptKind string
+
+const (
+	LoginAttemptUsername LoginAttemptKind = "username"
+	LoginAttemptIP       LoginAttemptKind = "ip"
+)
+
+// LoginAttempt merepresentasikan jumlah login gagal untuk satu username atau IP
+type LoginAttempt struct {
+	Kind          LoginAttemptKind `json:"kind"`
+	Key           string           `json:"key"`
+	Failures      int              `json:"failures"`
+	LastFailureAt time.Time        `json:"last_failure_at"`
+	LockedUntil   *time.Time       `json:"locked_until,omitempty"`
+}
+
+// IsLocked memeriksa apakah kunci sedang dikunci pada waktu tertentu
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// ProctoringEventType adalah jenis kejadian yang dicatat browser selama ujian
+type ProctoringEventType string
+
+const (
+	ProctorTabHidden       ProctoringEventType = "tab_hidden"       // Tab ujian disembunyikan (pindah tab atau aplikasi)
+	ProctorTabVisible      ProctoringEventType = "tab_visible"      // Tab ujian terlihat kembali
+	ProctorFocusLost       ProctoringEventType = "focus_lost"       // Jendela ujian kehilangan fokus
+	ProctorFocusGained     ProctoringEventType = "focus_gained"     // Jendela ujian mendapat fokus kembali
+	ProctorPaste           ProctoringEventType = "paste"            // Teks ditempel ke jawaban
+	ProctorCopy            ProctoringEventType = "copy"             // Teks soal disalin
+	ProctorFullscreenExit  ProctoringEventType = "fullscreen_exit"  // Keluar dari layar penuh
+	ProctorFullscreenEnter ProctoringEventType = "fullscreen_enter" // Masuk ke layar penuh
+)
+
+// ProctoringEventTypes adalah semua jenis kejadian yang diterima
+var ProctoringEventTypes = []ProctoringEventType{
+	ProctorTabHidden, ProctorTabVisible, ProctorFocusLost, ProctorFocusGained,
+	ProctorPaste, ProctorCopy, ProctorFullscreenExit, ProctorFullscreenEnter,
+}
+
+// ProctoringEvent merepresentasikan satu kejadian pengawasan dalam satu sesi ujian siswa
+type ProctoringEvent struct {
+	ID         uint64              `json:"id"`
+	StudentID  uint                `json:"student_id"`
+	SessionID  string              `json:"session_id"` // Dibuat browser saat ujian dimulai
+	Type       ProctoringEventType `json:"type"`
+	QuestionID *uint               `json:"question_id,omitempty"` // Soal yang sedang dikerjakan
+	Length     int                 `json:"length,omitempty"`      // Jumlah karakter yang ditempel atau disalin
+	OccurredAt time.Time           `json:"occurred_at"`           // Waktu menurut browser
+	ReceivedAt time.Time           `json:"received_at"`           // Waktu diterima server
+}
+
+// ProctoringSummary meringkas kejadian pengawasan untuk ditampilkan di samping jawaban
+type ProctoringSummary struct {
+	Events         int     `json:"events"`
+	TabSwitches    int     `json:"tab_switches"`
+	FocusLosses    int     `json:"focus_losses"`
+	Pastes         int     `json:"pastes"`
+	SecondsAway    float64 `json:"seconds_away"`    // Lama tab tersembunyi atau tidak fokus
+	SuspicionScore int     `json:"suspicion_score"` // 0-100
+	Level          string  `json:"level"`           // low, medium atau high
+}
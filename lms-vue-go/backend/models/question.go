@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
 
 // QuestionType adalah tipe untuk jenis soal
 type QuestionType string
@@ -19,8 +23,68 @@ type Question struct {
 	Answer    string       `json:"answer,omitempty"`
 	ImageURL  string       `json:"image_url,omitempty"`
 	Score     int          `json:"score"`
+	Tags      []string     `json:"tags,omitempty"`
+	FileRule  *FileRule    `json:"file_rule,omitempty"` // File yang boleh dilampirkan pada jawaban (hanya esai); nil berarti hanya teks
+	Version   int          `json:"version,omitempty"`   // Nomor revisi saat ini
 	CreatedAt time.Time    `json:"created_at,omitempty"`
 	UpdatedAt time.Time    `json:"updated_at,omitempty"`
+	DeletedAt *time.Time   `json:"deleted_at,omitempty"` // Diisi jika soal ada di tempat sampah
+}
+
+// FileRule mengatur file yang boleh dilampirkan siswa pada jawaban soal
+type FileRule struct {
+	Types     []string `json:"types"`              // Tipe MIME yang diizinkan, misalnya "application/pdf" atau "image/*"
+	MaxSizeMB int      `json:"max_size_mb"`        // Ukuran maksimum per file
+	MaxFiles  int      `json:"max_files"`          // Jumlah file maksimum per jawaban
+	Required  bool     `json:"required,omitempty"` // Jawaban harus menyertakan minimal satu file
+}
+
+// Equal membandingkan dua aturan file; nil hanya sama dengan nil
+func (r *FileRule) Equal(other *FileRule) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return slices.Equal(r.Types, other.Types) && r.MaxSizeMB == other.MaxSizeMB &&
+		r.MaxFiles == other.MaxFiles && r.Required == other.Required
+}
+
+// MaxTagLength adalah panjang maksimum satu tag soal
+const MaxTagLength = 50
+
+// QuestionSearchResult adalah soal hasil pencarian beserta jumlah pemakaian dan sorotan kata kunci
+type QuestionSearchResult struct {
+	Question
+	UsageCount int                 `json:"usage_count"`
+	Relevance  float64             `json:"relevance"`
+	Highlights *QuestionHighlights `json:"highlights,omitempty"`
+}
+
+// QuestionHighlights berisi teks soal, opsi, dan tag dengan kata kunci yang cocok ditandai <mark>
+type QuestionHighlights struct {
+	Question string   `json:"question"`
+	Options  []string `json:"options,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// NormalizeTags merapikan tag soal: huruf kecil, tanpa spasi di tepi, tanpa duplikat dan tag kosong
+func NormalizeTags(tags []string) []string {
+	var normalized []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// SearchText menggabungkan teks soal, opsi, dan tag untuk indeks pencarian
+func (q *Question) SearchText() string {
+	parts := append([]string{q.Question}, q.Options...)
+	return strings.Join(append(parts, q.Tags...), "\n")
 }
 
 // HideAnswer menghapus jawaban dari soal untuk keamanan
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// QuestionVersion adalah revisi soal yang tidak pernah diubah. Setiap perubahan soal
+// menyimpan revisi baru, sehingga teks yang dilihat siswa saat menjawab tetap tersimpan.
+type QuestionVersion struct {
+	ID         uint         `json:"id"`
+	QuestionID uint         `json:"question_id"`
+	Version    int          `json:"version"`
+	Type       QuestionType `json:"type"`
+	Question   string       `json:"question"`
+	Options    []string     `json:"options,omitempty"`
+	Answer     string       `json:"answer,omitempty"`
+	ImageURL   string       `json:"image_url,omitempty"`
+	Score      int          `json:"score"`
+	Tags       []string     `json:"tags,omitempty"`
+	FileRule   *FileRule    `json:"file_rule,omitempty"`
+	CreatedBy  *uint        `json:"created_by,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// HideAnswer menghapus kunci jawaban dari revisi untuk keamanan
+func (v *QuestionVersion) HideAnswer() {
+	v.Answer = ""
+}
+
+// ToQuestion mengembalikan isi revisi sebagai soal, misalnya untuk memulihkan revisi lama
+func (v *QuestionVersion) ToQuestion() Question {
+	return Question{
+		ID:       v.QuestionID,
+		Type:     v.Type,
+		Question: v.Question,
+		Options:  v.Options,
+		Answer:   v.Answer,
+		ImageURL: v.ImageURL,
+		Score:    v.Score,
+		Tags:     v.Tags,
+		FileRule: v.FileRule,
+		Version:  v.Version,
+	}
+}
+
+// FieldChange adalah perubahan satu field di antara dua revisi soal.
+// Untuk field berupa daftar (options, tags), Added dan Removed berisi item yang berbeda.
+type FieldChange struct {
+	Field   string      `json:"field"`
+	From    interface{} `json:"from"`
+	To      interface{} `json:"to"`
+	Added   []string    `json:"added,omitempty"`
+	Removed []string    `json:"removed,omitempty"`
+}
+
+// DiffQuestionVersions membandingkan dua revisi dan mengembalikan field yang berubah
+func DiffQuestionVersions(from, to *QuestionVersion) []FieldChange {
+	changes := []FieldChange{}
+
+	if from.Type != to.Type {
+		changes = append(changes, FieldChange{Field: "type", From: from.Type, To: to.Type})
+	}
+	if from.Question != to.Question {
+		changes = append(changes, FieldChange{Field: "question", From: from.Question, To: to.Question})
+	}
+	if change, changed := diffList("options", from.Options, to.Options); changed {
+		changes = append(changes, change)
+	}
+	if from.Answer != to.Answer {
+		changes = append(changes, FieldChange{Field: "answer", From: from.Answer, To: to.Answer})
+	}
+	if from.ImageURL != to.ImageURL {
+		changes = append(changes, FieldChange{Field: "image_url", From: from.ImageURL, To: to.ImageURL})
+	}
+	if from.Score != to.Score {
+		changes = append(changes, FieldChange{Field: "score", From: from.Score, To: to.Score})
+	}
+	if change, changed := diffList("tags", from.Tags, to.Tags); changed {
+		changes = append(changes, change)
+	}
+	if !from.FileRule.Equal(to.FileRule) {
+		changes = append(changes, FieldChange{Field: "file_rule", From: from.FileRule, To: to.FileRule})
+	}
+
+	return changes
+}
+
+// diffList membandingkan dua daftar. Urutan diperhitungkan untuk menentukan perubahan,
+// karena urutan opsi menentukan huruf kunci jawaban.
+func diffList(field string, from, to []string) (FieldChange, bool) {
+	if slices.Equal(from, to) {
+		return FieldChange{}, false
+	}
+
+	change := FieldChange{Field: field, From: from, To: to}
+	counts := map[string]int{}
+	for _, item := range from {
+		counts[item]++
+	}
+	for _, item := range to {
+		if counts[item] > 0 {
+			counts[item]--
+			continue
+		}
+		change.Added = append(change.Added, item)
+	}
+	for _, item := range from {
+		if counts[item] > 0 {
+			counts[item]--
+			change.Removed = append(change.Removed, item)
+		}
+	}
+
+	return change, true
+}
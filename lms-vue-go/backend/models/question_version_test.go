@@ -0,0 +1,43 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffQuestionVersions(t *testing.T) {
+	from := &QuestionVersion{
+		Type:     MultipleChoice,
+		Question: "Ibukota Indonesia adalah...",
+		Options:  []string{"Jakarta", "Bandung", "Surabaya"},
+		Answer:   "A",
+		Score:    1,
+	}
+	to := &QuestionVersion{
+		Type:     MultipleChoice,
+		Question: "Ibukota negara Indonesia adalah...",
+		Options:  []string{"Bandung", "Jakarta", "Medan"},
+		Answer:   "B",
+		Score:    1,
+		Tags:     []string{"geografi"},
+	}
+
+	changes := DiffQuestionVersions(from, to)
+
+	var fields []string
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	if want := []string{"question", "options", "answer", "tags"}; !reflect.DeepEqual(fields, want) {
+		t.Fatalf("changed fields = %v, want %v", fields, want)
+	}
+
+	options := changes[1]
+	if !reflect.DeepEqual(options.Added, []string{"Medan"}) || !reflect.DeepEqual(options.Removed, []string{"Surabaya"}) {
+		t.Errorf("options added %v removed %v", options.Added, options.Removed)
+	}
+
+	if changes := DiffQuestionVersions(from, from); len(changes) != 0 {
+		t.Errorf("diff of identical versions = %v, want none", changes)
+	}
+}
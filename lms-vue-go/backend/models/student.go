@@ -4,39 +4,47 @@ import "time"
 
 // Student merepresentasikan data siswa
 type Student struct {
-	ID        uint      `json:"id"`
-	UserID    uint      `json:"user_id,omitempty"`
-	Name      string    `json:"name"`
-	Class     string    `json:"class"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at,omitempty"`
-	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"user_id,omitempty"`
+	Name      string     `json:"name"`
+	Class     string     `json:"class"`
+	Email     string     `json:"email"`
+	CreatedAt time.Time  `json:"created_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // Diisi jika siswa ada di tempat sampah
 }
 
 // StudentAnswer merepresentasikan jawaban siswa untuk soal
 type StudentAnswer struct {
-	ID         uint      `json:"id"`
-	StudentID  uint      `json:"student_id"`
-	QuestionID uint      `json:"question_id"`
-	Answer     string    `json:"answer"`
-	Score      *int      `json:"score,omitempty"`
-	CreatedAt  time.Time `json:"created_at,omitempty"`
-	UpdatedAt  time.Time `json:"updated_at,omitempty"`
+	ID              uint      `json:"id"`
+	StudentID       uint      `json:"student_id"`
+	QuestionID      uint      `json:"question_id"`
+	Answer          string    `json:"answer"`
+	Score           *int      `json:"score,omitempty"`
+	Feedback        string    `json:"feedback,omitempty"`         // Catatan guru saat menilai
+	QuestionVersion int       `json:"question_version,omitempty"` // Revisi soal yang dijawab siswa
+	Files           []Upload  `json:"files,omitempty"`            // File yang dilampirkan pada jawaban
+	CreatedAt       time.Time `json:"created_at,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty"`
 }
 
 // StudentAnswerWithDetails merepresentasikan jawaban siswa dengan detail siswa dan soal
 type StudentAnswerWithDetails struct {
-	ID            uint         `json:"id"`
-	StudentID     uint         `json:"student_id"`
-	QuestionID    uint         `json:"question_id"`
-	Answer        string       `json:"answer"`
-	Score         *int         `json:"score,omitempty"`
-	StudentName   string       `json:"student_name"`
-	StudentClass  string       `json:"student_class"`
-	UserID        uint         `json:"user_id"`
-	QuestionText  string       `json:"question_text"`
-	QuestionType  QuestionType `json:"question_type"`
-	QuestionScore int          `json:"question_score"`
-	CreatedAt     time.Time    `json:"created_at,omitempty"`
-	UpdatedAt     time.Time    `json:"updated_at,omitempty"`
+	ID              uint               `json:"id"`
+	StudentID       uint               `json:"student_id"`
+	QuestionID      uint               `json:"question_id"`
+	Answer          string             `json:"answer"`
+	Score           *int               `json:"score,omitempty"`
+	Feedback        string             `json:"feedback,omitempty"` // Catatan guru saat menilai
+	StudentName     string             `json:"student_name"`
+	StudentClass    string             `json:"student_class"`
+	UserID          uint               `json:"user_id"`
+	QuestionText    string             `json:"question_text"`
+	QuestionType    QuestionType       `json:"question_type"`
+	QuestionScore   int                `json:"question_score"`
+	QuestionVersion int                `json:"question_version,omitempty"` // Revisi yang dijawab; teks dan skor soal dari revisi ini
+	Files           []Upload           `json:"files,omitempty"`            // File yang dilampirkan pada jawaban
+	Proctoring      *ProctoringSummary `json:"proctoring,omitempty"`       // Kejadian pengawasan saat soal ini dikerjakan
+	CreatedAt       time.Time          `json:"created_at,omitempty"`
+	UpdatedAt       time.Time          `json:"updated_at,omitempty"`
 }
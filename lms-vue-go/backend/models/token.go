@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// TokenPurpose adalah tujuan penggunaan token sekali pakai
+type TokenPurpose string
+
+const (
+	TokenEmailVerification TokenPurpose = "email_verification"
+	TokenPasswordReset     TokenPurpose = "password_reset"
+)
+
+// UserToken merepresentasikan token sekali pakai milik pengguna.
+// Nilai token asli tidak pernah disimpan, hanya tanda tangan HMAC-nya.
+type UserToken struct {
+	ID        uint         `json:"id"`
+	UserID    uint         `json:"user_id"`
+	Purpose   TokenPurpose `json:"purpose"`
+	TokenHash string       `json:"-"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    *time.Time   `json:"used_at,omitempty"`
+	CreatedAt time.Time    `json:"created_at,omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// UserTOTP merepresentasikan pengaturan TOTP (2FA) milik pengguna
+type UserTOTP struct {
+	UserID       uint       `json:"user_id"`
+	Secret       string     `json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"` // Nil selama pendaftaran belum dikonfirmasi
+	LastUsedStep int64      `json:"-"`                    // Mencegah kode yang sama dipakai dua kali
+	CreatedAt    time.Time  `json:"created_at,omitempty"`
+}
+
+// IsEnabled memeriksa apakah 2FA sudah aktif
+func (t *UserTOTP) IsEnabled() bool {
+	return t != nil && t.EnabledAt != nil
+}
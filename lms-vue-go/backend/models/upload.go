@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// UploadPurpose menentukan kegunaan file yang diunggah
+type UploadPurpose string
+
+const (
+	// UploadQuestionImage adalah gambar soal; dapat dibuka tanpa login seperti soal itu sendiri
+	UploadQuestionImage UploadPurpose = "question_image"
+	// UploadAttachment adalah lampiran pribadi, hanya dapat dibuka melalui link bertanda tangan
+	UploadAttachment UploadPurpose = "attachment"
+	// UploadAnswerFile adalah file jawaban siswa; hanya dapat dibuka melalui link bertanda tangan
+	UploadAnswerFile UploadPurpose = "answer_file"
+)
+
+// Upload merepresentasikan file yang diunggah
+type Upload struct {
+	ID          uint          `json:"id"`
+	StorageKey  string        `json:"-"` // Kunci objek di blob store
+	Filename    string        `json:"filename"`
+	ContentType string        `json:"content_type"` // Dideteksi dari isi file
+	Size        int64         `json:"size"`
+	Purpose     UploadPurpose `json:"purpose"`
+	UploadedBy  uint          `json:"uploaded_by,omitempty"`
+	URL         string        `json:"url,omitempty"` // Link untuk membuka file
+	CreatedAt   time.Time     `json:"created_at,omitempty"`
+}
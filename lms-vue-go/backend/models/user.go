@@ -13,29 +13,40 @@ const (
 
 // User merepresentasikan pengguna sistem
 type User struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Password  string    `json:"-"` // Password tidak akan dimasukkan dalam JSON response
-	Email     string    `json:"email"`
-	Role      Role      `json:"role"`
-	CreatedAt time.Time `json:"created_at,omitempty"`
-	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	ID              uint       `json:"id"`
+	Username        string     `json:"username"`
+	Password        string     `json:"-"` // Password tidak akan dimasukkan dalam JSON response
+	Email           string     `json:"email"`
+	Role            Role       `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"` // Diisi saat email dikonfirmasi
+	IsActive        bool       `json:"is_active"`                   // Akun nonaktif tidak dapat login
+	CreatedAt       time.Time  `json:"created_at,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at,omitempty"`
 }
 
 // UserResponse adalah struktur untuk response data user tanpa password
 type UserResponse struct {
-	ID       uint   `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Role     Role   `json:"role"`
+	ID            uint   `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	Role          Role   `json:"role"`
+	EmailVerified bool   `json:"email_verified"`
+	IsActive      bool   `json:"is_active"`
 }
 
 // ToResponse mengkonversi User ke UserResponse (tanpa password)
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:       u.ID,
-		Username: u.Username,
-		Email:    u.Email,
-		Role:     u.Role,
+		ID:            u.ID,
+		Username:      u.Username,
+		Email:         u.Email,
+		Role:          u.Role,
+		EmailVerified: u.EmailVerifiedAt != nil,
+		IsActive:      u.IsActive,
 	}
 }
+
+// IsValidRole memeriksa apakah role dikenal oleh sistem
+func IsValidRole(role Role) bool {
+	return role == RoleAdmin || role == RoleTeacher || role == RoleStudent
+}
@@ -0,0 +1,58 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestBytesXref(t *testing.T) {
+	doc := New("Rapor (Budi)")
+	doc.AddPage().Text(50, 50, Helvetica, 12, `Nilai: 90 \ (A)`)
+	doc.AddPage().Text(50, 50, HelveticaBold, 12, "Halaman 2 – é")
+	data := doc.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	if !bytes.Contains(data, []byte(`(Nilai: 90 \\ \(A\)) Tj`)) {
+		t.Error("text was not escaped")
+	}
+	if !bytes.Contains(data, []byte("/Count 2")) {
+		t.Error("page count is wrong")
+	}
+
+	// Every xref entry must point at the start of its object
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(data)
+	xref, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllSubmatch(data[xref:], -1)
+	if len(entries) != 9 {
+		t.Fatalf("got %d xref entries, want 9", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d does not point at %q", i+1, want)
+		}
+	}
+}
+
+func TestTruncateAndWrap(t *testing.T) {
+	long := "Penjelasan yang sangat panjang sekali untuk satu baris"
+	got := Truncate(Helvetica, 10, long, 100)
+	if TextWidth(Helvetica, 10, got) > 100 || got[len(got)-3:] != "..." {
+		t.Errorf("Truncate = %q", got)
+	}
+
+	lines := Wrap(Helvetica, 10, long, 100)
+	if len(lines) < 3 {
+		t.Errorf("Wrap = %q", lines)
+	}
+	for _, line := range lines {
+		if TextWidth(Helvetica, 10, line) > 100 {
+			t.Errorf("line %q is wider than 100", line)
+		}
+	}
+}
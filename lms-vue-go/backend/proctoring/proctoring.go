@@ -0,0 +1,190 @@
+// Package proctoring turns the events a browser reports during an exam attempt
+// (tab switches, focus changes, pastes) into a timeline and a suspicion score.
+// The events come from the client and can be suppressed by a determined
+// student, so the score is evidence for a teacher to review, not a verdict.
+package proctoring
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"lms-vue-go/backend/models"
+)
+
+// Weights of the suspicion score, in points out of 100
+const (
+	TabSwitchPoints      = 5.0 // Per time the exam tab was hidden
+	FocusLossPoints      = 3.0 // Per time the window lost focus without the tab being hidden, e.g. to a window beside it
+	PastePoints          = 5.0
+	LargePastePoints     = 5.0 // Extra for pasting at least LargePaste characters
+	CopyPoints           = 2.0
+	FullscreenExitPoints = 3.0
+	// AwayPointsPerSecond is added for the time spent away, up to MaxAwayPoints per absence
+	AwayPointsPerSecond = 0.1
+	MaxAwayPoints       = 10.0
+	// LargePaste is the length from which a paste is likely a whole answer
+	LargePaste = 100
+)
+
+// Suspicion levels and their lower limits
+const (
+	LevelLow    = "low"
+	LevelMedium = "medium"
+	LevelHigh   = "high"
+
+	MediumScore = 20
+	HighScore   = 50
+)
+
+// Entry is one event of the timeline with the points it added to the score
+type Entry struct {
+	models.ProctoringEvent
+	Points float64 `json:"points"`
+	// AwaySeconds is set on the event that ended an absence: the time since the
+	// tab was hidden or the window lost focus
+	AwaySeconds *float64 `json:"away_seconds,omitempty"`
+}
+
+// Report is the analysis of one exam attempt
+type Report struct {
+	SessionID string                   `json:"session_id"`
+	StudentID uint                     `json:"student_id"`
+	StartedAt time.Time                `json:"started_at"` // First event
+	EndedAt   time.Time                `json:"ended_at"`   // Last event
+	Summary   models.ProctoringSummary `json:"summary"`
+	Timeline  []Entry                  `json:"timeline"`
+}
+
+// Analyze builds the report of the events of one attempt. Events are ordered by
+// the time the browser reported. An absence starts when the tab is hidden or the
+// window loses focus and ends when the tab is visible and focused again; the
+// blur that accompanies hiding the tab is not counted separately.
+func Analyze(events []models.ProctoringEvent) *Report {
+	sorted := append([]models.ProctoringEvent{}, events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].OccurredAt.Equal(sorted[j].OccurredAt) {
+			return sorted[i].OccurredAt.Before(sorted[j].OccurredAt)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	report := &Report{Timeline: make([]Entry, len(sorted))}
+	if len(sorted) > 0 {
+		report.SessionID = sorted[0].SessionID
+		report.StudentID = sorted[0].StudentID
+		report.StartedAt = sorted[0].OccurredAt
+		report.EndedAt = sorted[len(sorted)-1].OccurredAt
+	}
+
+	var hidden, unfocused, tabHiddenInAbsence bool
+	absenceStart := -1 // Index of the event that started the current absence
+	var total float64
+	summary := &report.Summary
+
+	for i, event := range sorted {
+		entry := &report.Timeline[i]
+		entry.ProctoringEvent = event
+		wasAway := hidden || unfocused
+
+		switch event.Type {
+		case models.ProctorTabHidden:
+			if !hidden {
+				hidden = true
+				tabHiddenInAbsence = true
+				summary.TabSwitches++
+				entry.Points = TabSwitchPoints
+			}
+		case models.ProctorTabVisible:
+			hidden = false
+		case models.ProctorFocusLost:
+			unfocused = true
+		case models.ProctorFocusGained:
+			unfocused = false
+		case models.ProctorPaste:
+			summary.Pastes++
+			entry.Points = PastePoints
+			if event.Length >= LargePaste {
+				entry.Points += LargePastePoints
+			}
+		case models.ProctorCopy:
+			entry.Points = CopyPoints
+		case models.ProctorFullscreenExit:
+			entry.Points = FullscreenExitPoints
+		}
+
+		away := hidden || unfocused
+		switch {
+		case !wasAway && away:
+			absenceStart = i
+			tabHiddenInAbsence = event.Type == models.ProctorTabHidden
+		case wasAway && !away:
+			seconds := event.OccurredAt.Sub(sorted[absenceStart].OccurredAt).Seconds()
+			seconds = math.Round(seconds*10) / 10
+			entry.AwaySeconds = &seconds
+			entry.Points += math.Min(seconds*AwayPointsPerSecond, MaxAwayPoints)
+			summary.SecondsAway += seconds
+			if !tabHiddenInAbsence {
+				summary.FocusLosses++
+				report.Timeline[absenceStart].Points += FocusLossPoints
+			}
+		}
+	}
+	// An absence still open at the last event counts as a focus loss without a known duration
+	if (hidden || unfocused) && !tabHiddenInAbsence {
+		summary.FocusLosses++
+		report.Timeline[absenceStart].Points += FocusLossPoints
+	}
+
+	for i := range report.Timeline {
+		report.Timeline[i].Points = math.Round(report.Timeline[i].Points*10) / 10
+		total += report.Timeline[i].Points
+	}
+	summary.Events = len(sorted)
+	summary.SecondsAway = math.Round(summary.SecondsAway*10) / 10
+	summary.SuspicionScore, summary.Level = Score(total)
+	return report
+}
+
+// Summarize returns the combined summary of events from one or more attempts. Every
+// attempt is analyzed on its own; the counts and points are added up.
+func Summarize(events []models.ProctoringEvent) models.ProctoringSummary {
+	var sessions []string
+	bySession := map[string][]models.ProctoringEvent{}
+	for _, e := range events {
+		if _, ok := bySession[e.SessionID]; !ok {
+			sessions = append(sessions, e.SessionID)
+		}
+		bySession[e.SessionID] = append(bySession[e.SessionID], e)
+	}
+
+	var summary models.ProctoringSummary
+	var points float64
+	for _, session := range sessions {
+		report := Analyze(bySession[session])
+		summary.Events += report.Summary.Events
+		summary.TabSwitches += report.Summary.TabSwitches
+		summary.FocusLosses += report.Summary.FocusLosses
+		summary.Pastes += report.Summary.Pastes
+		summary.SecondsAway += report.Summary.SecondsAway
+		for _, entry := range report.Timeline {
+			points += entry.Points
+		}
+	}
+	summary.SecondsAway = math.Round(summary.SecondsAway*10) / 10
+	summary.SuspicionScore, summary.Level = Score(points)
+	return summary
+}
+
+// Score turns the points of an attempt into a score from 0 to 100 and its level
+func Score(points float64) (int, string) {
+	score := int(math.Round(math.Min(points, 100)))
+	switch {
+	case score >= HighScore:
+		return score, LevelHigh
+	case score >= MediumScore:
+		return score, LevelMedium
+	default:
+		return score, LevelLow
+	}
+}
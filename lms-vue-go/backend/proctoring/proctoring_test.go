@@ -0,0 +1,66 @@
+package proctoring
+
+import (
+	"testing"
+	"time"
+
+	"lms-vue-go/backend/models"
+)
+
+func TestAnalyze(t *testing.T) {
+	start := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	at := func(seconds float64) time.Time { return start.Add(time.Duration(seconds * float64(time.Second))) }
+	events := []models.ProctoringEvent{
+		// Switching tabs blurs the window first; it is one tab switch of 30 seconds
+		{ID: 1, Type: models.ProctorFocusLost, OccurredAt: at(10)},
+		{ID: 2, Type: models.ProctorTabHidden, OccurredAt: at(10.01)},
+		{ID: 4, Type: models.ProctorFocusGained, OccurredAt: at(40.01)},
+		{ID: 3, Type: models.ProctorTabVisible, OccurredAt: at(40)},
+		// A window beside the exam takes focus for 5 seconds
+		{ID: 5, Type: models.ProctorFocusLost, OccurredAt: at(60)},
+		{ID: 6, Type: models.ProctorFocusGained, OccurredAt: at(65)},
+		{ID: 7, Type: models.ProctorPaste, Length: 250, OccurredAt: at(66)},
+		// Still away when the attempt ends
+		{ID: 8, Type: models.ProctorFocusLost, OccurredAt: at(90)},
+	}
+
+	report := Analyze(events)
+	s := report.Summary
+	if s.Events != 8 || s.TabSwitches != 1 || s.FocusLosses != 2 || s.Pastes != 1 || s.SecondsAway != 35 {
+		t.Fatalf("summary = %+v", s)
+	}
+	// Tab switch 5 + 3 away, focus loss 3 + 0.5 away, large paste 10, open focus loss 3
+	if s.SuspicionScore != 25 || s.Level != LevelMedium {
+		t.Errorf("score = %d %s, want 25 medium", s.SuspicionScore, s.Level)
+	}
+	if e := report.Timeline[3]; e.ID != 4 || e.AwaySeconds == nil || *e.AwaySeconds != 30 {
+		t.Errorf("timeline[3] = %+v", e)
+	}
+	if !report.StartedAt.Equal(at(10)) || !report.EndedAt.Equal(at(90)) {
+		t.Errorf("range = %v - %v", report.StartedAt, report.EndedAt)
+	}
+}
+
+func TestSummarizeSeparatesSessions(t *testing.T) {
+	start := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	events := []models.ProctoringEvent{
+		{ID: 1, SessionID: "a", Type: models.ProctorTabHidden, OccurredAt: start},
+		{ID: 2, SessionID: "b", Type: models.ProctorTabVisible, OccurredAt: start.Add(time.Hour)},
+		{ID: 3, SessionID: "b", Type: models.ProctorPaste, Length: 10, OccurredAt: start.Add(time.Hour)},
+	}
+
+	// The tab hidden in attempt a is not ended by the event of attempt b
+	s := Summarize(events)
+	if s.Events != 3 || s.TabSwitches != 1 || s.SecondsAway != 0 || s.SuspicionScore != 10 || s.Level != LevelLow {
+		t.Errorf("summary = %+v", s)
+	}
+}
+
+func TestScore(t *testing.T) {
+	if score, level := Score(250); score != 100 || level != LevelHigh {
+		t.Errorf("Score(250) = %d %s", score, level)
+	}
+	if score, level := Score(0); score != 0 || level != LevelLow {
+		t.Errorf("Score(0) = %d %s", score, level)
+	}
+}
@@ -0,0 +1,94 @@
+package questionio
+
+import (
+	"reflect"
+	"testing"
+
+	"lms-vue-go/backend/models"
+)
+
+func sampleQuestions() []models.Question {
+	return []models.Question{
+		{
+			ID:       7,
+			Type:     models.MultipleChoice,
+			Question: "Hasil dari 2 < 3 & 4 > 1 adalah\nPilih satu.",
+			Options:  []string{"true", `"false"`, "<b>error</b>"},
+			Answer:   "C",
+			ImageURL: "https://cdn.example.com/soal/7.png?w=200&h=100",
+			Score:    5,
+			Tags:     []string{"logika", "kelas 10"},
+		},
+		{
+			ID:       8,
+			Type:     models.Essay,
+			Question: "Jelaskan hukum Newton.",
+			Answer:   "Inersia\nF = m × a\nAksi-reaksi",
+			Score:    20,
+			FileRule: &models.FileRule{Types: []string{"application/pdf", "image/*"}, MaxSizeMB: 5, MaxFiles: 2, Required: true},
+		},
+		{
+			ID:       9,
+			Type:     models.MultipleChoice,
+			Question: "Bumi itu bulat.",
+			Options:  []string{"True", "False"},
+			Answer:   "A",
+			Score:    1,
+			Tags:     []string{"geografi"},
+		},
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatMoodleXML, FormatQTI} {
+		data, err := Export(format, sampleQuestions())
+		if err != nil {
+			t.Fatalf("%s: Export: %v", format, err)
+		}
+		detected, err := DetectFormat("export"+format.Extension(), data)
+		if err != nil || detected != format {
+			t.Errorf("%s: DetectFormat = %q, %v", format, detected, err)
+		}
+
+		result, err := Import(format, data)
+		if err != nil {
+			t.Fatalf("%s: Import: %v", format, err)
+		}
+		if len(result.Issues) > 0 {
+			t.Errorf("%s: unexpected issues %+v", format, result.Issues)
+		}
+
+		// Only the native format stores file rules; the others report that they drop them
+		want := sampleQuestions()
+		issues := ExportIssues(format, want)
+		if format == FormatJSON {
+			if len(issues) != 0 {
+				t.Errorf("%s: unexpected export issues %+v", format, issues)
+			}
+		} else {
+			if len(issues) != 1 || issues[0].Item != 2 || issues[0].Name != "8" {
+				t.Errorf("%s: export issues = %+v, want the file rule of question 8", format, issues)
+			}
+			want[1].FileRule = nil
+		}
+
+		if len(result.Questions) != len(want) {
+			t.Fatalf("%s: got %d questions, want %d", format, len(result.Questions), len(want))
+		}
+		for i, got := range result.Questions {
+			want[i].ID = 0
+			if !reflect.DeepEqual(got, want[i]) {
+				t.Errorf("%s: question %d\n got %+v\nwant %+v", format, i+1, got, want[i])
+			}
+		}
+	}
+}
+
+func TestImportJSONRejectsUnknownVersion(t *testing.T) {
+	if _, err := ImportJSON([]byte(`{"format":"lms-questions","version":2,"questions":[]}`)); err == nil {
+		t.Error("ImportJSON should reject version 2")
+	}
+	if _, err := ImportJSON([]byte(`{"format":"other","version":1}`)); err == nil {
+		t.Error("ImportJSON should reject another format")
+	}
+}
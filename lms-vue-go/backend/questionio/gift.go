@@ -0,0 +1,311 @@
+package questionio
+
+import (
+	"regexp"
+	"strings"
+
+	"lms-vue-go/backend/models"
+)
+
+// giftBlank replaces the answer block of a "missing word" GIFT question inside the text
+const giftBlank = "_____"
+
+// giftWeight matches a "%50%" answer weight
+var giftWeight = regexp.MustCompile(`^%(-?[0-9.]+)%`)
+
+// giftFormat matches a "[html]" text format prefix
+var giftFormat = regexp.MustCompile(`^\[(html|moodle|plain|markdown)\]`)
+
+// ImportGIFT parses questions in the Moodle GIFT text format
+func ImportGIFT(data []byte) (*Result, error) {
+	result := &Result{}
+	var categoryTagList []string
+	item := 0
+
+	for _, block := range giftBlocks(string(data)) {
+		if strings.HasPrefix(block, "$CATEGORY:") {
+			categoryTagList = categoryTags(strings.TrimSpace(strings.TrimPrefix(block, "$CATEGORY:")))
+			continue
+		}
+		item++
+
+		name, body := giftTitle(block)
+		open, close := giftAnswerBlock(body)
+		if open < 0 {
+			result.skip(item, name, "no answer block {...} found")
+			continue
+		}
+
+		before := strings.TrimSpace(body[:open])
+		after := strings.TrimSpace(body[close+1:])
+		answers := strings.TrimSpace(body[open+1 : close])
+
+		format := ""
+		if m := giftFormat.FindStringSubmatch(before); m != nil {
+			format = m[1]
+			before = strings.TrimSpace(before[len(m[0]):])
+		}
+
+		text := giftUnescape(before)
+		if after != "" {
+			text += " " + giftBlank + " " + giftUnescape(after)
+		}
+
+		var images []string
+		if format == "html" || format == "" && strings.Contains(text, "<") {
+			text, images = htmlToText(text)
+		} else {
+			text = cleanText(text)
+		}
+
+		q := models.Question{
+			Question: text,
+			Score:    1,
+			Tags:     append([]string(nil), categoryTagList...),
+		}
+		q.ImageURL = pickImage(result, item, name, "", images)
+
+		if !giftAnswers(result, item, name, answers, &q) {
+			continue
+		}
+		result.addQuestion(item, name, q)
+	}
+
+	return result, nil
+}
+
+// giftAnswers interprets the content of the answer block
+func giftAnswers(result *Result, item int, name, answers string, q *models.Question) bool {
+	answers = stripGeneralFeedback(answers)
+
+	if strings.HasPrefix(answers, "#") {
+		q.Type = models.Essay
+		q.Answer = giftUnescape(cutFeedback(strings.TrimPrefix(answers, "#")))
+		result.warn(item, name, "numerical question imported as essay; the answer is kept as the answer key but is not graded automatically")
+		return true
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(cutFeedback(answers))) {
+	case "":
+		q.Type = models.Essay
+		return true
+	case "T", "TRUE":
+		q.Type = models.MultipleChoice
+		q.Options = []string{"True", "False"}
+		q.Answer = "A"
+		return true
+	case "F", "FALSE":
+		q.Type = models.MultipleChoice
+		q.Options = []string{"True", "False"}
+		q.Answer = "B"
+		return true
+	}
+
+	choices := giftChoices(answers)
+	if len(choices) == 0 {
+		result.skip(item, name, "answer block could not be parsed")
+		return false
+	}
+
+	wrong := 0
+	for _, choice := range choices {
+		if strings.Contains(choice.text, "->") {
+			result.skip(item, name, "matching questions are not supported")
+			return false
+		}
+		if !choice.correct {
+			wrong++
+		}
+	}
+
+	// Only "=" answers: a short answer question
+	if wrong == 0 {
+		q.Type = models.Essay
+		var accepted []string
+		for _, choice := range choices {
+			accepted = append(accepted, choice.text)
+		}
+		q.Answer = strings.Join(accepted, "\n")
+		result.warn(item, name, "short answer question imported as essay; accepted answers are kept as the answer key but are not graded automatically")
+		return true
+	}
+
+	q.Type = models.MultipleChoice
+	best, bestWeight, positive := -1, 0.0, 0
+	for i, choice := range choices {
+		q.Options = append(q.Options, choice.text)
+		if choice.weight > 0 {
+			positive++
+		}
+		if choice.weight > 0 && choice.weight < 100 {
+			result.warn(item, name, "partial credit (%g%%) for option %s was dropped", choice.weight, optionLetter(i))
+		}
+		if choice.weight > bestWeight {
+			best, bestWeight = i, choice.weight
+		}
+	}
+
+	if positive > 1 {
+		result.skip(item, name, "multiple-response questions (more than one correct option) are not supported")
+		return false
+	}
+	if best >= 0 {
+		q.Answer = optionLetter(best)
+	}
+	return true
+}
+
+// giftChoice is one "=" or "~" answer
+type giftChoice struct {
+	text    string
+	correct bool
+	weight  float64
+}
+
+// giftChoices splits an answer block into its "=" and "~" answers
+func giftChoices(answers string) []giftChoice {
+	var choices []giftChoice
+	for _, part := range splitUnescaped(answers, "=~") {
+		if part == "" {
+			continue
+		}
+		marker, rest := part[0], strings.TrimSpace(part[1:])
+		choice := giftChoice{correct: marker == '='}
+		if choice.correct {
+			choice.weight = 100
+		}
+		if m := giftWeight.FindStringSubmatch(rest); m != nil {
+			choice.weight = parseFloat(m[1], 0)
+			choice.correct = choice.weight > 0
+			rest = strings.TrimSpace(rest[len(m[0]):])
+		}
+		choice.text = cleanText(giftUnescape(cutFeedback(rest)))
+		choices = append(choices, choice)
+	}
+	return choices
+}
+
+// giftBlocks splits a GIFT file into questions separated by blank lines, dropping comments
+func giftBlocks(data string) []string {
+	var blocks []string
+	var current []string
+
+	flush := func() {
+		if block := strings.TrimSpace(strings.Join(current, "\n")); block != "" {
+			blocks = append(blocks, block)
+		}
+		current = nil
+	}
+
+	data = strings.TrimPrefix(data, "\ufeff")
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "//"):
+			continue
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "$CATEGORY:"):
+			flush()
+			blocks = append(blocks, trimmed)
+		default:
+			current = append(current, line)
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// giftTitle separates a "::title::" prefix from the question
+func giftTitle(block string) (string, string) {
+	if !strings.HasPrefix(block, "::") {
+		return "", block
+	}
+	end := indexUnescaped(block[2:], "::")
+	if end < 0 {
+		return "", block
+	}
+	return giftUnescape(strings.TrimSpace(block[2 : 2+end])), strings.TrimSpace(block[2+end+2:])
+}
+
+// giftAnswerBlock returns the positions of the unescaped braces around the answers
+func giftAnswerBlock(body string) (int, int) {
+	open := indexUnescaped(body, "{")
+	if open < 0 {
+		return -1, -1
+	}
+	close := indexUnescaped(body[open+1:], "}")
+	if close < 0 {
+		return -1, -1
+	}
+	return open, open + 1 + close
+}
+
+// stripGeneralFeedback removes "####general feedback" at the end of an answer block
+func stripGeneralFeedback(answers string) string {
+	if i := indexUnescaped(answers, "####"); i >= 0 {
+		return strings.TrimSpace(answers[:i])
+	}
+	return answers
+}
+
+// cutFeedback removes "#feedback" after an answer
+func cutFeedback(answer string) string {
+	if i := indexUnescaped(answer, "#"); i >= 0 {
+		return strings.TrimSpace(answer[:i])
+	}
+	return strings.TrimSpace(answer)
+}
+
+// indexUnescaped returns the index of the first occurrence of sep not preceded by a backslash
+func indexUnescaped(s, sep string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sep) {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitUnescaped splits s before every unescaped character in markers, keeping the marker
+func splitUnescaped(s, markers string) []string {
+	var parts []string
+	start := -1
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte(markers, s[i]) >= 0 {
+			if start >= 0 {
+				parts = append(parts, s[start:i])
+			}
+			start = i
+		}
+	}
+	if start >= 0 {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
+
+// giftUnescape resolves GIFT escapes such as "\=" and "\n"
+func giftUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
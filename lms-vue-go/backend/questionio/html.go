@@ -0,0 +1,114 @@
+package questionio
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// blockElements start a new line when converting HTML to text
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "pre": true, "blockquote": true,
+}
+
+// htmlToText converts an HTML fragment to plain text and returns the sources of its images
+func htmlToText(fragment string) (string, []string) {
+	if !strings.ContainsAny(fragment, "<&") {
+		return cleanText(fragment), nil
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return cleanText(fragment), nil
+	}
+
+	var b strings.Builder
+	var images []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+		case html.ElementNode:
+			if n.Data == "img" {
+				for _, attr := range n.Attr {
+					if attr.Key == "src" && attr.Val != "" {
+						images = append(images, attr.Val)
+					}
+				}
+			}
+			if n.Data == "script" || n.Data == "style" {
+				return
+			}
+			if blockElements[n.Data] {
+				b.WriteString("\n")
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if n.Type == html.ElementNode && blockElements[n.Data] {
+			b.WriteString("\n")
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	return cleanText(b.String()), images
+}
+
+// cleanText collapses runs of spaces inside lines and removes empty lines
+func cleanText(s string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isRemoteURL reports whether an image source can be stored as an image URL
+func isRemoteURL(src string) bool {
+	lower := strings.ToLower(src)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// pickImage chooses the image URL for a question from the images found in its text,
+// keeping imageURL if it is already set, and reports the images that cannot be imported
+func pickImage(r *Result, item int, name, imageURL string, images []string) string {
+	for _, src := range images {
+		switch {
+		case src == imageURL:
+		case imageURL == "" && isRemoteURL(src):
+			imageURL = src
+		case isRemoteURL(src):
+			r.warn(item, name, "only one image per question is supported, %s was dropped", src)
+		default:
+			r.warn(item, name, "embedded image %q was not imported; upload it and set image_url", shorten(src))
+		}
+	}
+	return imageURL
+}
+
+// shorten truncates long values in issue messages and names
+func shorten(s string) string {
+	runes := []rune(s)
+	if len(runes) > 60 {
+		return string(runes[:57]) + "..."
+	}
+	return s
+}
+
+// textToHTML converts plain text to an HTML fragment, keeping line breaks
+func textToHTML(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = html.EscapeString(line)
+	}
+	return strings.Join(lines, "<br>")
+}
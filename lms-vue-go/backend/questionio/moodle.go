@@ -0,0 +1,265 @@
+package questionio
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"lms-vue-go/backend/models"
+)
+
+// moodleQuiz is the root element of a Moodle XML file
+type moodleQuiz struct {
+	XMLName   xml.Name         `xml:"quiz"`
+	Questions []moodleQuestion `xml:"question"`
+}
+
+// moodleQuestion is one <question> element. Only the parts that map to models.Question are used.
+type moodleQuestion struct {
+	Type            string         `xml:"type,attr"`
+	Category        *moodleText    `xml:"category"`
+	Name            *moodleText    `xml:"name"`
+	QuestionText    moodleText     `xml:"questiontext"`
+	DefaultGrade    string         `xml:"defaultgrade,omitempty"`
+	Single          string         `xml:"single,omitempty"`
+	ShuffleAnswers  string         `xml:"shuffleanswers,omitempty"`
+	AnswerNumbering string         `xml:"answernumbering,omitempty"`
+	Answers         []moodleAnswer `xml:"answer"`
+	GraderInfo      *moodleText    `xml:"graderinfo"`
+	Tags            []moodleText   `xml:"tags>tag"`
+}
+
+// moodleText is an element holding <text> and optionally embedded <file> elements
+type moodleText struct {
+	Format string       `xml:"format,attr,omitempty"`
+	Text   string       `xml:"text"`
+	Files  []moodleFile `xml:"file"`
+}
+
+// moodleFile is a file embedded in base64 inside a text element
+type moodleFile struct {
+	Name     string `xml:"name,attr"`
+	Path     string `xml:"path,attr,omitempty"`
+	Encoding string `xml:"encoding,attr"`
+	Data     string `xml:",chardata"`
+}
+
+// moodleAnswer is an <answer> element; Fraction is the percentage of the grade it earns
+type moodleAnswer struct {
+	Fraction string      `xml:"fraction,attr"`
+	Format   string      `xml:"format,attr,omitempty"`
+	Text     string      `xml:"text"`
+	Feedback *moodleText `xml:"feedback"`
+}
+
+// ImportMoodleXML parses a Moodle XML question export
+func ImportMoodleXML(data []byte) (*Result, error) {
+	var quiz moodleQuiz
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	if err := decoder.Decode(&quiz); err != nil {
+		return nil, fmt.Errorf("invalid Moodle XML: %w", err)
+	}
+
+	result := &Result{}
+	var categoryTagList []string
+	item := 0
+
+	for _, mq := range quiz.Questions {
+		if mq.Type == "category" {
+			categoryTagList = nil
+			if mq.Category != nil {
+				categoryTagList = categoryTags(mq.Category.Text)
+			}
+			continue
+		}
+		item++
+		name := ""
+		if mq.Name != nil {
+			name = strings.TrimSpace(mq.Name.Text)
+		}
+
+		text, images := moodleContent(mq.QuestionText)
+		q := models.Question{
+			Question: text,
+			Tags:     append([]string(nil), categoryTagList...),
+		}
+		for _, tag := range mq.Tags {
+			q.Tags = append(q.Tags, tag.Text)
+		}
+		q.ImageURL = pickImage(result, item, name, "", images)
+		if len(mq.QuestionText.Files) > 0 {
+			result.warn(item, name, "%d embedded file(s) were not imported; upload them and set image_url", len(mq.QuestionText.Files))
+		}
+
+		score, exact := roundScore(parseFloat(mq.DefaultGrade, 1))
+		q.Score = score
+		if !exact {
+			result.warn(item, name, "grade %s was rounded to %d", strings.TrimSpace(mq.DefaultGrade), score)
+		}
+
+		switch mq.Type {
+		case "multichoice", "truefalse":
+			if !moodleChoices(result, item, name, mq, &q) {
+				continue
+			}
+		case "essay":
+			q.Type = models.Essay
+			if mq.GraderInfo != nil {
+				q.Answer, _ = moodleContent(*mq.GraderInfo)
+			}
+		case "shortanswer", "numerical":
+			q.Type = models.Essay
+			var accepted []string
+			for _, answer := range mq.Answers {
+				if parseFloat(answer.Fraction, 0) > 0 {
+					text, _ := htmlToText(answer.Text)
+					accepted = append(accepted, text)
+				}
+			}
+			q.Answer = strings.Join(accepted, "\n")
+			result.warn(item, name, "%s question imported as essay; accepted answers are kept as the answer key but are not graded automatically", mq.Type)
+		case "description":
+			result.skip(item, name, "description items are not questions")
+			continue
+		default:
+			result.skip(item, name, "question type %q is not supported", mq.Type)
+			continue
+		}
+
+		result.addQuestion(item, name, q)
+	}
+
+	return result, nil
+}
+
+// moodleChoices maps the answers of a multichoice or truefalse question to options and an answer key
+func moodleChoices(result *Result, item int, name string, mq moodleQuestion, q *models.Question) bool {
+	q.Type = models.MultipleChoice
+
+	best, bestFraction, positive := -1, 0.0, 0
+	for i, answer := range mq.Answers {
+		text, images := htmlToText(answer.Text)
+		if mq.Type == "truefalse" {
+			text = trueFalseLabel(text)
+		}
+		q.Options = append(q.Options, text)
+		if len(images) > 0 {
+			result.warn(item, name, "images inside option %s were not imported", optionLetter(i))
+		}
+
+		fraction := parseFloat(answer.Fraction, 0)
+		if fraction > 0 {
+			positive++
+		}
+		if fraction > 0 && fraction < 100 {
+			result.warn(item, name, "partial credit (%s%%) for option %s was dropped", strings.TrimSpace(answer.Fraction), optionLetter(i))
+		}
+		if fraction > bestFraction {
+			best, bestFraction = i, fraction
+		}
+	}
+
+	if strings.EqualFold(strings.TrimSpace(mq.Single), "false") && positive > 1 {
+		result.skip(item, name, "multiple-response questions (more than one correct option) are not supported")
+		return false
+	}
+
+	if best >= 0 {
+		q.Answer = optionLetter(best)
+	}
+	return true
+}
+
+// trueFalseLabel normalises the option text of a truefalse question
+func trueFalseLabel(text string) string {
+	switch strings.ToLower(text) {
+	case "true":
+		return "True"
+	case "false":
+		return "False"
+	}
+	return text
+}
+
+// moodleContent converts a Moodle text element to plain text according to its format
+func moodleContent(t moodleText) (string, []string) {
+	switch t.Format {
+	case "plain_text", "moodle_auto_format", "markdown":
+		return cleanText(t.Text), nil
+	}
+	return htmlToText(t.Text)
+}
+
+// parseFloat parses a number from a Moodle attribute, returning fallback if it is empty or invalid
+func parseFloat(s string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// ExportMoodleXML writes questions as a Moodle XML file. Options keep their order
+// (shuffling is turned off) and the image is embedded in the question text as a link.
+func ExportMoodleXML(questions []models.Question) ([]byte, error) {
+	quiz := moodleQuiz{}
+	for _, q := range questions {
+		text := textToHTML(q.Question)
+		if q.ImageURL != "" {
+			text += `<p><img src="` + html.EscapeString(q.ImageURL) + `" alt=""></p>`
+		}
+
+		mq := moodleQuestion{
+			Name:         &moodleText{Text: questionName(q)},
+			QuestionText: moodleText{Format: "html", Text: text},
+			DefaultGrade: strconv.Itoa(q.Score),
+		}
+		for _, tag := range q.Tags {
+			mq.Tags = append(mq.Tags, moodleText{Text: tag})
+		}
+
+		switch q.Type {
+		case models.MultipleChoice:
+			mq.Type = "multichoice"
+			mq.Single = "true"
+			mq.ShuffleAnswers = "0"
+			mq.AnswerNumbering = "ABCD"
+			correct := optionIndex(q.Answer, len(q.Options))
+			for i, option := range q.Options {
+				fraction := "0"
+				if i == correct {
+					fraction = "100"
+				}
+				mq.Answers = append(mq.Answers, moodleAnswer{Fraction: fraction, Format: "html", Text: textToHTML(option)})
+			}
+		case models.Essay:
+			mq.Type = "essay"
+			if q.Answer != "" {
+				mq.GraderInfo = &moodleText{Format: "html", Text: textToHTML(q.Answer)}
+			}
+		default:
+			return nil, fmt.Errorf("question %d: unsupported type %q", q.ID, q.Type)
+		}
+
+		quiz.Questions = append(quiz.Questions, mq)
+	}
+
+	out, err := xml.MarshalIndent(quiz, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// questionName returns a short name for a question in formats that require one
+func questionName(q models.Question) string {
+	name := strings.SplitN(q.Question, "\n", 2)[0]
+	if q.ID > 0 {
+		name = fmt.Sprintf("%d. %s", q.ID, name)
+	}
+	return shorten(name)
+}
@@ -0,0 +1,108 @@
+package questionio
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"lms-vue-go/backend/models"
+)
+
+// NativeFormatName and NativeFormatVersion identify the native JSON format
+const (
+	NativeFormatName    = "lms-questions"
+	NativeFormatVersion = 1
+)
+
+// NativeDocument is the native JSON export format. Unlike the LMS interchange
+// formats it stores every field exactly, so an export can be imported again
+// without any loss.
+type NativeDocument struct {
+	Format     string           `json:"format"`  // Always "lms-questions"
+	Version    int              `json:"version"` // Format version, currently 1
+	ExportedAt time.Time        `json:"exported_at"`
+	Questions  []NativeQuestion `json:"questions"`
+}
+
+// NativeQuestion is one question in a NativeDocument
+type NativeQuestion struct {
+	SourceID uint                `json:"source_id,omitempty"` // ID in the exporting system, ignored on import
+	Type     models.QuestionType `json:"type"`                // "multiple_choice" or "essay"
+	Question string              `json:"question"`
+	Options  []string            `json:"options,omitempty"`   // Multiple choice only, in display order
+	Answer   string              `json:"answer,omitempty"`    // Option letter ("A", "B", ...) or the essay answer key
+	ImageURL string              `json:"image_url,omitempty"` // Stored as is
+	Score    int                 `json:"score"`
+	Tags     []string            `json:"tags,omitempty"`
+	FileRule *models.FileRule    `json:"file_rule,omitempty"` // Essay only; files students may attach to answers
+}
+
+// ExportJSON writes questions in the native JSON format
+func ExportJSON(questions []models.Question) ([]byte, error) {
+	doc := NativeDocument{
+		Format:     NativeFormatName,
+		Version:    NativeFormatVersion,
+		ExportedAt: time.Now().UTC(),
+		Questions:  []NativeQuestion{},
+	}
+	for _, q := range questions {
+		doc.Questions = append(doc.Questions, NativeQuestion{
+			SourceID: q.ID,
+			Type:     q.Type,
+			Question: q.Question,
+			Options:  q.Options,
+			Answer:   q.Answer,
+			ImageURL: q.ImageURL,
+			Score:    q.Score,
+			Tags:     q.Tags,
+			FileRule: q.FileRule,
+		})
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// ImportJSON parses questions in the native JSON format
+func ImportJSON(data []byte) (*Result, error) {
+	var doc NativeDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if doc.Format != NativeFormatName {
+		return nil, fmt.Errorf("invalid JSON: format must be %q", NativeFormatName)
+	}
+	if doc.Version < 1 || doc.Version > NativeFormatVersion {
+		return nil, fmt.Errorf("invalid JSON: unsupported version %d", doc.Version)
+	}
+
+	result := &Result{}
+	for i, nq := range doc.Questions {
+		item := i + 1
+		name := ""
+		if nq.SourceID > 0 {
+			name = fmt.Sprintf("%d", nq.SourceID)
+		}
+
+		if nq.Type != models.MultipleChoice && nq.Type != models.Essay {
+			result.skip(item, name, "question type %q is not supported", nq.Type)
+			continue
+		}
+
+		result.addQuestion(item, name, models.Question{
+			Type:     nq.Type,
+			Question: nq.Question,
+			Options:  nq.Options,
+			Answer:   nq.Answer,
+			ImageURL: nq.ImageURL,
+			Score:    nq.Score,
+			Tags:     nq.Tags,
+			FileRule: nq.FileRule,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,569 @@
+package questionio
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"lms-vue-go/backend/models"
+)
+
+// Limits for QTI content packages, so a small archive cannot expand into huge amounts of data
+const (
+	maxPackageFileSize  = 10 << 20 // Uncompressed size of a single file
+	maxPackageTotalSize = 50 << 20 // Uncompressed size of all files read from a package
+	maxPackageEntries   = 2000     // Entries in the archive, including directories and other resources
+)
+
+// xmlNode is a generic XML element used to walk QTI item bodies, which mix XHTML and interactions
+type xmlNode struct {
+	Name     string
+	Attrs    map[string]string
+	Children []*xmlNode
+	Text     string        // Character data directly inside the element
+	order    []interface{} // Character data and child elements in document order
+}
+
+// parseXMLTree reads an XML document into a tree of xmlNode, ignoring namespaces
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: t.Name.Local, Attrs: map[string]string{}}
+			for _, attr := range t.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+				parent.order = append(parent.order, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Text += string(t)
+				parent.order = append(parent.order, string(t))
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("empty XML document")
+	}
+	return root, nil
+}
+
+// child returns the first child element with the given name
+func (n *xmlNode) child(name string) *xmlNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// children returns the child elements with the given name
+func (n *xmlNode) children(name string) []*xmlNode {
+	var found []*xmlNode
+	for _, c := range n.Children {
+		if c.Name == name {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// qtiInteractions are the QTI 2.1 interaction elements
+var qtiInteractions = map[string]bool{
+	"choiceInteraction": true, "extendedTextInteraction": true, "textEntryInteraction": true,
+	"orderInteraction": true, "associateInteraction": true, "matchInteraction": true,
+	"gapMatchInteraction": true, "inlineChoiceInteraction": true, "hottextInteraction": true,
+	"hotspotInteraction": true, "selectPointInteraction": true, "graphicOrderInteraction": true,
+	"graphicAssociateInteraction": true, "graphicGapMatchInteraction": true,
+	"positionObjectInteraction": true, "sliderInteraction": true, "drawingInteraction": true,
+	"uploadInteraction": true, "customInteraction": true, "mediaInteraction": true,
+}
+
+// itemText collects the text and image sources of an item body, leaving out interactions
+// (their prompt is added by the caller) and feedback shown only after answering
+func itemText(n *xmlNode, b *strings.Builder, images *[]string) {
+	switch n.Name {
+	case "img":
+		if src := n.Attrs["src"]; src != "" {
+			*images = append(*images, src)
+		}
+	case "object":
+		if data := n.Attrs["data"]; data != "" && strings.HasPrefix(n.Attrs["type"], "image/") {
+			*images = append(*images, data)
+		}
+	case "feedbackBlock", "feedbackInline", "modalFeedback", "rubricBlock", "templateBlock":
+		return
+	}
+	if qtiInteractions[n.Name] {
+		return
+	}
+
+	if blockElements[n.Name] {
+		b.WriteString("\n")
+	}
+	for _, part := range n.order {
+		switch p := part.(type) {
+		case string:
+			b.WriteString(p)
+		case *xmlNode:
+			itemText(p, b, images)
+		}
+	}
+	if blockElements[n.Name] {
+		b.WriteString("\n")
+	}
+}
+
+// findInteractions returns every interaction element below n
+func findInteractions(n *xmlNode) []*xmlNode {
+	var found []*xmlNode
+	for _, c := range n.Children {
+		if qtiInteractions[c.Name] {
+			found = append(found, c)
+			continue
+		}
+		found = append(found, findInteractions(c)...)
+	}
+	return found
+}
+
+// ImportQTI parses an IMS QTI 2.1 assessmentItem document or a content package (zip)
+// containing assessmentItem documents
+func ImportQTI(data []byte) (*Result, error) {
+	result := &Result{}
+
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		root, err := parseXMLTree(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid QTI XML: %w", err)
+		}
+		if root.Name != "assessmentItem" {
+			return nil, fmt.Errorf("invalid QTI XML: expected assessmentItem, found %s", root.Name)
+		}
+		importQTIItem(result, 1, root, nil)
+		return result, nil
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QTI package: %w", err)
+	}
+	if len(archive.File) > maxPackageEntries {
+		return nil, fmt.Errorf("invalid QTI package: more than %d entries", maxPackageEntries)
+	}
+
+	files := map[string]*zip.File{}
+	var names []string
+	var manifest *zip.File
+	for _, file := range archive.File {
+		switch {
+		case path.Base(file.Name) == "imsmanifest.xml":
+			manifest = file
+		case strings.ToLower(path.Ext(file.Name)) == ".xml":
+			files[file.Name] = file
+			names = append(names, file.Name)
+		}
+	}
+	sort.Strings(names)
+
+	// Items listed in the manifest come first, in manifest order, with their keywords as tags
+	var keywords map[string][]string
+	budget := int64(maxPackageTotalSize)
+	if manifest != nil {
+		content, err := readZipFile(manifest, &budget)
+		if err != nil {
+			return nil, fmt.Errorf("invalid QTI package: %s: %w", manifest.Name, err)
+		}
+		var order []string
+		order, keywords = qtiManifest(content, path.Dir(manifest.Name))
+		names = append(order, names...)
+	}
+
+	item := 0
+	seen := map[string]bool{}
+	for _, name := range names {
+		file := files[name]
+		if file == nil || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		content, err := readZipFile(file, &budget)
+		if err != nil {
+			return nil, fmt.Errorf("invalid QTI package: %s: %w", file.Name, err)
+		}
+		root, err := parseXMLTree(content)
+		if err != nil || root.Name != "assessmentItem" {
+			continue // Tests, sections and other resources are not questions
+		}
+		item++
+		importQTIItem(result, item, root, keywords[name])
+	}
+
+	if item == 0 {
+		return nil, fmt.Errorf("invalid QTI package: no assessmentItem found")
+	}
+	return result, nil
+}
+
+// qtiManifest returns the item files of a content package manifest in order and the
+// LOM keywords of each file. dir is the directory of the manifest inside the package.
+func qtiManifest(data []byte, dir string) ([]string, map[string][]string) {
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return nil, nil
+	}
+
+	var order []string
+	keywords := map[string][]string{}
+	resources := root.child("resources")
+	if resources == nil {
+		return nil, nil
+	}
+	for _, resource := range resources.children("resource") {
+		href := resource.Attrs["href"]
+		if href == "" || !strings.HasPrefix(resource.Attrs["type"], "imsqti_item") {
+			continue
+		}
+		name := path.Join(dir, href)
+		order = append(order, name)
+		for _, keyword := range descendants(resource, "keyword") {
+			for _, value := range keyword.children("string") {
+				keywords[name] = append(keywords[name], strings.TrimSpace(value.Text))
+			}
+		}
+	}
+	return order, keywords
+}
+
+// descendants returns every element below n with the given name
+func descendants(n *xmlNode, name string) []*xmlNode {
+	var found []*xmlNode
+	for _, c := range n.Children {
+		if c.Name == name {
+			found = append(found, c)
+		}
+		found = append(found, descendants(c, name)...)
+	}
+	return found
+}
+
+// readZipFile reads a file from a zip archive with a size limit. budget holds the bytes
+// still allowed for the whole package and is reduced by the bytes actually read, since
+// the sizes in the zip headers can be forged.
+func readZipFile(file *zip.File, budget *int64) ([]byte, error) {
+	if file.UncompressedSize64 > maxPackageFileSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxPackageFileSize)
+	}
+	if file.UncompressedSize64 > uint64(*budget) {
+		return nil, fmt.Errorf("package is larger than %d bytes uncompressed", maxPackageTotalSize)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	limit := min(int64(maxPackageFileSize), *budget)
+	content, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		if limit == *budget {
+			return nil, fmt.Errorf("package is larger than %d bytes uncompressed", maxPackageTotalSize)
+		}
+		return nil, fmt.Errorf("file is larger than %d bytes", maxPackageFileSize)
+	}
+	*budget -= int64(len(content))
+	return content, nil
+}
+
+// importQTIItem converts one assessmentItem; tags come from the package manifest
+func importQTIItem(result *Result, item int, root *xmlNode, tags []string) {
+	name := root.Attrs["title"]
+	if name == "" {
+		name = root.Attrs["identifier"]
+	}
+
+	body := root.child("itemBody")
+	if body == nil {
+		result.skip(item, name, "item has no itemBody")
+		return
+	}
+
+	interactions := findInteractions(body)
+	if len(interactions) != 1 {
+		result.skip(item, name, "items with %d interactions are not supported (exactly one is required)", len(interactions))
+		return
+	}
+	interaction := interactions[0]
+
+	var b strings.Builder
+	var images []string
+	itemText(body, &b, &images)
+	if prompt := interaction.child("prompt"); prompt != nil {
+		b.WriteString("\n")
+		itemText(prompt, &b, &images)
+	}
+
+	q := models.Question{
+		Question: cleanText(b.String()),
+		Score:    qtiScore(result, item, name, root),
+		Tags:     tags,
+	}
+	q.ImageURL = pickImage(result, item, name, "", images)
+
+	correct := qtiCorrectResponse(root, interaction.Attrs["responseIdentifier"])
+
+	switch interaction.Name {
+	case "choiceInteraction":
+		q.Type = models.MultipleChoice
+		if len(correct) > 1 {
+			result.skip(item, name, "multiple-response questions (more than one correct choice) are not supported")
+			return
+		}
+		if interaction.Attrs["shuffle"] == "true" {
+			result.warn(item, name, "choice shuffling is not supported; options keep their order")
+		}
+		for i, choice := range interaction.children("simpleChoice") {
+			var cb strings.Builder
+			var choiceImages []string
+			itemText(choice, &cb, &choiceImages)
+			q.Options = append(q.Options, cleanText(cb.String()))
+			if len(choiceImages) > 0 {
+				result.warn(item, name, "images inside option %s were not imported", optionLetter(i))
+			}
+			if len(correct) == 1 && choice.Attrs["identifier"] == correct[0] {
+				q.Answer = optionLetter(i)
+			}
+		}
+	case "extendedTextInteraction":
+		q.Type = models.Essay
+		q.Answer = strings.Join(correct, "\n")
+	case "textEntryInteraction":
+		q.Type = models.Essay
+		q.Answer = strings.Join(correct, "\n")
+		result.warn(item, name, "text entry question imported as essay; accepted answers are kept as the answer key but are not graded automatically")
+	default:
+		result.skip(item, name, "%s is not supported", interaction.Name)
+		return
+	}
+
+	result.addQuestion(item, name, q)
+}
+
+// qtiCorrectResponse returns the correct values of a response declaration
+func qtiCorrectResponse(root *xmlNode, identifier string) []string {
+	for _, decl := range root.children("responseDeclaration") {
+		if identifier != "" && decl.Attrs["identifier"] != identifier {
+			continue
+		}
+		var values []string
+		if correct := decl.child("correctResponse"); correct != nil {
+			for _, value := range correct.children("value") {
+				values = append(values, strings.TrimSpace(value.Text))
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// qtiScore reads the maximum score of an item from the MAXSCORE outcome or the
+// normalMaximum of the SCORE outcome; it defaults to 1
+func qtiScore(result *Result, item int, name string, root *xmlNode) int {
+	raw := ""
+	for _, decl := range root.children("outcomeDeclaration") {
+		switch decl.Attrs["identifier"] {
+		case "MAXSCORE":
+			if def := decl.child("defaultValue"); def != nil {
+				if value := def.child("value"); value != nil {
+					raw = strings.TrimSpace(value.Text)
+				}
+			}
+		case "SCORE":
+			if raw == "" {
+				raw = decl.Attrs["normalMaximum"]
+			}
+		}
+	}
+	if raw == "" {
+		return 1
+	}
+
+	score, exact := roundScore(parseFloat(raw, 1))
+	if !exact {
+		result.warn(item, name, "score %s was rounded to %d", raw, score)
+	}
+	return score
+}
+
+// qtiNamespace attributes of an exported assessmentItem
+const qtiNamespace = `xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" ` +
+	`xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" ` +
+	`xsi:schemaLocation="http://www.imsglobal.org/xsd/imsqti_v2p1 http://www.imsglobal.org/xsd/qti/qtiv2p1/imsqti_v2p1.xsd"`
+
+// ExportQTI writes questions as an IMS QTI 2.1 content package (zip) with one
+// assessmentItem per question. Tags are stored as LOM keywords in the manifest.
+func ExportQTI(questions []models.Question) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	var resources strings.Builder
+	for i, q := range questions {
+		item, err := qtiItem(q)
+		if err != nil {
+			return nil, err
+		}
+
+		href := fmt.Sprintf("item%05d.xml", i+1)
+		w, err := archive.Create(href)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(item); err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(&resources, "    <resource identifier=\"RES%05d\" type=\"imsqti_item_xmlv2p1\" href=\"%s\">\n", i+1, href)
+		if len(q.Tags) > 0 {
+			resources.WriteString("      <metadata><imsmd:lom><imsmd:general>")
+			for _, tag := range q.Tags {
+				fmt.Fprintf(&resources, "<imsmd:keyword><imsmd:string>%s</imsmd:string></imsmd:keyword>", xmlText(tag))
+			}
+			resources.WriteString("</imsmd:general></imsmd:lom></metadata>\n")
+		}
+		fmt.Fprintf(&resources, "      <file href=\"%s\"/>\n    </resource>\n", href)
+	}
+
+	w, err := archive.Create("imsmanifest.xml")
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w, `%s<manifest xmlns="http://www.imsglobal.org/xsd/imscp_v1p1" xmlns:imsmd="http://ltsc.ieee.org/xsd/LOM" identifier="MANIFEST">
+  <metadata>
+    <schema>QTIv2.1 Package</schema>
+    <schemaversion>1.0.0</schemaversion>
+  </metadata>
+  <organizations/>
+  <resources>
+%s  </resources>
+</manifest>
+`, xml.Header, resources.String())
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// qtiItem writes one question as an assessmentItem document
+func qtiItem(q models.Question) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, "<assessmentItem %s identifier=\"Q%d\" title=\"%s\" adaptive=\"false\" timeDependent=\"false\">\n",
+		qtiNamespace, q.ID, xmlText(questionName(q)))
+
+	baseType := "identifier"
+	correct := q.Answer
+	switch q.Type {
+	case models.MultipleChoice:
+		if i := optionIndex(q.Answer, len(q.Options)); i >= 0 {
+			correct = optionLetter(i)
+		}
+	case models.Essay:
+		baseType = "string"
+	default:
+		return nil, fmt.Errorf("question %d: unsupported type %q", q.ID, q.Type)
+	}
+
+	fmt.Fprintf(&b, "  <responseDeclaration identifier=\"RESPONSE\" cardinality=\"single\" baseType=\"%s\">\n", baseType)
+	if correct != "" {
+		fmt.Fprintf(&b, "    <correctResponse><value>%s</value></correctResponse>\n", xmlText(correct))
+	}
+	b.WriteString("  </responseDeclaration>\n")
+	b.WriteString("  <outcomeDeclaration identifier=\"SCORE\" cardinality=\"single\" baseType=\"float\"><defaultValue><value>0</value></defaultValue></outcomeDeclaration>\n")
+	fmt.Fprintf(&b, "  <outcomeDeclaration identifier=\"MAXSCORE\" cardinality=\"single\" baseType=\"float\"><defaultValue><value>%d</value></defaultValue></outcomeDeclaration>\n", q.Score)
+
+	b.WriteString("  <itemBody>\n")
+	for _, line := range strings.Split(q.Question, "\n") {
+		fmt.Fprintf(&b, "    <p>%s</p>\n", xmlText(line))
+	}
+	if q.ImageURL != "" {
+		fmt.Fprintf(&b, "    <p><img src=\"%s\" alt=\"\"/></p>\n", xmlText(q.ImageURL))
+	}
+	if q.Type == models.MultipleChoice {
+		b.WriteString("    <choiceInteraction responseIdentifier=\"RESPONSE\" shuffle=\"false\" maxChoices=\"1\">\n")
+		for i, option := range q.Options {
+			fmt.Fprintf(&b, "      <simpleChoice identifier=\"%s\">%s</simpleChoice>\n", optionLetter(i), qtiLines(option))
+		}
+		b.WriteString("    </choiceInteraction>\n")
+	} else {
+		b.WriteString("    <extendedTextInteraction responseIdentifier=\"RESPONSE\"/>\n")
+	}
+	b.WriteString("  </itemBody>\n")
+
+	// A correct choice earns MAXSCORE; essays are graded manually
+	if q.Type == models.MultipleChoice {
+		b.WriteString(`  <responseProcessing>
+    <responseCondition>
+      <responseIf>
+        <match><variable identifier="RESPONSE"/><correct identifier="RESPONSE"/></match>
+        <setOutcomeValue identifier="SCORE"><variable identifier="MAXSCORE"/></setOutcomeValue>
+      </responseIf>
+    </responseCondition>
+  </responseProcessing>
+`)
+	}
+	b.WriteString("</assessmentItem>\n")
+
+	return []byte(b.String()), nil
+}
+
+// qtiLines escapes text for an XHTML element, turning line breaks into <br/>
+func qtiLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = xmlText(line)
+	}
+	return strings.Join(lines, "<br/>")
+}
+
+// xmlText escapes s for use in XML character data and attribute values
+func xmlText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,269 @@
+// Package questionio converts questions between models.Question and the
+// interchange formats used by other learning management systems: Moodle XML,
+// GIFT (import only) and IMS QTI 2.1, plus a native JSON format that keeps
+// every field exactly.
+//
+// Importers never fail on a single bad question. Questions that cannot be
+// represented are skipped and reported as issues, and lossy conversions (for
+// example partial credit) are reported as warnings, so a teacher can review a
+// dry run before committing.
+package questionio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+
+	"lms-vue-go/backend/models"
+)
+
+// Format identifies an interchange format
+type Format string
+
+const (
+	FormatMoodleXML Format = "moodle"
+	FormatGIFT      Format = "gift"
+	FormatQTI       Format = "qti"
+	FormatJSON      Format = "json"
+)
+
+// ErrUnknownFormat is returned for a format name or file that is not recognised
+var ErrUnknownFormat = errors.New("unknown question format")
+
+// Severity of an import issue
+const (
+	SeverityWarning = "warning" // The question was imported, but something was lost
+	SeverityError   = "error"   // The question was skipped
+)
+
+// Issue describes a construct that could not be imported exactly
+type Issue struct {
+	Item     int    `json:"item"`           // 1-based position of the question in the source
+	Name     string `json:"name,omitempty"` // Question name or identifier in the source
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Result is the outcome of parsing an import file
+type Result struct {
+	Questions []models.Question `json:"questions"`
+	Issues    []Issue           `json:"issues"`
+	Skipped   int               `json:"skipped"`
+}
+
+// warn records a lossy conversion for an imported question
+func (r *Result) warn(item int, name, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Item: item, Name: name, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+// skip records a question that could not be imported
+func (r *Result) skip(item int, name, format string, args ...interface{}) {
+	r.Skipped++
+	r.Issues = append(r.Issues, Issue{Item: item, Name: name, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+// ParseFormat converts a format name to a Format
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(name))) {
+	case FormatMoodleXML, "moodlexml", "xml":
+		return FormatMoodleXML, nil
+	case FormatGIFT:
+		return FormatGIFT, nil
+	case FormatQTI, "qti21":
+		return FormatQTI, nil
+	case FormatJSON, "native":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+}
+
+// DetectFormat guesses the format of an uploaded file from its name and content
+func DetectFormat(filename string, data []byte) (Format, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".gift", ".txt":
+		return FormatGIFT, nil
+	case ".zip":
+		return FormatQTI, nil
+	case ".json":
+		return FormatJSON, nil
+	}
+
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return FormatQTI, nil
+	case bytes.HasPrefix(bytes.TrimSpace(head), []byte("{")) && bytes.Contains(head, []byte(`"format"`)):
+		return FormatJSON, nil
+	case bytes.Contains(head, []byte("<quiz")):
+		return FormatMoodleXML, nil
+	case bytes.Contains(head, []byte("<assessmentItem")):
+		return FormatQTI, nil
+	case bytes.Contains(head, []byte("{")) && !bytes.HasPrefix(bytes.TrimSpace(head), []byte("<")):
+		return FormatGIFT, nil
+	}
+	return "", fmt.Errorf("%w: cannot detect the format of %q", ErrUnknownFormat, filename)
+}
+
+// Import parses data in the given format
+func Import(format Format, data []byte) (*Result, error) {
+	switch format {
+	case FormatMoodleXML:
+		return ImportMoodleXML(data)
+	case FormatGIFT:
+		return ImportGIFT(data)
+	case FormatQTI:
+		return ImportQTI(data)
+	case FormatJSON:
+		return ImportJSON(data)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// Export writes questions in the given format. GIFT export is not supported.
+func Export(format Format, questions []models.Question) ([]byte, error) {
+	switch format {
+	case FormatMoodleXML:
+		return ExportMoodleXML(questions)
+	case FormatQTI:
+		return ExportQTI(questions)
+	case FormatJSON:
+		return ExportJSON(questions)
+	}
+	return nil, fmt.Errorf("%w: cannot export to %q", ErrUnknownFormat, format)
+}
+
+// ExportIssues reports fields that the format cannot store, so they are lost when the export
+// is imported again. The native JSON format stores every field and never has issues.
+func ExportIssues(format Format, questions []models.Question) []Issue {
+	if format == FormatJSON {
+		return nil
+	}
+
+	var issues []Issue
+	for i, q := range questions {
+		if q.FileRule != nil {
+			issues = append(issues, Issue{
+				Item:     i + 1,
+				Name:     fmt.Sprintf("%d", q.ID),
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("the answer file rule cannot be stored in %s", format),
+			})
+		}
+	}
+	return issues
+}
+
+// ContentType returns the MIME type of an export in the given format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatMoodleXML:
+		return "application/xml"
+	case FormatQTI:
+		return "application/zip"
+	case FormatJSON:
+		return "application/json"
+	}
+	return "text/plain"
+}
+
+// Extension returns the file name extension of an export in the given format
+func (f Format) Extension() string {
+	switch f {
+	case FormatMoodleXML:
+		return ".xml"
+	case FormatQTI:
+		return ".zip"
+	case FormatJSON:
+		return ".json"
+	}
+	return ".txt"
+}
+
+// optionLetter returns the answer key for the option at index i ("A", "B", ...)
+func optionLetter(i int) string {
+	return string(rune('A' + i))
+}
+
+// optionIndex returns the index of an answer key, or -1 if it is not a letter key
+func optionIndex(key string, options int) int {
+	if len(key) != 1 {
+		return -1
+	}
+	i := int(strings.ToUpper(key)[0] - 'A')
+	if i < 0 || i >= options {
+		return -1
+	}
+	return i
+}
+
+// maxOptions is the number of options that can be given a letter answer key
+const maxOptions = 26
+
+// roundScore converts a fractional grade to the integer score used by models.Question.
+// It reports false if the value had to be changed.
+func roundScore(value float64) (int, bool) {
+	if value <= 0 || math.IsNaN(value) {
+		return 1, false
+	}
+	rounded := int(math.Round(value))
+	if rounded < 1 {
+		rounded = 1
+	}
+	return rounded, float64(rounded) == value
+}
+
+// validate checks that a converted question can be stored. It returns a reason if not.
+func validate(q *models.Question) string {
+	if strings.TrimSpace(q.Question) == "" {
+		return "question text is empty"
+	}
+	if q.Type == models.MultipleChoice {
+		if len(q.Options) < 2 {
+			return "a multiple choice question needs at least two options"
+		}
+		if len(q.Options) > maxOptions {
+			return fmt.Sprintf("more than %d options are not supported", maxOptions)
+		}
+		if optionIndex(q.Answer, len(q.Options)) < 0 {
+			return "no correct option"
+		}
+	}
+	if q.FileRule != nil && q.Type != models.Essay {
+		return "file rules are only supported for essay questions"
+	}
+	for _, tag := range q.Tags {
+		if len(tag) > models.MaxTagLength {
+			return fmt.Sprintf("tag %q is longer than %d characters", tag, models.MaxTagLength)
+		}
+	}
+	return ""
+}
+
+// addQuestion validates a converted question and adds it to the result or records why it was skipped
+func (r *Result) addQuestion(item int, name string, q models.Question) {
+	q.Tags = models.NormalizeTags(q.Tags)
+	if reason := validate(&q); reason != "" {
+		r.skip(item, name, "%s", reason)
+		return
+	}
+	r.Questions = append(r.Questions, q)
+}
+
+// categoryTags turns a category path such as "$course$/Matematika/Aljabar" into tags
+func categoryTags(category string) []string {
+	var tags []string
+	for _, part := range strings.Split(category, "/") {
+		part = strings.TrimSpace(part)
+		if part == "" || strings.HasPrefix(part, "$") && strings.HasSuffix(part, "$") || strings.EqualFold(part, "top") {
+			continue
+		}
+		tags = append(tags, part)
+	}
+	return tags
+}
@@ -0,0 +1,220 @@
+package questionio
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"lms-vue-go/backend/models"
+)
+
+func TestImportGIFT(t *testing.T) {
+	data := []byte(`// komentar
+$CATEGORY: $course$/Geografi/Asia
+
+::ibukota::Ibukota Indonesia adalah {=Jakarta ~Bandung#Salah ~Surabaya}
+
+::pi::Nilai pi dua desimal? {#3.14}
+
+Matahari terbit dari {T} timur
+
+::jodoh::Pasangkan {=a -> 1 =b -> 2 =c -> 3}
+
+::esai::Jelaskan siklus air. {}
+`)
+
+	result, err := ImportGIFT(data)
+	if err != nil {
+		t.Fatalf("ImportGIFT: %v", err)
+	}
+	if len(result.Questions) != 4 || result.Skipped != 1 {
+		t.Fatalf("got %d questions, %d skipped: %+v", len(result.Questions), result.Skipped, result.Issues)
+	}
+
+	mc := result.Questions[0]
+	if mc.Type != models.MultipleChoice || mc.Answer != "A" || !reflect.DeepEqual(mc.Options, []string{"Jakarta", "Bandung", "Surabaya"}) {
+		t.Errorf("multiple choice = %+v", mc)
+	}
+	if !reflect.DeepEqual(mc.Tags, []string{"geografi", "asia"}) {
+		t.Errorf("tags = %q", mc.Tags)
+	}
+	if mc.Question != "Ibukota Indonesia adalah" {
+		t.Errorf("text = %q", mc.Question)
+	}
+	if num := result.Questions[1]; num.Type != models.Essay || num.Answer != "3.14" {
+		t.Errorf("numerical = %+v", num)
+	}
+	if tf := result.Questions[2]; tf.Question != "Matahari terbit dari "+giftBlank+" timur" || tf.Answer != "A" || !reflect.DeepEqual(tf.Options, []string{"True", "False"}) {
+		t.Errorf("true/false = %+v", tf)
+	}
+	if essay := result.Questions[3]; essay.Type != models.Essay || essay.Answer != "" {
+		t.Errorf("essay = %+v", essay)
+	}
+}
+
+func TestImportMoodleXML(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<quiz>
+  <question type="category"><category><text>$course$/top/Biologi</text></category></question>
+  <question type="multichoice">
+    <name><text>Sel</text></name>
+    <questiontext format="html"><text><![CDATA[<p>Bagian sel <b>penghasil</b> energi?</p><img src="https://cdn.example.com/sel.png">]]></text></questiontext>
+    <defaultgrade>2.0000000</defaultgrade>
+    <single>true</single>
+    <answer fraction="0"><text>Nukleus</text></answer>
+    <answer fraction="100"><text>Mitokondria</text></answer>
+    <answer fraction="0"><text>Ribosom</text></answer>
+  </question>
+  <question type="essay">
+    <name><text>Fotosintesis</text></name>
+    <questiontext format="html"><text>Jelaskan fotosintesis.</text></questiontext>
+    <defaultgrade>2.5</defaultgrade>
+    <graderinfo format="html"><text>&lt;p&gt;Cahaya, klorofil&lt;/p&gt;</text></graderinfo>
+  </question>
+  <question type="matching"><name><text>Pasangan</text></name></question>
+</quiz>`)
+
+	result, err := ImportMoodleXML(data)
+	if err != nil {
+		t.Fatalf("ImportMoodleXML: %v", err)
+	}
+	if len(result.Questions) != 2 || result.Skipped != 1 {
+		t.Fatalf("got %d questions, %d skipped: %+v", len(result.Questions), result.Skipped, result.Issues)
+	}
+
+	mc := result.Questions[0]
+	if mc.Question != "Bagian sel penghasil energi?" || mc.Answer != "B" || mc.Score != 2 || mc.ImageURL != "https://cdn.example.com/sel.png" {
+		t.Errorf("multiple choice = %+v", mc)
+	}
+	if !reflect.DeepEqual(mc.Tags, []string{"biologi"}) {
+		t.Errorf("tags = %q", mc.Tags)
+	}
+
+	essay := result.Questions[1]
+	if essay.Type != models.Essay || essay.Answer != "Cahaya, klorofil" || essay.Score != 3 {
+		t.Errorf("essay = %+v", essay)
+	}
+	if !hasIssue(result, 2, SeverityWarning) {
+		t.Errorf("expected a rounding warning: %+v", result.Issues)
+	}
+}
+
+func TestImportQTI(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="q1" title="Planet" adaptive="false" timeDependent="false">
+  <responseDeclaration identifier="RESPONSE" cardinality="single" baseType="identifier">
+    <correctResponse><value>ChoiceB</value></correctResponse>
+  </responseDeclaration>
+  <outcomeDeclaration identifier="SCORE" cardinality="single" baseType="float"/>
+  <outcomeDeclaration identifier="MAXSCORE" cardinality="single" baseType="float">
+    <defaultValue><value>5</value></defaultValue>
+  </outcomeDeclaration>
+  <itemBody>
+    <p>Tata surya</p>
+    <choiceInteraction responseIdentifier="RESPONSE" shuffle="false" maxChoices="1">
+      <prompt>Planet terbesar adalah</prompt>
+      <simpleChoice identifier="ChoiceA">Mars</simpleChoice>
+      <simpleChoice identifier="ChoiceB">Jupiter</simpleChoice>
+    </choiceInteraction>
+  </itemBody>
+</assessmentItem>`)
+
+	result, err := ImportQTI(data)
+	if err != nil {
+		t.Fatalf("ImportQTI: %v", err)
+	}
+	if len(result.Questions) != 1 {
+		t.Fatalf("got %d questions: %+v", len(result.Questions), result.Issues)
+	}
+	q := result.Questions[0]
+	if q.Question != "Tata surya\nPlanet terbesar adalah" || q.Answer != "B" || q.Score != 5 || !reflect.DeepEqual(q.Options, []string{"Mars", "Jupiter"}) {
+		t.Errorf("question = %+v", q)
+	}
+}
+
+func TestImportQTIPackageLimits(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for i := 0; i <= maxPackageEntries; i++ {
+		if _, err := w.Create(fmt.Sprintf("res/%d.txt", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportQTI(buf.Bytes()); err == nil || !strings.Contains(err.Error(), "entries") {
+		t.Errorf("ImportQTI with %d entries error = %v, want entry limit", maxPackageEntries+1, err)
+	}
+
+	buf.Reset()
+	w = zip.NewWriter(&buf)
+	for _, name := range []string{"a.xml", "b.xml"} {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(bytes.Repeat([]byte("x"), 600))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	budget := int64(1000)
+	if _, err := readZipFile(archive.File[0], &budget); err != nil || budget != 400 {
+		t.Fatalf("first file: error %v, budget %d, want nil and 400", err, budget)
+	}
+	if _, err := readZipFile(archive.File[1], &budget); err == nil {
+		t.Error("second file should exceed the package budget")
+	}
+}
+
+func TestImportJSONSkipsFileRuleOnMultipleChoice(t *testing.T) {
+	result, err := ImportJSON([]byte(`{"format":"lms-questions","version":1,"questions":[
+		{"type":"multiple_choice","question":"1 + 1?","options":["1","2"],"answer":"B","score":1,"file_rule":{"types":["application/pdf"],"max_size_mb":1,"max_files":1}}
+	]}`))
+	if err != nil {
+		t.Fatalf("ImportJSON: %v", err)
+	}
+	if len(result.Questions) != 0 || result.Skipped != 1 {
+		t.Errorf("got %d questions, %d skipped, want the question skipped", len(result.Questions), result.Skipped)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	cases := []struct {
+		filename string
+		data     string
+		want     Format
+	}{
+		{"soal.gift", "", FormatGIFT},
+		{"paket.zip", "", FormatQTI},
+		{"export.xml", `<?xml version="1.0"?><quiz>`, FormatMoodleXML},
+		{"item.xml", `<?xml version="1.0"?><assessmentItem>`, FormatQTI},
+		{"", "Soal {=a ~b}", FormatGIFT},
+	}
+	for _, c := range cases {
+		if got, err := DetectFormat(c.filename, []byte(c.data)); err != nil || got != c.want {
+			t.Errorf("DetectFormat(%q) = %q, %v, want %q", c.filename, got, err, c.want)
+		}
+	}
+	if _, err := DetectFormat("data.bin", []byte("<html>")); err == nil {
+		t.Error("DetectFormat should fail for unknown content")
+	}
+}
+
+func hasIssue(r *Result, item int, severity string) bool {
+	for _, issue := range r.Issues {
+		if issue.Item == item && issue.Severity == severity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"log"
+)
+
+// APITokenRepository handles database operations for personal API tokens
+type APITokenRepository struct {
+	DB *sql.DB
+	requestScope
+}
+
+// NewAPITokenRepository creates a new API token repository
+func NewAPITokenRepository() *APITokenRepository {
+	// Check if DB is initialized
+	if config.DB == nil {
+		log.Println("WARNING: Database connection is nil in APITokenRepository")
+	}
+	return &APITokenRepository{
+		DB: config.DB,
+	}
+}
+
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *APITokenRepository) WithContext(ctx context.Context) *APITokenRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// apiTokenColumns is the column list read by scanAPIToken
+const apiTokenColumns = `id, user_id, name, token_prefix, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+// hashAPIToken hashes a raw token. Tokens are long random strings, so a plain
+// SHA-256 is sufficient and lets the token be looked up by its hash.
+func hashAPIToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// scanAPIToken scans a row selected with apiTokenColumns
+func scanAPIToken(row rowScanner) (*models.APIToken, error) {
+	var token models.APIToken
+	var scopesJSON string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.Prefix,
+		&scopesJSON,
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(scopesJSON), &token.Scopes); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
+
+// Create stores a token; only the hash of rawToken is saved
+func (r *APITokenRepository) Create(token *models.APIToken, rawToken string) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Create")
+		return errors.New("database connection not initialized")
+	}
+
+	scopesJSON, err := json.Marshal(token.Scopes)
+	if err != nil {
+		return err
+	}
+
+	var expiresAt sql.NullTime
+	if token.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *token.ExpiresAt, Valid: true}
+	}
+
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_prefix, token_hash, scopes, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.DB.ExecContext(r.ctx(), query,
+		token.UserID,
+		token.Name,
+		token.Prefix,
+		hashAPIToken(rawToken),
+		scopesJSON,
+		expiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	token.ID = uint(id)
+	return nil
+}
+
+// FindByRawToken finds a token by its raw value
+func (r *APITokenRepository) FindByRawToken(rawToken string) (*models.APIToken, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindByRawToken")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = ?`
+
+	token, err := scanAPIToken(r.DB.QueryRowContext(r.ctx(), query, hashAPIToken(rawToken)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Token not found
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// FindByUser returns all tokens of a user, newest first
+func (r *APITokenRepository) FindByUser(userID uint) ([]models.APIToken, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindByUser")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE user_id = ? ORDER BY id DESC`
+
+	rows, err := r.DB.QueryContext(r.ctx(), query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []models.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Revoke revokes a token of a user. It returns false when no active token matched.
+func (r *APITokenRepository) Revoke(id, userID uint) (bool, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Revoke")
+		return false, errors.New("database connection not initialized")
+	}
+
+	result, err := r.DB.ExecContext(r.ctx(), `UPDATE api_tokens SET revoked_at = NOW() WHERE id = ? AND user_id = ? AND revoked_at IS NULL`, id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// TouchLastUsed records token usage, at most once per minute to limit writes
+func (r *APITokenRepository) TouchLastUsed(id uint) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "TouchLastUsed")
+		return errors.New("database connection not initialized")
+	}
+
+	query := `
+		UPDATE api_tokens SET last_used_at = NOW()
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL 1 MINUTE)
+	`
+	_, err := r.DB.ExecContext(r.ctx(), query, id)
+	return err
+}
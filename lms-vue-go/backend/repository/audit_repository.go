@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lms-vue-go/backend/audit"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"log"
+	"time"
+)
+
+// AuditRepository handles database operations for the audit log. Entries can only
+// be appended; there is no update or delete.
+type AuditRepository struct {
+	DB *sql.DB
+	requestScope
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository() *AuditRepository {
+	// Check if DB is initialized
+	if config.DB == nil {
+		log.Println("WARNING: Database connection is nil in AuditRepository")
+	}
+	return &AuditRepository{
+		DB: config.DB,
+	}
+}
+
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *AuditRepository) WithContext(ctx context.Context) *AuditRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// auditColumns is the column list read by scanAuditLog
+const auditColumns = `id, actor_id, actor_role, action, entity_type, entity_id, before_data, after_data, ip, created_at, prev_hash, hash`
+
+// scanAuditLog scans a row selected with auditColumns
+func scanAuditLog(row rowScanner) (*models.AuditLog, error) {
+	var entry models.AuditLog
+	var actorID sql.NullInt64
+	var before, after sql.NullString
+
+	err := row.Scan(
+		&entry.ID,
+		&actorID,
+		&entry.ActorRole,
+		&entry.Action,
+		&entry.EntityType,
+		&entry.EntityID,
+		&before,
+		&after,
+		&entry.IP,
+		&entry.CreatedAt,
+		&entry.PrevHash,
+		&entry.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if actorID.Valid {
+		id := uint(actorID.Int64)
+		entry.ActorID = &id
+	}
+	if before.Valid {
+		entry.Before = json.RawMessage(before.String)
+	}
+	if after.Valid {
+		entry.After = json.RawMessage(after.String)
+	}
+	return &entry, nil
+}
+
+// AuditListSpec lists the filter and sort fields of GET /api/admin/audit
+var AuditListSpec = ListSpec{
+	SearchColumns: []string{"action", "ip"},
+	Filters: map[string]ListFilter{
+		"actor_id":    IntFilter("actor_id"),
+		"action":      EqualFilter("action"),
+		"entity_type": EqualFilter("entity_type"),
+		"entity_id":   IntFilter("entity_id"),
+		"ip":          EqualFilter("ip"),
+		"from":        timeFilter("created_at >= ?"),
+		"to":          timeFilter("created_at < ?"),
+	},
+	SortColumns: map[string]string{
+		"id":         "id",
+		"created_at": "created_at",
+	},
+	DefaultSort: "id",
+	DefaultDesc: true,
+	TieBreaker:  "id",
+}
+
+// timeFilter compares a column with an RFC 3339 time or a date (YYYY-MM-DD)
+func timeFilter(condition string) ListFilter {
+	return func(value string) (string, []interface{}, error) {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", value); err != nil {
+				return "", nil, fmt.Errorf("%w: %q is not a time", ErrInvalidListQuery, value)
+			}
+		}
+		return condition, []interface{}{t.UTC()}, nil
+	}
+}
+
+// Append seals an entry with the hash of the newest entry and stores it. The head
+// row is locked for the transaction, so concurrent appends form one chain.
+func (r *AuditRepository) Append(entry *models.AuditLog) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Append")
+		return errors.New("database connection not initialized")
+	}
+
+	// The change being audited is already saved, so the entry is written even if the request is cancelled
+	tx, err := r.DB.BeginTx(context.WithoutCancel(r.ctx()), nil)
+	if err != nil {
+		return err
+	}
+
+	if err := appendAudit(tx, entry); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// appendAudit appends an entry inside tx, so repositories can store a change and its
+// audit entry in one transaction: either both are saved or neither is. The caller
+// commits or rolls back.
+func appendAudit(tx *sql.Tx, entry *models.AuditLog) error {
+	var prevHash string
+	err := tx.QueryRow(`SELECT last_hash FROM audit_log_head WHERE id = 1 FOR UPDATE`).Scan(&prevHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Head row missing: start the chain
+		if _, err = tx.Exec(`INSERT IGNORE INTO audit_log_head (id, last_hash) VALUES (1, ?)`, audit.GenesisHash); err == nil {
+			err = tx.QueryRow(`SELECT last_hash FROM audit_log_head WHERE id = 1 FOR UPDATE`).Scan(&prevHash)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	entry.CreatedAt = entry.CreatedAt.UTC()
+	audit.Seal(entry, prevHash)
+
+	result, err := tx.Exec(`
+		INSERT INTO audit_logs (actor_id, actor_role, action, entity_type, entity_id, before_data, after_data, ip, created_at, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		nullableID(entry.ActorID),
+		entry.ActorRole,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+		entry.IP,
+		entry.CreatedAt,
+		entry.PrevHash,
+		entry.Hash,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	entry.ID = uint64(id)
+
+	_, err = tx.Exec(`UPDATE audit_log_head SET last_hash = ? WHERE id = 1`, entry.Hash)
+	return err
+}
+
+// purgeAudited permanently deletes the rows of table that were moved to the trash
+// before the given time. Each deleted row gets an audit entry without actor holding
+// the JSON_OBJECT arguments in fields as its before value, in the same transaction.
+func purgeAudited(ctx context.Context, db *sql.DB, table, fields string, before time.Time, action models.AuditAction, entity models.AuditEntity) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(`SELECT id, JSON_OBJECT(`+fields+`) FROM `+table+` WHERE deleted_at IS NOT NULL AND deleted_at < ? FOR UPDATE`, before)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	var entries []models.AuditLog
+	for rows.Next() {
+		var entry models.AuditLog
+		var raw string
+		if err := rows.Scan(&entry.EntityID, &raw); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return 0, err
+		}
+		entry.Action, entry.EntityType, entry.Before = action, entity, json.RawMessage(raw)
+		entries = append(entries, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for i := range entries {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE id = ?`, entries[i].EntityID); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err := appendAudit(tx, &entries[i]); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int64(len(entries)), nil
+}
+
+// List returns a page of audit entries matching the query and the total number of matches
+func (r *AuditRepository) List(q ListQuery) ([]models.AuditLog, int, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "List")
+		return nil, 0, errors.New("database connection not initialized")
+	}
+
+	where, args, err := AuditListSpec.whereClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := AuditListSpec.orderClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.DB.QueryRowContext(r.ctx(), `SELECT COUNT(*) FROM audit_logs`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	entries, err := r.queryEntries(`SELECT `+auditColumns+` FROM audit_logs`+where+order+` LIMIT ? OFFSET ?`, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// FindAfter returns up to limit entries with an ID above afterID in chain order
+func (r *AuditRepository) FindAfter(afterID uint64, limit int) ([]models.AuditLog, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindAfter")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	return r.queryEntries(`SELECT `+auditColumns+` FROM audit_logs WHERE id > ? ORDER BY id LIMIT ?`, afterID, limit)
+}
+
+// Head returns the hash of the newest entry recorded in the head row, or
+// audit.GenesisHash when nothing has been logged
+func (r *AuditRepository) Head() (string, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Head")
+		return "", errors.New("database connection not initialized")
+	}
+
+	var hash string
+	err := r.DB.QueryRowContext(r.ctx(), `SELECT last_hash FROM audit_log_head WHERE id = 1`).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return audit.GenesisHash, nil
+	}
+	return hash, err
+}
+
+// queryEntries runs a query selecting auditColumns
+func (r *AuditRepository) queryEntries(query string, args ...interface{}) ([]models.AuditLog, error) {
+	rows, err := r.DB.QueryContext(r.ctx(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditLog{}
+	for rows.Next() {
+		entry, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// nullableID stores a missing ID as NULL
+func nullableID(id *uint) sql.NullInt64 {
+	if id == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*id), Valid: true}
+}
+
+// nullableJSON stores a missing JSON value as NULL
+func nullableJSON(raw json.RawMessage) sql.NullString {
+	if len(raw) == 0 {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(raw), Valid: true}
+}
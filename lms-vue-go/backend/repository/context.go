@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"lms-vue-go/backend/logging"
+)
+
+// requestScope carries the context of the request a repository works for. Queries
+// run with it, so they are cancelled when the client goes away, and records logged
+// by the repository carry the request ID.
+type requestScope struct {
+	reqCtx context.Context
+}
+
+// ctx returns the request context, or the background context outside a request
+func (s requestScope) ctx() context.Context {
+	if s.reqCtx == nil {
+		return context.Background()
+	}
+	return s.reqCtx
+}
+
+// logger returns the logger of the request
+func (s requestScope) logger() *slog.Logger {
+	return logging.FromContext(s.ctx())
+}
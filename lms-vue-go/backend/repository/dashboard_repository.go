@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"log"
+	"math"
+	"time"
+)
+
+// DashboardRepository computes the teacher dashboard with aggregate queries
+type DashboardRepository struct {
+	DB *sql.DB
+	requestScope
+}
+
+// NewDashboardRepository creates a new dashboard repository
+func NewDashboardRepository() *DashboardRepository {
+	// Check if DB is initialized
+	if config.DB == nil {
+		log.Println("WARNING: Database connection is nil in DashboardRepository")
+	}
+	return &DashboardRepository{
+		DB: config.DB,
+	}
+}
+
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *DashboardRepository) WithContext(ctx context.Context) *DashboardRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// activeAnswersJoin limits answers to students and questions that are not in the trash
+const activeAnswersJoin = `
+		JOIN students s ON s.id = sa.student_id AND s.deleted_at IS NULL
+		JOIN questions q ON q.id = sa.question_id AND q.deleted_at IS NULL`
+
+// Summary returns the dashboard; limit caps the recent submissions and missing work lists
+func (r *DashboardRepository) Summary(limit int) (*models.DashboardSummary, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Summary")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	summary := &models.DashboardSummary{GeneratedAt: time.Now()}
+	if err := r.counts(summary); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if summary.RecentSubmissions, err = r.recentSubmissions(limit); err != nil {
+		return nil, err
+	}
+	if summary.ClassAverages, err = r.classAverages(); err != nil {
+		return nil, err
+	}
+	if summary.MissingWork, err = r.missingWork(limit); err != nil {
+		return nil, err
+	}
+	if summary.UpcomingDeadlines, err = r.upcomingDeadlines(limit); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// counts fills the totals of the summary in a single query
+func (r *DashboardRepository) counts(summary *models.DashboardSummary) error {
+	// Only essays are left ungraded; multiple choice answers are scored on submission
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM students WHERE deleted_at IS NULL),
+			(SELECT COUNT(*) FROM questions WHERE deleted_at IS NULL),
+			(SELECT COUNT(*) FROM student_answers sa` + activeAnswersJoin + ` WHERE sa.score IS NULL),
+			(SELECT MIN(sa.created_at) FROM student_answers sa` + activeAnswersJoin + ` WHERE sa.score IS NULL),
+			(SELECT COUNT(*) FROM student_answers sa` + activeAnswersJoin + ` WHERE sa.created_at >= ?),
+			(SELECT COUNT(*) FROM (
+				SELECT s.id
+				FROM students s
+				LEFT JOIN student_answers sa ON sa.student_id = s.id
+				LEFT JOIN questions q ON q.id = sa.question_id AND q.deleted_at IS NULL
+				WHERE s.deleted_at IS NULL
+				GROUP BY s.id
+				HAVING COUNT(q.id) < (SELECT COUNT(*) FROM questions WHERE deleted_at IS NULL)
+			) missing)`
+
+	var oldestPending sql.NullTime
+	err := r.DB.QueryRowContext(r.ctx(), query, time.Now().AddDate(0, 0, -7)).Scan(
+		&summary.Students,
+		&summary.Questions,
+		&summary.PendingGrading,
+		&oldestPending,
+		&summary.SubmissionsLastWeek,
+		&summary.MissingWorkCount,
+	)
+	if err != nil {
+		return err
+	}
+	if oldestPending.Valid {
+		summary.OldestPendingAt = &oldestPending.Time
+	}
+	return nil
+}
+
+// recentSubmissions returns the newest answers
+func (r *DashboardRepository) recentSubmissions(limit int) ([]models.RecentSubmission, error) {
+	query := `
+		SELECT sa.id, s.id, s.name, s.class, q.id, LEFT(COALESCE(qv.question, q.question), 120),
+		       COALESCE(qv.type, q.type), sa.score, sa.created_at
+		FROM student_answers sa` + activeAnswersJoin + `
+		LEFT JOIN question_versions qv ON qv.question_id = sa.question_id AND qv.version = sa.question_version
+		ORDER BY sa.created_at DESC, sa.id DESC
+		LIMIT ?`
+
+	rows, err := r.DB.QueryContext(r.ctx(), query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	submissions := []models.RecentSubmission{}
+	for rows.Next() {
+		var submission models.RecentSubmission
+		var score sql.NullInt32
+		err := rows.Scan(
+			&submission.AnswerID,
+			&submission.StudentID,
+			&submission.StudentName,
+			&submission.StudentClass,
+			&submission.QuestionID,
+			&submission.Question,
+			&submission.QuestionType,
+			&score,
+			&submission.SubmittedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if score.Valid {
+			scoreInt := int(score.Int32)
+			submission.Score = &scoreInt
+		}
+		submissions = append(submissions, submission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return submissions, nil
+}
+
+// classAverages returns the share of points earned on graded answers per class
+func (r *DashboardRepository) classAverages() ([]models.ClassAverage, error) {
+	query := `
+		SELECT s.class, COUNT(DISTINCT s.id), COUNT(sa.score), COUNT(sa.id) - COUNT(sa.score),
+		       SUM(sa.score), SUM(CASE WHEN sa.score IS NOT NULL THEN COALESCE(qv.score, q.score) END)
+		FROM students s
+		LEFT JOIN (
+			student_answers sa
+			JOIN questions q ON q.id = sa.question_id AND q.deleted_at IS NULL
+			LEFT JOIN question_versions qv ON qv.question_id = sa.question_id AND qv.version = sa.question_version
+		) ON sa.student_id = s.id
+		WHERE s.deleted_at IS NULL
+		GROUP BY s.class
+		ORDER BY s.class`
+
+	rows, err := r.DB.QueryContext(r.ctx(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	averages := []models.ClassAverage{}
+	for rows.Next() {
+		var average models.ClassAverage
+		var earned, possible sql.NullFloat64
+		if err := rows.Scan(&average.Class, &average.Students, &average.Graded, &average.Pending, &earned, &possible); err != nil {
+			return nil, err
+		}
+		if possible.Valid && possible.Float64 > 0 {
+			percentage := math.Round(earned.Float64/possible.Float64*1000) / 10
+			average.Average = &percentage
+		}
+		averages = append(averages, average)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return averages, nil
+}
+
+// missingWork returns the students who have not answered every question, most missing first
+func (r *DashboardRepository) missingWork(limit int) ([]models.StudentMissingWork, error) {
+	query := `
+		SELECT s.id, s.name, s.class, COUNT(q.id), total.questions - COUNT(q.id)
+		FROM students s
+		CROSS JOIN (SELECT COUNT(*) AS questions FROM questions WHERE deleted_at IS NULL) total
+		LEFT JOIN student_answers sa ON sa.student_id = s.id
+		LEFT JOIN questions q ON q.id = sa.question_id AND q.deleted_at IS NULL
+		WHERE s.deleted_at IS NULL
+		GROUP BY s.id, s.name, s.class, total.questions
+		HAVING COUNT(q.id) < total.questions
+		ORDER BY total.questions - COUNT(q.id) DESC, s.class, s.name
+		LIMIT ?`
+
+	rows, err := r.DB.QueryContext(r.ctx(), query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	students := []models.StudentMissingWork{}
+	for rows.Next() {
+		var student models.StudentMissingWork
+		if err := rows.Scan(&student.StudentID, &student.Name, &student.Class, &student.Answered, &student.Missing); err != nil {
+			return nil, err
+		}
+		students = append(students, student)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return students, nil
+}
+
+// upcomingDeadlines returns the exam schedules that are not due yet, nearest first
+func (r *DashboardRepository) upcomingDeadlines(limit int) ([]models.ExamDeadline, error) {
+	query := `
+		SELECT e.id, e.title, e.class, e.due_at,
+		       (SELECT COUNT(*) FROM students s WHERE s.deleted_at IS NULL AND (e.class = '' OR s.class = e.class))
+		FROM exam_schedules e
+		WHERE e.due_at >= ?
+		ORDER BY e.due_at, e.id
+		LIMIT ?`
+
+	rows, err := r.DB.QueryContext(r.ctx(), query, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deadlines := []models.ExamDeadline{}
+	for rows.Next() {
+		var deadline models.ExamDeadline
+		if err := rows.Scan(&deadline.ID, &deadline.Title, &deadline.Class, &deadline.DueAt, &deadline.Students); err != nil {
+			return nil, err
+		}
+		deadlines = append(deadlines, deadline)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deadlines, nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"log"
+)
+
+// ExamScheduleRepository handles database operations for exam schedules
+type ExamScheduleRepository struct {
+	DB *sql.DB
+	requestScope
+}
+
+// NewExamScheduleRepository creates a new exam schedule repository
+func NewExamScheduleRepository() *ExamScheduleRepository {
+	// Check if DB is initialized
+	if config.DB == nil {
+		log.Println("WARNING: Database connection is nil in ExamScheduleRepository")
+	}
+	return &ExamScheduleRepository{
+		DB: config.DB,
+	}
+}
+
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *ExamScheduleRepository) WithContext(ctx context.Context) *ExamScheduleRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// examScheduleColumns is the column list read by scanExamSchedule
+const examScheduleColumns = `id, title, class, due_at, created_by, created_at`
+
+// scanExamSchedule scans a row selected with examScheduleColumns
+func scanExamSchedule(row rowScanner) (*models.ExamSchedule, error) {
+	var schedule models.ExamSchedule
+	var createdBy sql.NullInt64
+
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.Title,
+		&schedule.Class,
+		&schedule.DueAt,
+		&createdBy,
+		&schedule.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if createdBy.Valid {
+		id := uint(createdBy.Int64)
+		schedule.CreatedBy = &id
+	}
+	return &schedule, nil
+}
+
+// FindAll returns every exam schedule, nearest deadline first
+func (r *ExamScheduleRepository) FindAll() ([]models.ExamSchedule, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindAll")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	rows, err := r.DB.QueryContext(r.ctx(), `SELECT `+examScheduleColumns+` FROM exam_schedules ORDER BY due_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []models.ExamSchedule{}
+	for rows.Next() {
+		schedule, err := scanExamSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// FindByID finds an exam schedule by ID
+func (r *ExamScheduleRepository) FindByID(id uint) (*models.ExamSchedule, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindByID")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	schedule, err := scanExamSchedule(r.DB.QueryRowContext(r.ctx(), `SELECT `+examScheduleColumns+` FROM exam_schedules WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil // Schedule not found
+	}
+	return schedule, err
+}
+
+// Create stores a new exam schedule
+func (r *ExamScheduleRepository) Create(schedule *models.ExamSchedule) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Create")
+		return errors.New("database connection not initialized")
+	}
+
+	result, err := r.DB.ExecContext(r.ctx(),
+		`INSERT INTO exam_schedules (title, class, due_at, created_by) VALUES (?, ?, ?, ?)`,
+		schedule.Title, schedule.Class, schedule.DueAt, nullableID(schedule.CreatedBy),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	schedule.ID = uint(id)
+	return nil
+}
+
+// Update changes the title, class and deadline of an exam schedule
+func (r *ExamScheduleRepository) Update(schedule *models.ExamSchedule) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Update")
+		return errors.New("database connection not initialized")
+	}
+
+	_, err := r.DB.ExecContext(r.ctx(),
+		`UPDATE exam_schedules SET title = ?, class = ?, due_at = ? WHERE id = ?`,
+		schedule.Title, schedule.Class, schedule.DueAt, schedule.ID,
+	)
+	return err
+}
+
+// Delete removes an exam schedule
+func (r *ExamScheduleRepository) Delete(id uint) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Delete")
+		return errors.New("database connection not initialized")
+	}
+
+	_, err := r.DB.ExecContext(r.ctx(), `DELETE FROM exam_schedules WHERE id = ?`, id)
+	return err
+}
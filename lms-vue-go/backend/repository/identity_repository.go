@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"log"
+)
+
+// IdentityRepository handles database operations for external identity links
+type IdentityRepository struct {
+	DB *sql.DB
+	requestScope
+}
+
+// NewIdentityRepository creates a new identity repository
+func NewIdentityRepository() *IdentityRepository {
+	// Check if DB is initialized
+	if config.DB == nil {
+		log.Println("WARNING: Database connection is nil in IdentityRepository")
+	}
+	return &IdentityRepository{
+		DB: config.DB,
+	}
+}
+
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *IdentityRepository) WithContext(ctx context.Context) *IdentityRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// FindByIssuerAndSubject finds the identity link for an IdP subject
+func (r *IdentityRepository) FindByIssuerAndSubject(issuer, subject string) (*models.UserIdentity, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindByIssuerAndSubject")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `
+		SELECT id, user_id, issuer, subject, created_at
+		FROM user_identities
+		WHERE issuer = ? AND subject = ?
+	`
+
+	var identity models.UserIdentity
+	err := r.DB.QueryRowContext(r.ctx(), query, issuer, subject).Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Identity not linked
+		}
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// Create links a user to an IdP subject
+func (r *IdentityRepository) Create(identity *models.UserIdentity) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Create")
+		return errors.New("database connection not initialized")
+	}
+
+	query := `INSERT INTO user_identities (user_id, issuer, subject) VALUES (?, ?, ?)`
+
+	result, err := r.DB.ExecContext(r.ctx(), query, identity.UserID, identity.Issuer, identity.Subject)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	identity.ID = uint(id)
+	return nil
+}
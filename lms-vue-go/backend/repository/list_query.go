@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidListQuery is returned when a list query uses an unknown sort key or an invalid filter value
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+// ListQuery holds the pagination, sorting, filtering and search parameters shared by list endpoints
+type ListQuery struct {
+	Search  string            // Free-text search over the spec's search columns
+	Filters map[string]string // Filter name to raw value, e.g. "type" => "essay"
+	Sort    string            // Sort key; empty uses the spec's default
+	Desc    bool
+	Limit   int
+	Offset  int
+}
+
+// ListFilter turns a raw filter value into a SQL condition
+type ListFilter func(value string) (string, []interface{}, error)
+
+// ListSpec describes which fields of a list endpoint can be searched, filtered and sorted
+type ListSpec struct {
+	SearchColumns []string
+	Filters       map[string]ListFilter
+	SortColumns   map[string]string // Sort key to column
+	DefaultSort   string
+	DefaultDesc   bool
+	TieBreaker    string // Unique column appended to ORDER BY so pages are stable
+}
+
+// EqualFilter matches a column against the value as is
+func EqualFilter(column string) ListFilter {
+	return func(value string) (string, []interface{}, error) {
+		return column + ` = ?`, []interface{}{value}, nil
+	}
+}
+
+// IntFilter matches a column against an integer value
+func IntFilter(column string) ListFilter {
+	return func(value string) (string, []interface{}, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %q is not a number", ErrInvalidListQuery, value)
+		}
+		return column + ` = ?`, []interface{}{n}, nil
+	}
+}
+
+// BoolFilter matches a boolean column against "true" or "false"
+func BoolFilter(column string) ListFilter {
+	return func(value string) (string, []interface{}, error) {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %q is not a boolean", ErrInvalidListQuery, value)
+		}
+		return column + ` = ?`, []interface{}{b}, nil
+	}
+}
+
+// PresenceFilter checks whether a nullable column is set ("true") or NULL ("false")
+func PresenceFilter(column string) ListFilter {
+	return func(value string) (string, []interface{}, error) {
+		present, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %q is not a boolean", ErrInvalidListQuery, value)
+		}
+		if present {
+			return column + ` IS NOT NULL`, nil, nil
+		}
+		return column + ` IS NULL`, nil, nil
+	}
+}
+
+// HasSort reports whether key is a valid sort key for the spec
+func (s ListSpec) HasSort(key string) bool {
+	_, ok := s.SortColumns[key]
+	return ok
+}
+
+// HasFilter reports whether name is a filter supported by the spec
+func (s ListSpec) HasFilter(name string) bool {
+	_, ok := s.Filters[name]
+	return ok
+}
+
+// whereClause builds the WHERE clause and its arguments for a query
+func (s ListSpec) whereClause(q ListQuery) (string, []interface{}, error) {
+	where := ` WHERE 1 = 1`
+	var args []interface{}
+
+	if q.Search != "" && len(s.SearchColumns) > 0 {
+		pattern := "%" + escapeLike(q.Search) + "%"
+		conditions := make([]string, len(s.SearchColumns))
+		for i, column := range s.SearchColumns {
+			conditions[i] = column + ` LIKE ?`
+			args = append(args, pattern)
+		}
+		where += ` AND (` + strings.Join(conditions, " OR ") + `)`
+	}
+
+	// Apply filters in a fixed order so the generated SQL is deterministic
+	names := make([]string, 0, len(q.Filters))
+	for name := range q.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := q.Filters[name]
+		filter, ok := s.Filters[name]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: unknown filter %q", ErrInvalidListQuery, name)
+		}
+		condition, filterArgs, err := filter(value)
+		if err != nil {
+			return "", nil, err
+		}
+		where += ` AND ` + condition
+		args = append(args, filterArgs...)
+	}
+
+	return where, args, nil
+}
+
+// orderClause builds the ORDER BY clause for a query
+func (s ListSpec) orderClause(q ListQuery) (string, error) {
+	key, desc := q.Sort, q.Desc
+	if key == "" {
+		key, desc = s.DefaultSort, s.DefaultDesc
+	}
+
+	column, ok := s.SortColumns[key]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown sort key %q", ErrInvalidListQuery, key)
+	}
+
+	direction := ` ASC`
+	if desc {
+		direction = ` DESC`
+	}
+
+	order := ` ORDER BY ` + column + direction
+	if s.TieBreaker != "" && column != s.TieBreaker {
+		order += `, ` + s.TieBreaker + direction
+	}
+	return order, nil
+}
+
+// escapeLike escapes LIKE wildcards so search terms are matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
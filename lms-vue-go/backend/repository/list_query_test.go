@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAnswerListSpecWhereClause(t *testing.T) {
+	where, args, err := AnswerListSpec.whereClause(ListQuery{
+		Search:  "50%",
+		Filters: map[string]string{"graded": "false", "class": "10A", "type": "essay"},
+	})
+	if err != nil {
+		t.Fatalf("whereClause: %v", err)
+	}
+
+	wantWhere := ` WHERE 1 = 1 AND (sa.answer LIKE ? OR s.name LIKE ? OR q.question LIKE ?)` +
+		` AND s.class = ? AND sa.score IS NULL AND q.type = ?`
+	if where != wantWhere {
+		t.Errorf("where = %q, want %q", where, wantWhere)
+	}
+
+	wantArgs := []interface{}{`%50\%%`, `%50\%%`, `%50\%%`, "10A", "essay"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestListSpecRejectsInvalidQueries(t *testing.T) {
+	queries := []ListQuery{
+		{Filters: map[string]string{"password": "x"}},
+		{Filters: map[string]string{"graded": "maybe"}},
+		{Filters: map[string]string{"student_id": "abc"}},
+	}
+	for _, q := range queries {
+		if _, _, err := AnswerListSpec.whereClause(q); !errors.Is(err, ErrInvalidListQuery) {
+			t.Errorf("whereClause(%v) error = %v, want ErrInvalidListQuery", q.Filters, err)
+		}
+	}
+
+	if _, err := StudentListSpec.orderClause(ListQuery{Sort: "password"}); !errors.Is(err, ErrInvalidListQuery) {
+		t.Errorf("orderClause with unknown key error = %v, want ErrInvalidListQuery", err)
+	}
+}
+
+func TestListSpecOrderClause(t *testing.T) {
+	tests := []struct {
+		query ListQuery
+		want  string
+	}{
+		{ListQuery{}, ` ORDER BY sa.id DESC`},
+		{ListQuery{Sort: "score"}, ` ORDER BY sa.score ASC, sa.id ASC`},
+		{ListQuery{Sort: "class", Desc: true}, ` ORDER BY s.class DESC, sa.id DESC`},
+	}
+	for _, tt := range tests {
+		got, err := AnswerListSpec.orderClause(tt.query)
+		if err != nil {
+			t.Fatalf("orderClause(%+v): %v", tt.query, err)
+		}
+		if got != tt.want {
+			t.Errorf("orderClause(%+v) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestUserListSpecWhereClause(t *testing.T) {
+	where, args, err := UserListSpec.whereClause(ListQuery{
+		Search:  "a_b",
+		Filters: map[string]string{"role": "teacher", "active": "false"},
+	})
+	if err != nil {
+		t.Fatalf("whereClause: %v", err)
+	}
+
+	wantWhere := ` WHERE 1 = 1 AND (username LIKE ? OR email LIKE ?) AND is_active = ? AND role = ?`
+	if where != wantWhere {
+		t.Errorf("where = %q, want %q", where, wantWhere)
+	}
+
+	wantArgs := []interface{}{`%a\_b%`, `%a\_b%`, false, "teacher"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+
+	for _, filters := range []map[string]string{{"role": "superuser"}, {"active": "ya"}} {
+		if _, _, err := UserListSpec.whereClause(ListQuery{Filters: filters}); !errors.Is(err, ErrInvalidListQuery) {
+			t.Errorf("whereClause(%v) error = %v, want ErrInvalidListQuery", filters, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"log"
+)
+
+// LoginAttemptRepository handles database operations for failed login tracking
+type LoginAttemptRepository struct {
+	DB *sql.DB
+	requestScope
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository
+func NewLoginAttemptRepository() *LoginAttemptRepository {
+	// Check if DB is initialized
+	if config.DB == nil {
+		log.Println("WARNING: Database connection is nil in LoginAttemptRepository")
+	}
+	return &LoginAttemptRepository{
+		DB: config.DB,
+	}
+}
+
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *LoginAttemptRepository) WithContext(ctx context.Context) *LoginAttemptRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// Find returns the failure record for a key, or nil when there is none
+func (r *LoginAttemptRepository) Find(kind models.LoginAttemptKind, key string) (*models.LoginAttempt, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Find")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `
+		SELECT kind, attempt_key, failures, last_failure_at, locked_until
+		FROM login_attempts
+		WHERE kind = ? AND attempt_key = ?
+	`
+
+	var attempt models.LoginAttempt
+	var lockedUntil sql.NullTime
+
+	err := r.DB.QueryRowContext(r.ctx(), query, kind, key).Scan(
+		&attempt.Kind,
+		&attempt.Key,
+		&attempt.Failures,
+		&attempt.LastFailureAt,
+		&lockedUntil,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // No failures recorded
+		}
+		return nil, err
+	}
+
+	// Set lock time if present
+	if lockedUntil.Valid {
+		attempt.LockedUntil = &lockedUntil.Time
+	}
+
+	return &attempt, nil
+}
+
+// RecordFailure updates the failure record for a key with next, which receives the
+// current record (nil when there is none). The row is locked from read to write, so
+// concurrent failed logins are counted one after another instead of overwriting each other.
+func (r *LoginAttemptRepository) RecordFailure(kind models.LoginAttemptKind, key string, next func(existing *models.LoginAttempt) *models.LoginAttempt) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "RecordFailure")
+		return errors.New("database connection not initialized")
+	}
+
+	tx, err := r.DB.BeginTx(r.ctx(), nil)
+	if err != nil {
+		return err
+	}
+
+	// Create the row first so there is always a row to lock; failures = 0 means no record yet
+	_, err = tx.Exec(`INSERT IGNORE INTO login_attempts (kind, attempt_key, failures, last_failure_at) VALUES (?, ?, 0, NOW())`, kind, key)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var existing models.LoginAttempt
+	var lockedUntil sql.NullTime
+	err = tx.QueryRow(`
+		SELECT kind, attempt_key, failures, last_failure_at, locked_until
+		FROM login_attempts
+		WHERE kind = ? AND attempt_key = ?
+		FOR UPDATE
+	`, kind, key).Scan(
+		&existing.Kind,
+		&existing.Key,
+		&existing.Failures,
+		&existing.LastFailureAt,
+		&lockedUntil,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if lockedUntil.Valid {
+		existing.LockedUntil = &lockedUntil.Time
+	}
+
+	var current *models.LoginAttempt
+	if existing.Failures > 0 {
+		current = &existing
+	}
+	attempt := next(current)
+
+	var newLockedUntil sql.NullTime
+	if attempt.LockedUntil != nil {
+		newLockedUntil = sql.NullTime{Time: *attempt.LockedUntil, Valid: true}
+	}
+	_, err = tx.Exec(`
+		UPDATE login_attempts
+		SET failures = ?, last_failure_at = ?, locked_until = ?
+		WHERE kind = ? AND attempt_key = ?
+	`, attempt.Failures, attempt.LastFailureAt, newLockedUntil, kind, key)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Reset clears the failure record for a key
+func (r *LoginAttemptRepository) Reset(kind models.LoginAttemptKind, key string) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Reset")
+		return errors.New("database connection not initialized")
+	}
+
+	_, err := r.DB.ExecContext(r.ctx(), `DELETE FROM login_attempts WHERE kind = ? AND attempt_key = ?`, kind, key)
+	return err
+}
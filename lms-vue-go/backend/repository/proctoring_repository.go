@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"log"
+	"strings"
+)
+
+// ProctoringRepository handles database operations for exam proctoring events
+type ProctoringRepository struct {
+	DB *sql.DB
+	requestScope
+}
+
+// NewProctoringRepository creates a new proctoring repository
+func NewProctoringRepository() *ProctoringRepository {
+	// Check if DB is initialized
+	if config.DB == nil {
+		log.Println("WARNING: Database connection is nil in ProctoringRepository")
+	}
+	return &ProctoringRepository{
+		DB: config.DB,
+	}
+}
+
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *ProctoringRepository) WithContext(ctx context.Context) *ProctoringRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// proctoringColumns is the column list read by scanProctoringEvent
+const proctoringColumns = `id, student_id, session_id, event_type, question_id, length, occurred_at, received_at`
+
+// scanProctoringEvent scans a row selected with proctoringColumns
+func scanProctoringEvent(row rowScanner) (*models.ProctoringEvent, error) {
+	var event models.ProctoringEvent
+	var questionID sql.NullInt64
+
+	err := row.Scan(
+		&event.ID,
+		&event.StudentID,
+		&event.SessionID,
+		&event.Type,
+		&questionID,
+		&event.Length,
+		&event.OccurredAt,
+		&event.ReceivedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if questionID.Valid {
+		id := uint(questionID.Int64)
+		event.QuestionID = &id
+	}
+	return &event, nil
+}
+
+// CreateBatch stores a batch of events with a single INSERT
+func (r *ProctoringRepository) CreateBatch(events []models.ProctoringEvent) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "CreateBatch")
+		return errors.New("database connection not initialized")
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(events))
+	args := make([]interface{}, 0, len(events)*6)
+	for i, e := range events {
+		values[i] = "(?, ?, ?, ?, ?, ?)"
+		var questionID sql.NullInt64
+		if e.QuestionID != nil {
+			questionID = sql.NullInt64{Int64: int64(*e.QuestionID), Valid: true}
+		}
+		args = append(args, e.StudentID, e.SessionID, e.Type, questionID, e.Length, e.OccurredAt)
+	}
+
+	query := `INSERT INTO proctoring_events (student_id, session_id, event_type, question_id, length, occurred_at)
+		VALUES ` + strings.Join(values, ", ")
+	_, err := r.DB.ExecContext(r.ctx(), query, args...)
+	return err
+}
+
+// FindByStudent returns all events of a student, grouped by session and in time order
+func (r *ProctoringRepository) FindByStudent(studentID uint) ([]models.ProctoringEvent, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindByStudent")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `SELECT ` + proctoringColumns + ` FROM proctoring_events
+		WHERE student_id = ?
+		ORDER BY session_id, occurred_at, id`
+	return r.queryEvents(query, studentID)
+}
+
+// FindBySession returns the events of one exam attempt in time order
+func (r *ProctoringRepository) FindBySession(studentID uint, sessionID string) ([]models.ProctoringEvent, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindBySession")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `SELECT ` + proctoringColumns + ` FROM proctoring_events
+		WHERE student_id = ? AND session_id = ?
+		ORDER BY occurred_at, id`
+	return r.queryEvents(query, studentID, sessionID)
+}
+
+// FindByQuestions returns the events of the given students that are bound to a question,
+// grouped by student and question and in time order
+func (r *ProctoringRepository) FindByQuestions(studentIDs []uint) ([]models.ProctoringEvent, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindByQuestions")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	if len(studentIDs) == 0 {
+		return []models.ProctoringEvent{}, nil
+	}
+
+	placeholders := make([]string, len(studentIDs))
+	args := make([]interface{}, len(studentIDs))
+	for i, id := range studentIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := `SELECT ` + proctoringColumns + ` FROM proctoring_events
+		WHERE student_id IN (` + strings.Join(placeholders, ", ") + `) AND question_id IS NOT NULL
+		ORDER BY student_id, question_id, occurred_at, id`
+	return r.queryEvents(query, args...)
+}
+
+// queryEvents runs a query selecting proctoringColumns
+func (r *ProctoringRepository) queryEvents(query string, args ...interface{}) ([]models.ProctoringEvent, error) {
+	rows, err := r.DB.QueryContext(r.ctx(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.ProctoringEvent{}
+	for rows.Next() {
+		event, err := scanProctoringEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	return events, rows.Err()
+}
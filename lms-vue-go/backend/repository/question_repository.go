@@ -1,17 +1,26 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"lms-vue-go/backend/config"
 	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/search"
 	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
 )
 
 // QuestionRepository handles database operations for questions
 type QuestionRepository struct {
 	DB *sql.DB
+	requestScope
 }
 
 // NewQuestionRepository creates a new question repository
@@ -25,20 +34,219 @@ func NewQuestionRepository() *QuestionRepository {
 	}
 }
 
-// FindAll returns all questions
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *QuestionRepository) WithContext(ctx context.Context) *QuestionRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// questionColumns is the column list read by scanQuestion
+const questionColumns = `id, type, question, options, answer, image_url, score, file_rule, current_version, deleted_at`
+
+// scanQuestion reads a question row selected with questionColumns
+func scanQuestion(row rowScanner) (*models.Question, error) {
+	var question models.Question
+	var optionsJSON sql.NullString
+	var imageURL sql.NullString
+	var answer sql.NullString
+	var fileRuleJSON sql.NullString
+	var deletedAt sql.NullTime
+
+	err := row.Scan(
+		&question.ID,
+		&question.Type,
+		&question.Question,
+		&optionsJSON,
+		&answer,
+		&imageURL,
+		&question.Score,
+		&fileRuleJSON,
+		&question.Version,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if deletedAt.Valid {
+		question.DeletedAt = &deletedAt.Time
+	}
+
+	// Parse options JSON if present
+	if optionsJSON.Valid && optionsJSON.String != "" {
+		err = json.Unmarshal([]byte(optionsJSON.String), &question.Options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Set answer if present
+	if answer.Valid {
+		question.Answer = answer.String
+	}
+
+	// Set image URL if present
+	if imageURL.Valid {
+		question.ImageURL = imageURL.String
+	}
+
+	if question.FileRule, err = unmarshalFileRule(fileRuleJSON); err != nil {
+		return nil, err
+	}
+
+	return &question, nil
+}
+
+// FindAll returns all questions that are not in the trash, in exam order
 func (r *QuestionRepository) FindAll() ([]models.Question, error) {
 	// Check if DB is nil
 	if r.DB == nil {
-		log.Println("ERROR: Database connection is nil in FindAll")
+		r.logger().Error("Database connection is nil", "method", "FindAll")
 		return nil, errors.New("database connection not initialized")
 	}
 
-	query := `
-		SELECT id, type, question, options, answer, image_url, score
-		FROM questions
-	`
+	query := `SELECT ` + questionColumns + ` FROM questions WHERE deleted_at IS NULL ORDER BY id`
 
-	rows, err := r.DB.Query(query)
+	questions, err := r.queryQuestions(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.attachTags(questions); err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+// FindByIDs returns the questions with the given IDs in the order of ids.
+// IDs that do not exist or are in the trash are left out.
+func (r *QuestionRepository) FindByIDs(ids []uint) ([]models.Question, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindByIDs")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	if len(ids) == 0 {
+		return []models.Question{}, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := `SELECT ` + questionColumns + ` FROM questions
+		WHERE deleted_at IS NULL AND id IN (` + strings.Join(placeholders, ", ") + `)`
+
+	found, err := r.queryQuestions(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Question, len(found))
+	for _, q := range found {
+		byID[q.ID] = q
+	}
+
+	questions := []models.Question{}
+	for _, id := range ids {
+		if q, ok := byID[id]; ok {
+			questions = append(questions, q)
+			delete(byID, id)
+		}
+	}
+
+	if err := r.attachTags(questions); err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+// QuestionListSpec lists the search, filter and sort fields of GET /api/questions
+var QuestionListSpec = ListSpec{
+	SearchColumns: []string{"question"},
+	Filters: map[string]ListFilter{
+		"type":  EqualFilter("type"),
+		"score": IntFilter("score"),
+		"tag":   tagFilter("questions.id"),
+	},
+	SortColumns: map[string]string{
+		"id":         "id",
+		"type":       "type",
+		"score":      "score",
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"deleted_at": "deleted_at",
+	},
+	DefaultSort: "id",
+	TieBreaker:  "id",
+}
+
+// List returns a page of questions matching the query and the total number of matches.
+// Questions in the trash are excluded.
+func (r *QuestionRepository) List(q ListQuery) ([]models.Question, int, error) {
+	return r.list(q, false)
+}
+
+// ListDeleted returns a page of questions in the trash, most recently deleted first by default
+func (r *QuestionRepository) ListDeleted(q ListQuery) ([]models.Question, int, error) {
+	if q.Sort == "" {
+		q.Sort, q.Desc = "deleted_at", true
+	}
+	return r.list(q, true)
+}
+
+// list returns a page of either active or deleted questions
+func (r *QuestionRepository) list(q ListQuery, deleted bool) ([]models.Question, int, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "List")
+		return nil, 0, errors.New("database connection not initialized")
+	}
+
+	where, args, err := QuestionListSpec.whereClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := QuestionListSpec.orderClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if deleted {
+		where += ` AND deleted_at IS NOT NULL`
+	} else {
+		where += ` AND deleted_at IS NULL`
+	}
+
+	var total int
+	err = r.DB.QueryRowContext(r.ctx(), `SELECT COUNT(*) FROM questions`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + questionColumns + ` FROM questions` + where + order + ` LIMIT ? OFFSET ?`
+	questions, err := r.queryQuestions(query, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.attachTags(questions); err != nil {
+		return nil, 0, err
+	}
+
+	return questions, total, nil
+}
+
+// queryQuestions runs a query selecting questionColumns and scans every row
+func (r *QuestionRepository) queryQuestions(query string, args ...interface{}) ([]models.Question, error) {
+	rows, err := r.DB.QueryContext(r.ctx(), query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -46,43 +254,11 @@ func (r *QuestionRepository) FindAll() ([]models.Question, error) {
 
 	var questions []models.Question
 	for rows.Next() {
-		var question models.Question
-		var optionsJSON sql.NullString
-		var imageURL sql.NullString
-		var answer sql.NullString
-
-		err := rows.Scan(
-			&question.ID,
-			&question.Type,
-			&question.Question,
-			&optionsJSON,
-			&answer,
-			&imageURL,
-			&question.Score,
-		)
+		question, err := scanQuestion(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		// Parse options JSON if present
-		if optionsJSON.Valid && optionsJSON.String != "" {
-			err = json.Unmarshal([]byte(optionsJSON.String), &question.Options)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		// Set answer if present
-		if answer.Valid {
-			question.Answer = answer.String
-		}
-
-		// Set image URL if present
-		if imageURL.Valid {
-			question.ImageURL = imageURL.String
-		}
-
-		questions = append(questions, question)
+		questions = append(questions, *question)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -92,35 +268,17 @@ func (r *QuestionRepository) FindAll() ([]models.Question, error) {
 	return questions, nil
 }
 
-// FindByID finds a question by ID
+// FindByID finds a question by ID. Questions in the trash are not found.
 func (r *QuestionRepository) FindByID(id uint) (*models.Question, error) {
 	// Check if DB is nil
 	if r.DB == nil {
-		log.Println("ERROR: Database connection is nil in FindByID")
+		r.logger().Error("Database connection is nil", "method", "FindByID")
 		return nil, errors.New("database connection not initialized")
 	}
 
-	query := `
-		SELECT id, type, question, options, answer, image_url, score
-		FROM questions
-		WHERE id = ?
-	`
-
-	var question models.Question
-	var optionsJSON sql.NullString
-	var imageURL sql.NullString
-	var answer sql.NullString
-
-	err := r.DB.QueryRow(query, id).Scan(
-		&question.ID,
-		&question.Type,
-		&question.Question,
-		&optionsJSON,
-		&answer,
-		&imageURL,
-		&question.Score,
-	)
+	query := `SELECT ` + questionColumns + ` FROM questions WHERE id = ? AND deleted_at IS NULL`
 
+	question, err := scanQuestion(r.DB.QueryRowContext(r.ctx(), query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Question not found
@@ -128,59 +286,87 @@ func (r *QuestionRepository) FindByID(id uint) (*models.Question, error) {
 		return nil, err
 	}
 
-	// Parse options JSON if present
-	if optionsJSON.Valid && optionsJSON.String != "" {
-		err = json.Unmarshal([]byte(optionsJSON.String), &question.Options)
-		if err != nil {
-			return nil, err
-		}
+	questions := []models.Question{*question}
+	if err := r.attachTags(questions); err != nil {
+		return nil, err
 	}
 
-	// Set answer if present
-	if answer.Valid {
-		question.Answer = answer.String
+	return &questions[0], nil
+}
+
+// Create creates a new question together with its first version. authorID is
+// recorded on the version; 0 means unknown.
+func (r *QuestionRepository) Create(question *models.Question, authorID uint) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Create")
+		return errors.New("database connection not initialized")
 	}
 
-	// Set image URL if present
-	if imageURL.Valid {
-		question.ImageURL = imageURL.String
+	tx, err := r.DB.BeginTx(r.ctx(), nil)
+	if err != nil {
+		return err
 	}
 
-	return &question, nil
+	if err = createQuestion(tx, question, authorID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// Create creates a new question
-func (r *QuestionRepository) Create(question *models.Question) error {
+// CreateBatch creates several questions in one transaction: either all of them are
+// stored or none is
+func (r *QuestionRepository) CreateBatch(questions []models.Question, authorID uint) error {
 	// Check if DB is nil
 	if r.DB == nil {
-		log.Println("ERROR: Database connection is nil in Create")
+		r.logger().Error("Database connection is nil", "method", "CreateBatch")
 		return errors.New("database connection not initialized")
 	}
 
-	query := `
-		INSERT INTO questions (type, question, options, answer, image_url, score)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`
+	tx, err := r.DB.BeginTx(r.ctx(), nil)
+	if err != nil {
+		return err
+	}
 
-	// Convert options to JSON
-	var optionsJSON []byte
-	var err error
-	if len(question.Options) > 0 {
-		optionsJSON, err = json.Marshal(question.Options)
-		if err != nil {
+	for i := range questions {
+		if err = createQuestion(tx, &questions[i], authorID); err != nil {
+			tx.Rollback()
 			return err
 		}
 	}
 
-	result, err := r.DB.Exec(query,
+	return tx.Commit()
+}
+
+// createQuestion inserts a question with its tags and first version inside tx
+func createQuestion(tx *sql.Tx, question *models.Question, authorID uint) error {
+	query := `
+		INSERT INTO questions (type, question, options, answer, image_url, score, file_rule, search_text, current_version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, 1)
+	`
+
+	// Convert options and file rule to JSON
+	optionsJSON, err := marshalOptions(question.Options)
+	if err != nil {
+		return err
+	}
+	fileRuleJSON, err := marshalFileRule(question.FileRule)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(query,
 		question.Type,
 		question.Question,
 		optionsJSON,
 		sql.NullString{String: question.Answer, Valid: question.Answer != ""},
 		sql.NullString{String: question.ImageURL, Valid: question.ImageURL != ""},
 		question.Score,
+		fileRuleJSON,
+		question.SearchText(),
 	)
-
 	if err != nil {
 		return err
 	}
@@ -191,55 +377,509 @@ func (r *QuestionRepository) Create(question *models.Question) error {
 	}
 
 	question.ID = uint(id)
-	return nil
+	question.Version = 1
+
+	if err = replaceQuestionTags(tx, question.ID, question.Tags); err != nil {
+		return err
+	}
+
+	return insertQuestionVersion(tx, question, optionsJSON, authorID)
 }
 
-// Update updates an existing question
-func (r *QuestionRepository) Update(question *models.Question) error {
+// Update updates an existing question and stores the result as a new version.
+// Earlier versions are never modified.
+func (r *QuestionRepository) Update(question *models.Question, authorID uint) error {
 	// Check if DB is nil
 	if r.DB == nil {
-		log.Println("ERROR: Database connection is nil in Update")
+		r.logger().Error("Database connection is nil", "method", "Update")
 		return errors.New("database connection not initialized")
 	}
 
 	query := `
 		UPDATE questions
-		SET type = ?, question = ?, options = ?, answer = ?, image_url = ?, score = ?
+		SET type = ?, question = ?, options = ?, answer = ?, image_url = ?, score = ?, file_rule = ?,
+		    search_text = ?, current_version = ?
 		WHERE id = ?
 	`
 
-	// Convert options to JSON
-	var optionsJSON []byte
-	var err error
-	if len(question.Options) > 0 {
-		optionsJSON, err = json.Marshal(question.Options)
-		if err != nil {
-			return err
-		}
+	// Convert options and file rule to JSON
+	optionsJSON, err := marshalOptions(question.Options)
+	if err != nil {
+		return err
+	}
+	fileRuleJSON, err := marshalFileRule(question.FileRule)
+	if err != nil {
+		return err
 	}
 
-	_, err = r.DB.Exec(query,
+	tx, err := r.DB.BeginTx(r.ctx(), nil)
+	if err != nil {
+		return err
+	}
+
+	// Lock the row so concurrent edits get consecutive version numbers
+	var currentVersion int
+	err = tx.QueryRow(`SELECT current_version FROM questions WHERE id = ? FOR UPDATE`, question.ID).Scan(&currentVersion)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	question.Version = currentVersion + 1
+
+	_, err = tx.Exec(query,
 		question.Type,
 		question.Question,
 		optionsJSON,
 		sql.NullString{String: question.Answer, Valid: question.Answer != ""},
 		sql.NullString{String: question.ImageURL, Valid: question.ImageURL != ""},
 		question.Score,
+		fileRuleJSON,
+		question.SearchText(),
+		question.Version,
 		question.ID,
 	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = replaceQuestionTags(tx, question.ID, question.Tags); err != nil {
+		tx.Rollback()
+		return err
+	}
 
+	if err = insertQuestionVersion(tx, question, optionsJSON, authorID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// marshalOptions converts options to JSON; no options are stored as NULL
+func marshalOptions(options []string) ([]byte, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(options)
+}
+
+// marshalFileRule converts a file rule to JSON; no rule is stored as NULL
+func marshalFileRule(rule *models.FileRule) ([]byte, error) {
+	if rule == nil {
+		return nil, nil
+	}
+	return json.Marshal(rule)
+}
+
+// unmarshalFileRule parses a file rule column; NULL means no rule
+func unmarshalFileRule(value sql.NullString) (*models.FileRule, error) {
+	if !value.Valid || value.String == "" {
+		return nil, nil
+	}
+	var rule models.FileRule
+	if err := json.Unmarshal([]byte(value.String), &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// replaceQuestionTags replaces the tags of a question inside a transaction
+func replaceQuestionTags(tx *sql.Tx, questionID uint, tags []string) error {
+	if _, err := tx.Exec(`DELETE FROM question_tags WHERE question_id = ?`, questionID); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		if _, err := tx.Exec(`INSERT INTO question_tags (question_id, tag) VALUES (?, ?)`, questionID, tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertQuestionVersion stores the current content of a question as version question.Version
+func insertQuestionVersion(tx *sql.Tx, question *models.Question, optionsJSON []byte, authorID uint) error {
+	tagsJSON, err := json.Marshal(question.Tags)
+	if err != nil {
+		return err
+	}
+	fileRuleJSON, err := marshalFileRule(question.FileRule)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO question_versions (question_id, version, type, question, options, answer, image_url, score, tags, file_rule, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		question.ID,
+		question.Version,
+		question.Type,
+		question.Question,
+		optionsJSON,
+		sql.NullString{String: question.Answer, Valid: question.Answer != ""},
+		sql.NullString{String: question.ImageURL, Valid: question.ImageURL != ""},
+		question.Score,
+		tagsJSON,
+		fileRuleJSON,
+		sql.NullInt64{Int64: int64(authorID), Valid: authorID != 0},
+	)
 	return err
 }
 
-// Delete deletes a question
+// questionVersionColumns is the column list read by scanQuestionVersion
+const questionVersionColumns = `id, question_id, version, type, question, options, answer, image_url, score, tags, file_rule, created_by, created_at`
+
+// scanQuestionVersion reads a version row selected with questionVersionColumns
+func scanQuestionVersion(row rowScanner) (*models.QuestionVersion, error) {
+	var version models.QuestionVersion
+	var optionsJSON, answer, imageURL, tagsJSON, fileRuleJSON sql.NullString
+	var createdBy sql.NullInt64
+
+	err := row.Scan(
+		&version.ID,
+		&version.QuestionID,
+		&version.Version,
+		&version.Type,
+		&version.Question,
+		&optionsJSON,
+		&answer,
+		&imageURL,
+		&version.Score,
+		&tagsJSON,
+		&fileRuleJSON,
+		&createdBy,
+		&version.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if optionsJSON.Valid && optionsJSON.String != "" {
+		if err := json.Unmarshal([]byte(optionsJSON.String), &version.Options); err != nil {
+			return nil, err
+		}
+	}
+	if tagsJSON.Valid && tagsJSON.String != "" {
+		if err := json.Unmarshal([]byte(tagsJSON.String), &version.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if version.FileRule, err = unmarshalFileRule(fileRuleJSON); err != nil {
+		return nil, err
+	}
+	version.Answer = answer.String
+	version.ImageURL = imageURL.String
+	if createdBy.Valid {
+		id := uint(createdBy.Int64)
+		version.CreatedBy = &id
+	}
+
+	return &version, nil
+}
+
+// ListVersions returns every version of a question, newest first
+func (r *QuestionRepository) ListVersions(questionID uint) ([]models.QuestionVersion, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "ListVersions")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `SELECT ` + questionVersionColumns + ` FROM question_versions WHERE question_id = ? ORDER BY version DESC`
+	rows, err := r.DB.QueryContext(r.ctx(), query, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []models.QuestionVersion
+	for rows.Next() {
+		version, err := scanQuestionVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *version)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// FindVersion finds one version of a question
+func (r *QuestionRepository) FindVersion(questionID uint, version int) (*models.QuestionVersion, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindVersion")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `SELECT ` + questionVersionColumns + ` FROM question_versions WHERE question_id = ? AND version = ?`
+	v, err := scanQuestionVersion(r.DB.QueryRowContext(r.ctx(), query, questionID, version))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Version not found
+		}
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// attachTags loads the tags of the given questions
+func (r *QuestionRepository) attachTags(questions []models.Question) error {
+	if len(questions) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(questions))
+	args := make([]interface{}, len(questions))
+	index := make(map[uint]int, len(questions))
+	for i := range questions {
+		placeholders[i] = "?"
+		args[i] = questions[i].ID
+		index[questions[i].ID] = i
+	}
+
+	query := `SELECT question_id, tag FROM question_tags WHERE question_id IN (` +
+		strings.Join(placeholders, ", ") + `) ORDER BY tag`
+	rows, err := r.DB.QueryContext(r.ctx(), query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var questionID uint
+		var tag string
+		if err := rows.Scan(&questionID, &tag); err != nil {
+			return err
+		}
+		if i, ok := index[questionID]; ok {
+			questions[i].Tags = append(questions[i].Tags, tag)
+		}
+	}
+
+	return rows.Err()
+}
+
+// tagFilter matches questions having a tag; idColumn is the question ID column of the outer query
+func tagFilter(idColumn string) ListFilter {
+	return func(value string) (string, []interface{}, error) {
+		condition := `EXISTS (SELECT 1 FROM question_tags qt WHERE qt.question_id = ` + idColumn + ` AND qt.tag = ?)`
+		return condition, []interface{}{strings.ToLower(strings.TrimSpace(value))}, nil
+	}
+}
+
+// usageCountExpr counts the answers given to the question q
+const usageCountExpr = `(SELECT COUNT(*) FROM student_answers sa WHERE sa.question_id = q.id)`
+
+// QuestionSearchSpec lists the filters and sort keys of GET /api/questions/search.
+// Text search itself is done by Search, not by the spec.
+var QuestionSearchSpec = ListSpec{
+	Filters: map[string]ListFilter{
+		"type":  EqualFilter("q.type"),
+		"score": IntFilter("q.score"),
+		"min_score": func(value string) (string, []interface{}, error) {
+			return compareIntFilter("q.score", ">=", value)
+		},
+		"max_score": func(value string) (string, []interface{}, error) {
+			return compareIntFilter("q.score", "<=", value)
+		},
+		"tag": tagFilter("q.id"),
+		"used": func(value string) (string, []interface{}, error) {
+			used, err := strconv.ParseBool(value)
+			if err != nil {
+				return "", nil, fmt.Errorf("%w: %q is not a boolean", ErrInvalidListQuery, value)
+			}
+			exists := `EXISTS (SELECT 1 FROM student_answers sa WHERE sa.question_id = q.id)`
+			if !used {
+				exists = `NOT ` + exists
+			}
+			return exists, nil, nil
+		},
+		"min_usage": func(value string) (string, []interface{}, error) {
+			return compareIntFilter(usageCountExpr, ">=", value)
+		},
+		"max_usage": func(value string) (string, []interface{}, error) {
+			return compareIntFilter(usageCountExpr, "<=", value)
+		},
+	},
+	SortColumns: map[string]string{
+		"relevance":  "relevance",
+		"id":         "q.id",
+		"score":      "q.score",
+		"usage":      "usage_count",
+		"created_at": "q.created_at",
+	},
+	DefaultSort: "relevance",
+	DefaultDesc: true,
+	TieBreaker:  "q.id",
+}
+
+// compareIntFilter builds "<expr> <op> ?" for an integer value
+func compareIntFilter(expr, op, value string) (string, []interface{}, error) {
+	condition, args, err := IntFilter(expr)(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.Replace(condition, " = ", " "+op+" ", 1), args, nil
+}
+
+// errNoFullTextIndex is MySQL error 1191: "Can't find FULLTEXT index matching the column list"
+const errNoFullTextIndex = 1191
+
+// Search finds questions whose text, options or tags contain every term. With fullText the
+// MySQL FULLTEXT index on search_text is used and results are ranked by relevance; otherwise,
+// or when the index is missing, every term is matched with LIKE.
+func (r *QuestionRepository) Search(terms []string, q ListQuery, fullText bool) ([]models.QuestionSearchResult, int, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Search")
+		return nil, 0, errors.New("database connection not initialized")
+	}
+
+	results, total, err := r.search(terms, q, fullText)
+	var mysqlErr *mysql.MySQLError
+	if fullText && errors.As(err, &mysqlErr) && mysqlErr.Number == errNoFullTextIndex {
+		r.logger().Warn("FULLTEXT index on questions.search_text not found, falling back to LIKE search")
+		return r.search(terms, q, false)
+	}
+	return results, total, err
+}
+
+// search runs one search query in the given mode
+func (r *QuestionRepository) search(terms []string, q ListQuery, fullText bool) ([]models.QuestionSearchResult, int, error) {
+	where, args, err := QuestionSearchSpec.whereClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := QuestionSearchSpec.orderClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	where += ` AND q.deleted_at IS NULL`
+
+	relevance := `0`
+	var relevanceArgs []interface{}
+	booleanQuery := search.BooleanQuery(terms)
+	if fullText && booleanQuery != "" {
+		relevance = `MATCH(q.search_text) AGAINST(? IN BOOLEAN MODE)`
+		relevanceArgs = []interface{}{booleanQuery}
+		where += ` AND ` + relevance
+		args = append(args, booleanQuery)
+	} else {
+		for _, term := range terms {
+			where += ` AND q.search_text LIKE ?`
+			args = append(args, "%"+escapeLike(term)+"%")
+		}
+	}
+
+	var total int
+	err = r.DB.QueryRowContext(r.ctx(), `SELECT COUNT(*) FROM questions q`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT q.id, q.type, q.question, q.options, q.answer, q.image_url, q.score, q.file_rule, q.current_version, q.deleted_at, ` +
+		usageCountExpr + ` AS usage_count, ` + relevance + ` AS relevance FROM questions q` +
+		where + order + ` LIMIT ? OFFSET ?`
+	queryArgs := append(append(relevanceArgs, args...), q.Limit, q.Offset)
+
+	rows, err := r.DB.QueryContext(r.ctx(), query, queryArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var results []models.QuestionSearchResult
+	for rows.Next() {
+		var result models.QuestionSearchResult
+		question, err := scanQuestion(searchRow{rows, &result})
+		if err != nil {
+			return nil, 0, err
+		}
+		result.Question = *question
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	questions := make([]models.Question, len(results))
+	for i := range results {
+		questions[i] = results[i].Question
+	}
+	if err := r.attachTags(questions); err != nil {
+		return nil, 0, err
+	}
+	for i := range results {
+		results[i].Tags = questions[i].Tags
+	}
+
+	return results, total, nil
+}
+
+// searchRow scans the question columns of a search row with scanQuestion and
+// the trailing usage_count and relevance columns into result
+type searchRow struct {
+	rows   *sql.Rows
+	result *models.QuestionSearchResult
+}
+
+func (s searchRow) Scan(dest ...interface{}) error {
+	return s.rows.Scan(append(dest, &s.result.UsageCount, &s.result.Relevance)...)
+}
+
+// Delete moves a question to the trash. Its versions and answers are kept until the
+// question is purged.
 func (r *QuestionRepository) Delete(id uint) error {
 	// Check if DB is nil
 	if r.DB == nil {
-		log.Println("ERROR: Database connection is nil in Delete")
+		r.logger().Error("Database connection is nil", "method", "Delete")
 		return errors.New("database connection not initialized")
 	}
 
-	query := `DELETE FROM questions WHERE id = ?`
-	_, err := r.DB.Exec(query, id)
+	query := `UPDATE questions SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+	_, err := r.DB.ExecContext(r.ctx(), query, id)
 	return err
 }
+
+// Restore takes a question out of the trash. It reports false if the question is not in the trash.
+func (r *QuestionRepository) Restore(id uint) (bool, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Restore")
+		return false, errors.New("database connection not initialized")
+	}
+
+	result, err := r.DB.ExecContext(r.ctx(), `UPDATE questions SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// PurgeDeleted permanently deletes questions that were moved to the trash before the given time,
+// together with their versions and answers
+func (r *QuestionRepository) PurgeDeleted(before time.Time) (int64, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "PurgeDeleted")
+		return 0, errors.New("database connection not initialized")
+	}
+
+	return purgeAudited(r.ctx(), r.DB, "questions", "'question', question, 'type', type, 'score', score, 'deleted_at', deleted_at", before, models.AuditQuestionPurge, models.AuditEntityQuestion)
+}
@@ -1,16 +1,21 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"lms-vue-go/backend/config"
 	"lms-vue-go/backend/models"
 	"log"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
 )
 
 // StudentAnswerRepository handles database operations for student answers
 type StudentAnswerRepository struct {
 	DB *sql.DB
+	requestScope
 }
 
 // NewStudentAnswerRepository creates a new student answer repository
@@ -24,29 +29,47 @@ func NewStudentAnswerRepository() *StudentAnswerRepository {
 	}
 }
 
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *StudentAnswerRepository) WithContext(ctx context.Context) *StudentAnswerRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
 // FindByStudentAndQuestion finds an answer by student ID and question ID
 func (r *StudentAnswerRepository) FindByStudentAndQuestion(studentID, questionID uint) (*models.StudentAnswer, error) {
-	query := `
-		SELECT id, student_id, question_id, answer, score
-		FROM student_answers
-		WHERE student_id = ? AND question_id = ?
-	`
+	query := `SELECT ` + answerColumns + ` FROM student_answers WHERE student_id = ? AND question_id = ?`
+
+	answer, err := scanStudentAnswer(r.DB.QueryRowContext(r.ctx(), query, studentID, questionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Answer not found
+		}
+		return nil, err
+	}
 
+	return answer, nil
+}
+
+// answerColumns is the column list read by scanStudentAnswer
+const answerColumns = `id, student_id, question_id, answer, score, COALESCE(feedback, ''), question_version`
+
+// scanStudentAnswer reads an answer row selected with answerColumns
+func scanStudentAnswer(row rowScanner) (*models.StudentAnswer, error) {
 	var answer models.StudentAnswer
 	var score sql.NullInt32
+	var questionVersion sql.NullInt32
 
-	err := r.DB.QueryRow(query, studentID, questionID).Scan(
+	err := row.Scan(
 		&answer.ID,
 		&answer.StudentID,
 		&answer.QuestionID,
 		&answer.Answer,
 		&score,
+		&answer.Feedback,
+		&questionVersion,
 	)
-
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil // Answer not found
-		}
 		return nil, err
 	}
 
@@ -55,19 +78,16 @@ func (r *StudentAnswerRepository) FindByStudentAndQuestion(studentID, questionID
 		scoreInt := int(score.Int32)
 		answer.Score = &scoreInt
 	}
+	answer.QuestionVersion = int(questionVersion.Int32)
 
 	return &answer, nil
 }
 
 // FindByStudent finds all answers for a student
 func (r *StudentAnswerRepository) FindByStudent(studentID uint) ([]models.StudentAnswer, error) {
-	query := `
-		SELECT id, student_id, question_id, answer, score
-		FROM student_answers
-		WHERE student_id = ?
-	`
+	query := `SELECT ` + answerColumns + ` FROM student_answers WHERE student_id = ?`
 
-	rows, err := r.DB.Query(query, studentID)
+	rows, err := r.DB.QueryContext(r.ctx(), query, studentID)
 	if err != nil {
 		return nil, err
 	}
@@ -75,27 +95,11 @@ func (r *StudentAnswerRepository) FindByStudent(studentID uint) ([]models.Studen
 
 	var answers []models.StudentAnswer
 	for rows.Next() {
-		var answer models.StudentAnswer
-		var score sql.NullInt32
-
-		err := rows.Scan(
-			&answer.ID,
-			&answer.StudentID,
-			&answer.QuestionID,
-			&answer.Answer,
-			&score,
-		)
+		answer, err := scanStudentAnswer(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		// Set score if present
-		if score.Valid {
-			scoreInt := int(score.Int32)
-			answer.Score = &scoreInt
-		}
-
-		answers = append(answers, answer)
+		answers = append(answers, *answer)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -107,9 +111,24 @@ func (r *StudentAnswerRepository) FindByStudent(studentID uint) ([]models.Studen
 
 // Create creates a new student answer
 func (r *StudentAnswerRepository) Create(answer *models.StudentAnswer) error {
+	return insertAnswer(r.ctx(), r.DB, answer)
+}
+
+// Update updates an existing student answer
+func (r *StudentAnswerRepository) Update(answer *models.StudentAnswer) error {
+	return updateAnswer(r.ctx(), r.DB, answer)
+}
+
+// answerExecer is implemented by *sql.DB and *sql.Tx
+type answerExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertAnswer inserts answer and sets its ID
+func insertAnswer(ctx context.Context, db answerExecer, answer *models.StudentAnswer) error {
 	query := `
-		INSERT INTO student_answers (student_id, question_id, answer, score)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO student_answers (student_id, question_id, answer, score, question_version)
+		VALUES (?, ?, ?, ?, ?)
 	`
 
 	var scoreSQL sql.NullInt32
@@ -117,11 +136,12 @@ func (r *StudentAnswerRepository) Create(answer *models.StudentAnswer) error {
 		scoreSQL = sql.NullInt32{Int32: int32(*answer.Score), Valid: true}
 	}
 
-	result, err := r.DB.Exec(query,
+	result, err := db.ExecContext(ctx, query,
 		answer.StudentID,
 		answer.QuestionID,
 		answer.Answer,
 		scoreSQL,
+		sql.NullInt32{Int32: int32(answer.QuestionVersion), Valid: answer.QuestionVersion != 0},
 	)
 
 	if err != nil {
@@ -137,11 +157,11 @@ func (r *StudentAnswerRepository) Create(answer *models.StudentAnswer) error {
 	return nil
 }
 
-// Update updates an existing student answer
-func (r *StudentAnswerRepository) Update(answer *models.StudentAnswer) error {
+// updateAnswer updates the text, score and question version of answer
+func updateAnswer(ctx context.Context, db answerExecer, answer *models.StudentAnswer) error {
 	query := `
 		UPDATE student_answers
-		SET answer = ?, score = ?
+		SET answer = ?, score = ?, question_version = ?
 		WHERE id = ?
 	`
 
@@ -150,35 +170,209 @@ func (r *StudentAnswerRepository) Update(answer *models.StudentAnswer) error {
 		scoreSQL = sql.NullInt32{Int32: int32(*answer.Score), Valid: true}
 	}
 
-	_, err := r.DB.Exec(query,
+	_, err := db.ExecContext(ctx, query,
 		answer.Answer,
 		scoreSQL,
+		sql.NullInt32{Int32: int32(answer.QuestionVersion), Valid: answer.QuestionVersion != 0},
 		answer.ID,
 	)
 
 	return err
 }
 
+// ErrFileAttached is returned by Save when a file is already attached to another answer
+var ErrFileAttached = errors.New("file is already attached to another answer")
+
+// errDuplicateEntry is the MySQL error number for a unique key violation
+const errDuplicateEntry = 1062
+
+// Save creates the answer, or updates it when it has an ID, and replaces its attached
+// files with uploadIDs in the same transaction; nil uploadIDs keeps the current files.
+// A submit that fails to attach its files leaves the previous answer untouched, and the
+// unique key on answer_files.upload_id turns a file attached concurrently to another
+// answer into ErrFileAttached.
+func (r *StudentAnswerRepository) Save(answer *models.StudentAnswer, uploadIDs *[]uint) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Save")
+		return errors.New("database connection not initialized")
+	}
+
+	tx, err := r.DB.BeginTx(r.ctx(), nil)
+	if err != nil {
+		return err
+	}
+
+	id := answer.ID
+	if id == 0 {
+		err = insertAnswer(r.ctx(), tx, answer)
+	} else {
+		err = updateAnswer(r.ctx(), tx, answer)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if uploadIDs != nil {
+		if err := setAnswerFiles(r.ctx(), tx, answer.ID, *uploadIDs); err != nil {
+			tx.Rollback()
+			answer.ID = id
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+				return ErrFileAttached
+			}
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		answer.ID = id
+		return err
+	}
+	return nil
+}
+
+// Grade sets the score and teacher feedback of an answer and appends its audit entry
+// in one transaction, so a grade is never changed without a trace
+func (r *StudentAnswerRepository) Grade(answer *models.StudentAnswer, entry *models.AuditLog) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Grade")
+		return errors.New("database connection not initialized")
+	}
+
+	tx, err := r.DB.BeginTx(r.ctx(), nil)
+	if err != nil {
+		return err
+	}
+
+	var scoreSQL sql.NullInt32
+	if answer.Score != nil {
+		scoreSQL = sql.NullInt32{Int32: int32(*answer.Score), Valid: true}
+	}
+	feedback := sql.NullString{String: answer.Feedback, Valid: answer.Feedback != ""}
+	if _, err := tx.Exec(`UPDATE student_answers SET score = ?, feedback = ? WHERE id = ?`, scoreSQL, feedback, answer.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := appendAudit(tx, entry); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
 // Delete deletes a student answer
 func (r *StudentAnswerRepository) Delete(id uint) error {
 	query := `DELETE FROM student_answers WHERE id = ?`
-	_, err := r.DB.Exec(query, id)
+	_, err := r.DB.ExecContext(r.ctx(), query, id)
 	return err
 }
 
-// FindAll returns all student answers with student and question details
-func (r *StudentAnswerRepository) FindAll() ([]models.StudentAnswerWithDetails, error) {
-	query := `
-		SELECT sa.id, sa.student_id, sa.question_id, sa.answer, sa.score,
+// answerDetailsSelect selects answers joined with their student and the question version that was answered
+const answerDetailsSelect = `
+		SELECT sa.id, sa.student_id, sa.question_id, sa.answer, sa.score, COALESCE(sa.feedback, ''),
 		       s.name as student_name, s.class as student_class, s.user_id,
-		       q.question, q.type, q.score as question_score
+		       COALESCE(qv.question, q.question), COALESCE(qv.type, q.type),
+		       COALESCE(qv.score, q.score) as question_score, sa.question_version,
+		       sa.created_at, sa.updated_at
 		FROM student_answers sa
-		JOIN students s ON sa.student_id = s.id
-		JOIN questions q ON sa.question_id = q.id
+		JOIN students s ON sa.student_id = s.id AND s.deleted_at IS NULL
+		JOIN questions q ON sa.question_id = q.id AND q.deleted_at IS NULL
+		LEFT JOIN question_versions qv ON qv.question_id = sa.question_id AND qv.version = sa.question_version`
+
+// FindAll returns all student answers with student and question details
+func (r *StudentAnswerRepository) FindAll() ([]models.StudentAnswerWithDetails, error) {
+	query := answerDetailsSelect + `
 		ORDER BY sa.id DESC
 	`
 
-	rows, err := r.DB.Query(query)
+	return r.queryAnswerDetails(query)
+}
+
+// FindEssayAnswers returns the essay answers of one question, or of every question if questionID is 0
+func (r *StudentAnswerRepository) FindEssayAnswers(questionID uint) ([]models.StudentAnswerWithDetails, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindEssayAnswers")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := answerDetailsSelect + ` WHERE COALESCE(qv.type, q.type) = ?`
+	args := []interface{}{models.Essay}
+	if questionID != 0 {
+		query += ` AND sa.question_id = ?`
+		args = append(args, questionID)
+	}
+	query += ` ORDER BY sa.question_id, sa.id`
+
+	return r.queryAnswerDetails(query, args...)
+}
+
+// AnswerListSpec lists the search, filter and sort fields of GET /api/answers
+var AnswerListSpec = ListSpec{
+	SearchColumns: []string{"sa.answer", "s.name", "q.question"},
+	Filters: map[string]ListFilter{
+		"type":        EqualFilter("q.type"),
+		"class":       EqualFilter("s.class"),
+		"graded":      PresenceFilter("sa.score"),
+		"student_id":  IntFilter("sa.student_id"),
+		"question_id": IntFilter("sa.question_id"),
+	},
+	SortColumns: map[string]string{
+		"id":         "sa.id",
+		"score":      "sa.score",
+		"student":    "s.name",
+		"class":      "s.class",
+		"created_at": "sa.created_at",
+		"updated_at": "sa.updated_at",
+	},
+	DefaultSort: "id",
+	DefaultDesc: true,
+	TieBreaker:  "sa.id",
+}
+
+// List returns a page of student answers with details matching the query and the total number of matches
+func (r *StudentAnswerRepository) List(q ListQuery) ([]models.StudentAnswerWithDetails, int, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "List")
+		return nil, 0, errors.New("database connection not initialized")
+	}
+
+	where, args, err := AnswerListSpec.whereClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := AnswerListSpec.orderClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	countQuery := `
+		SELECT COUNT(*)
+		FROM student_answers sa
+		JOIN students s ON sa.student_id = s.id AND s.deleted_at IS NULL
+		JOIN questions q ON sa.question_id = q.id AND q.deleted_at IS NULL` + where
+
+	var total int
+	if err := r.DB.QueryRowContext(r.ctx(), countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	answers, err := r.queryAnswerDetails(answerDetailsSelect+where+order+` LIMIT ? OFFSET ?`, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return answers, total, nil
+}
+
+// queryAnswerDetails runs a query built on answerDetailsSelect and scans every row
+func (r *StudentAnswerRepository) queryAnswerDetails(query string, args ...interface{}) ([]models.StudentAnswerWithDetails, error) {
+	rows, err := r.DB.QueryContext(r.ctx(), query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -188,6 +382,7 @@ func (r *StudentAnswerRepository) FindAll() ([]models.StudentAnswerWithDetails,
 	for rows.Next() {
 		var answer models.StudentAnswerWithDetails
 		var score sql.NullInt32
+		var questionVersion sql.NullInt32
 
 		err := rows.Scan(
 			&answer.ID,
@@ -195,12 +390,16 @@ func (r *StudentAnswerRepository) FindAll() ([]models.StudentAnswerWithDetails,
 			&answer.QuestionID,
 			&answer.Answer,
 			&score,
+			&answer.Feedback,
 			&answer.StudentName,
 			&answer.StudentClass,
 			&answer.UserID,
 			&answer.QuestionText,
 			&answer.QuestionType,
 			&answer.QuestionScore,
+			&questionVersion,
+			&answer.CreatedAt,
+			&answer.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -211,6 +410,7 @@ func (r *StudentAnswerRepository) FindAll() ([]models.StudentAnswerWithDetails,
 			scoreInt := int(score.Int32)
 			answer.Score = &scoreInt
 		}
+		answer.QuestionVersion = int(questionVersion.Int32)
 
 		answers = append(answers, answer)
 	}
@@ -224,23 +424,9 @@ func (r *StudentAnswerRepository) FindAll() ([]models.StudentAnswerWithDetails,
 
 // FindByID finds a student answer by ID
 func (r *StudentAnswerRepository) FindByID(id uint) (*models.StudentAnswer, error) {
-	query := `
-		SELECT id, student_id, question_id, answer, score
-		FROM student_answers
-		WHERE id = ?
-	`
-
-	var answer models.StudentAnswer
-	var score sql.NullInt32
-
-	err := r.DB.QueryRow(query, id).Scan(
-		&answer.ID,
-		&answer.StudentID,
-		&answer.QuestionID,
-		&answer.Answer,
-		&score,
-	)
+	query := `SELECT ` + answerColumns + ` FROM student_answers WHERE id = ?`
 
+	answer, err := scanStudentAnswer(r.DB.QueryRowContext(r.ctx(), query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Answer not found
@@ -248,11 +434,120 @@ func (r *StudentAnswerRepository) FindByID(id uint) (*models.StudentAnswer, erro
 		return nil, err
 	}
 
-	// Set score if present
-	if score.Valid {
-		scoreInt := int(score.Int32)
-		answer.Score = &scoreInt
+	return answer, nil
+}
+
+// setAnswerFiles replaces the files attached to an answer, keeping the given order
+func setAnswerFiles(ctx context.Context, tx *sql.Tx, answerID uint, uploadIDs []uint) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM answer_files WHERE answer_id = ?`, answerID); err != nil {
+		return err
+	}
+	for i, uploadID := range uploadIDs {
+		_, err := tx.ExecContext(ctx, `INSERT INTO answer_files (answer_id, upload_id, position) VALUES (?, ?, ?)`, answerID, uploadID, i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindFiles returns the files attached to the given answers, keyed by answer ID
+func (r *StudentAnswerRepository) FindFiles(answerIDs []uint) (map[uint][]models.Upload, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindFiles")
+		return nil, errors.New("database connection not initialized")
 	}
 
-	return &answer, nil
+	files := map[uint][]models.Upload{}
+	if len(answerIDs) == 0 {
+		return files, nil
+	}
+
+	placeholders := make([]string, len(answerIDs))
+	args := make([]interface{}, len(answerIDs))
+	for i, id := range answerIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := `
+		SELECT af.answer_id, u.id, u.storage_key, u.filename, u.content_type, u.size, u.purpose, u.uploaded_by, u.created_at
+		FROM answer_files af
+		JOIN uploads u ON u.id = af.upload_id
+		WHERE af.answer_id IN (` + strings.Join(placeholders, ", ") + `)
+		ORDER BY af.answer_id, af.position`
+
+	rows, err := r.DB.QueryContext(r.ctx(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var answerID uint
+		upload, err := scanUpload(answerFileRow{rows, &answerID})
+		if err != nil {
+			return nil, err
+		}
+		files[answerID] = append(files[answerID], *upload)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// answerFileRow scans the leading answer_id column into answerID and the rest with scanUpload
+type answerFileRow struct {
+	rows     *sql.Rows
+	answerID *uint
+}
+
+func (a answerFileRow) Scan(dest ...interface{}) error {
+	return a.rows.Scan(append([]interface{}{a.answerID}, dest...)...)
+}
+
+// FindAnswerIDByFile returns the ID of the answer a file is attached to, or 0 if it is not attached
+func (r *StudentAnswerRepository) FindAnswerIDByFile(uploadID uint) (uint, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindAnswerIDByFile")
+		return 0, errors.New("database connection not initialized")
+	}
+
+	var answerID uint
+	err := r.DB.QueryRowContext(r.ctx(), `SELECT answer_id FROM answer_files WHERE upload_id = ?`, uploadID).Scan(&answerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return answerID, err
+}
+
+// DataVersion returns a value that changes whenever answers, questions or students
+// are added, changed or deleted; cached statistics are valid while it is unchanged.
+// It is checked on every analytics request, so it only reads the row counts and
+// MAX(updated_at), which uses the updated_at index and has microsecond precision
+// (migrations/add_updated_at_precision.sql). An edit committed after a later one
+// can be missed until the cache TTL expires.
+func (r *StudentAnswerRepository) DataVersion() (string, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "DataVersion")
+		return "", errors.New("database connection not initialized")
+	}
+
+	query := `
+		SELECT
+			(SELECT CONCAT(COUNT(*), '@', COALESCE(MAX(updated_at), '')) FROM student_answers),
+			(SELECT CONCAT(COUNT(*), '@', COALESCE(MAX(updated_at), '')) FROM questions),
+			(SELECT CONCAT(COUNT(*), '@', COALESCE(MAX(updated_at), '')) FROM students)`
+
+	var answers, questions, students string
+	if err := r.DB.QueryRowContext(r.ctx(), query).Scan(&answers, &questions, &students); err != nil {
+		return "", err
+	}
+	return answers + "|" + questions + "|" + students, nil
 }
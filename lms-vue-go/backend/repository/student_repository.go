@@ -1,15 +1,18 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"lms-vue-go/backend/config"
 	"lms-vue-go/backend/models"
+	"time"
 )
 
 // StudentRepository handles database operations for students
 type StudentRepository struct {
 	DB *sql.DB
+	requestScope
 }
 
 // NewStudentRepository creates a new student repository
@@ -19,15 +22,23 @@ func NewStudentRepository() *StudentRepository {
 	}
 }
 
-// FindAll returns all students
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *StudentRepository) WithContext(ctx context.Context) *StudentRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// FindAll returns all students that are not in the trash
 func (r *StudentRepository) FindAll() ([]models.Student, error) {
 	query := `
 		SELECT s.id, s.user_id, s.name, s.class, u.email
 		FROM students s
 		JOIN users u ON s.user_id = u.id
+		WHERE s.deleted_at IS NULL
 	`
 
-	rows, err := r.DB.Query(query)
+	rows, err := r.DB.QueryContext(r.ctx(), query)
 	if err != nil {
 		return nil, err
 	}
@@ -56,17 +67,115 @@ func (r *StudentRepository) FindAll() ([]models.Student, error) {
 	return students, nil
 }
 
-// FindByID finds a student by ID
+// StudentListSpec lists the search, filter and sort fields of GET /api/students
+var StudentListSpec = ListSpec{
+	SearchColumns: []string{"s.name", "u.email", "u.username"},
+	Filters: map[string]ListFilter{
+		"class": EqualFilter("s.class"),
+	},
+	SortColumns: map[string]string{
+		"id":         "s.id",
+		"name":       "s.name",
+		"class":      "s.class",
+		"email":      "u.email",
+		"created_at": "s.created_at",
+		"deleted_at": "s.deleted_at",
+	},
+	DefaultSort: "id",
+	TieBreaker:  "s.id",
+}
+
+// List returns a page of students matching the query and the total number of matches.
+// Students in the trash are excluded.
+func (r *StudentRepository) List(q ListQuery) ([]models.Student, int, error) {
+	return r.list(q, false)
+}
+
+// ListDeleted returns a page of students in the trash, most recently deleted first by default
+func (r *StudentRepository) ListDeleted(q ListQuery) ([]models.Student, int, error) {
+	if q.Sort == "" {
+		q.Sort, q.Desc = "deleted_at", true
+	}
+	return r.list(q, true)
+}
+
+// list returns a page of either active or deleted students
+func (r *StudentRepository) list(q ListQuery, deleted bool) ([]models.Student, int, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "List")
+		return nil, 0, errors.New("database connection not initialized")
+	}
+
+	where, args, err := StudentListSpec.whereClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := StudentListSpec.orderClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if deleted {
+		where += ` AND s.deleted_at IS NOT NULL`
+	} else {
+		where += ` AND s.deleted_at IS NULL`
+	}
+
+	from := ` FROM students s JOIN users u ON s.user_id = u.id`
+
+	var total int
+	err = r.DB.QueryRowContext(r.ctx(), `SELECT COUNT(*)`+from+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT s.id, s.user_id, s.name, s.class, u.email, s.deleted_at` + from + where + order + ` LIMIT ? OFFSET ?`
+	rows, err := r.DB.QueryContext(r.ctx(), query, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var students []models.Student
+	for rows.Next() {
+		var student models.Student
+		var deletedAt sql.NullTime
+		err := rows.Scan(
+			&student.ID,
+			&student.UserID,
+			&student.Name,
+			&student.Class,
+			&student.Email,
+			&deletedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		if deletedAt.Valid {
+			student.DeletedAt = &deletedAt.Time
+		}
+		students = append(students, student)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return students, total, nil
+}
+
+// FindByID finds a student by ID. Students in the trash are not found.
 func (r *StudentRepository) FindByID(id uint) (*models.Student, error) {
 	query := `
 		SELECT s.id, s.user_id, s.name, s.class, u.email
 		FROM students s
 		JOIN users u ON s.user_id = u.id
-		WHERE s.id = ?
+		WHERE s.id = ? AND s.deleted_at IS NULL
 	`
 
 	var student models.Student
-	err := r.DB.QueryRow(query, id).Scan(
+	err := r.DB.QueryRowContext(r.ctx(), query, id).Scan(
 		&student.ID,
 		&student.UserID,
 		&student.Name,
@@ -84,17 +193,17 @@ func (r *StudentRepository) FindByID(id uint) (*models.Student, error) {
 	return &student, nil
 }
 
-// FindByUserID finds a student by user ID
+// FindByUserID finds a student by user ID. Students in the trash are not found.
 func (r *StudentRepository) FindByUserID(userID uint) (*models.Student, error) {
 	query := `
 		SELECT s.id, s.user_id, s.name, s.class, u.email
 		FROM students s
 		JOIN users u ON s.user_id = u.id
-		WHERE s.user_id = ?
+		WHERE s.user_id = ? AND s.deleted_at IS NULL
 	`
 
 	var student models.Student
-	err := r.DB.QueryRow(query, userID).Scan(
+	err := r.DB.QueryRowContext(r.ctx(), query, userID).Scan(
 		&student.ID,
 		&student.UserID,
 		&student.Name,
@@ -112,20 +221,30 @@ func (r *StudentRepository) FindByUserID(userID uint) (*models.Student, error) {
 	return &student, nil
 }
 
-// Create creates a new student
+// ErrStudentInTrash is returned by Create when the user's student record is in the trash
+var ErrStudentInTrash = errors.New("student is in the trash")
+
+// Create creates a new student, or updates the user's existing student record
 func (r *StudentRepository) Create(student *models.Student, userID uint) error {
 	// Set the UserID field in the student model
 	student.UserID = userID
 
-	// Check if student record already exists for this user
-	existingStudent, err := r.FindByUserID(userID)
-	if err != nil {
+	// Check if student record already exists for this user, including one in the trash
+	var existingID uint
+	var trashed bool
+	err := r.DB.QueryRowContext(r.ctx(), `SELECT id, deleted_at IS NOT NULL FROM students WHERE user_id = ? ORDER BY deleted_at IS NULL DESC, id LIMIT 1`, userID).Scan(&existingID, &trashed)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return err
 	}
 
+	// A student in the trash is only restored through the admin trash endpoint
+	if err == nil && trashed {
+		return ErrStudentInTrash
+	}
+
 	// If student record already exists, update it instead
-	if existingStudent != nil {
-		student.ID = existingStudent.ID
+	if err == nil {
+		student.ID = existingID
 		return r.Update(student)
 	}
 
@@ -135,7 +254,7 @@ func (r *StudentRepository) Create(student *models.Student, userID uint) error {
 		VALUES (?, ?, ?)
 	`
 
-	result, err := r.DB.Exec(query,
+	result, err := r.DB.ExecContext(r.ctx(), query,
 		userID,
 		student.Name,
 		student.Class,
@@ -167,7 +286,7 @@ func (r *StudentRepository) Update(student *models.Student) error {
 		WHERE id = ?
 	`
 
-	_, err := r.DB.Exec(query,
+	_, err := r.DB.ExecContext(r.ctx(), query,
 		student.Name,
 		student.Class,
 		student.UserID,
@@ -177,9 +296,120 @@ func (r *StudentRepository) Update(student *models.Student) error {
 	return err
 }
 
-// Delete deletes a student
+// Delete moves a student to the trash. Their answers and grades are kept until the student is purged.
 func (r *StudentRepository) Delete(id uint) error {
-	query := `DELETE FROM students WHERE id = ?`
-	_, err := r.DB.Exec(query, id)
+	query := `UPDATE students SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+	_, err := r.DB.ExecContext(r.ctx(), query, id)
 	return err
 }
+
+// Restore takes a student out of the trash. It reports false if the student is not in the trash.
+func (r *StudentRepository) Restore(id uint) (bool, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Restore")
+		return false, errors.New("database connection not initialized")
+	}
+
+	result, err := r.DB.ExecContext(r.ctx(), `UPDATE students SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// PurgeDeleted permanently deletes students that were moved to the trash before the given time,
+// together with their answers
+func (r *StudentRepository) PurgeDeleted(before time.Time) (int64, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "PurgeDeleted")
+		return 0, errors.New("database connection not initialized")
+	}
+
+	return purgeAudited(r.ctx(), r.DB, "students", "'user_id', user_id, 'name', name, 'class', class, 'deleted_at', deleted_at", before, models.AuditStudentPurge, models.AuditEntityStudent)
+}
+
+// CountAnswersByUserID counts the answers belonging to the students linked to a user
+func (r *StudentRepository) CountAnswersByUserID(userID uint) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM student_answers sa
+		JOIN students s ON sa.student_id = s.id
+		WHERE s.user_id = ?
+	`
+
+	var count int
+	err := r.DB.QueryRowContext(r.ctx(), query, userID).Scan(&count)
+	return count, err
+}
+
+// SaveWithUser creates or updates a student account in one transaction. A user with
+// ID 0 is created with role student; otherwise its username and email are updated.
+// The student row linked to the user is updated, restored from the trash or created.
+func (r *StudentRepository) SaveWithUser(user *models.User, student *models.Student) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "SaveWithUser")
+		return errors.New("database connection not initialized")
+	}
+
+	tx, err := r.DB.BeginTx(r.ctx(), nil)
+	if err != nil {
+		return err
+	}
+
+	if user.ID == 0 {
+		user.Role = models.RoleStudent
+		result, err := tx.Exec(`INSERT INTO users (username, password, email, role) VALUES (?, ?, ?, ?)`,
+			user.Username, user.Password, user.Email, user.Role)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		user.ID = uint(id)
+		user.IsActive = true
+	} else {
+		if _, err := tx.Exec(`UPDATE users SET username = ?, email = ? WHERE id = ?`, user.Username, user.Email, user.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	student.UserID = user.ID
+	student.Email = user.Email
+
+	// Reuse the existing row, including one in the trash, so earlier answers stay linked
+	var existingID uint
+	err = tx.QueryRow(`SELECT id FROM students WHERE user_id = ? ORDER BY deleted_at IS NULL DESC, id LIMIT 1`, user.ID).Scan(&existingID)
+	switch {
+	case err == nil:
+		student.ID = existingID
+		_, err = tx.Exec(`UPDATE students SET name = ?, class = ?, deleted_at = NULL WHERE id = ?`, student.Name, student.Class, student.ID)
+	case errors.Is(err, sql.ErrNoRows):
+		var result sql.Result
+		result, err = tx.Exec(`INSERT INTO students (user_id, name, class) VALUES (?, ?, ?)`, user.ID, student.Name, student.Class)
+		if err == nil {
+			var id int64
+			id, err = result.LastInsertId()
+			student.ID = uint(id)
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
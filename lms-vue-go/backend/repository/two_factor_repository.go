@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"log"
+)
+
+// TwoFactorRepository handles database operations for TOTP secrets and recovery codes
+type TwoFactorRepository struct {
+	DB *sql.DB
+	requestScope
+}
+
+// NewTwoFactorRepository creates a new two-factor repository
+func NewTwoFactorRepository() *TwoFactorRepository {
+	// Check if DB is initialized
+	if config.DB == nil {
+		log.Println("WARNING: Database connection is nil in TwoFactorRepository")
+	}
+	return &TwoFactorRepository{
+		DB: config.DB,
+	}
+}
+
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *TwoFactorRepository) WithContext(ctx context.Context) *TwoFactorRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// FindByUserID returns the TOTP settings of a user, or nil when none exist
+func (r *TwoFactorRepository) FindByUserID(userID uint) (*models.UserTOTP, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindByUserID")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at
+		FROM user_totp
+		WHERE user_id = ?
+	`
+
+	var settings models.UserTOTP
+	var enabledAt sql.NullTime
+
+	err := r.DB.QueryRowContext(r.ctx(), query, userID).Scan(
+		&settings.UserID,
+		&settings.Secret,
+		&enabledAt,
+		&settings.LastUsedStep,
+		&settings.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // 2FA not configured
+		}
+		return nil, err
+	}
+
+	// Set enabled time if present
+	if enabledAt.Valid {
+		settings.EnabledAt = &enabledAt.Time
+	}
+
+	return &settings, nil
+}
+
+// SavePendingSecret stores a new, not yet enabled secret for a user
+func (r *TwoFactorRepository) SavePendingSecret(userID uint, secret string) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "SavePendingSecret")
+		return errors.New("database connection not initialized")
+	}
+
+	query := `
+		INSERT INTO user_totp (user_id, secret, enabled_at, last_used_step)
+		VALUES (?, ?, NULL, 0)
+		ON DUPLICATE KEY UPDATE secret = VALUES(secret), enabled_at = NULL, last_used_step = 0
+	`
+	_, err := r.DB.ExecContext(r.ctx(), query, userID, secret)
+	return err
+}
+
+// Enable activates 2FA and replaces the recovery codes in one transaction
+func (r *TwoFactorRepository) Enable(userID uint, step int64, recoveryCodeHashes []string) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Enable")
+		return errors.New("database connection not initialized")
+	}
+
+	tx, err := r.DB.BeginTx(r.ctx(), nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`UPDATE user_totp SET enabled_at = NOW(), last_used_step = ? WHERE user_id = ?`, step, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Disable removes the TOTP secret and recovery codes of a user
+func (r *TwoFactorRepository) Disable(userID uint) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Disable")
+		return errors.New("database connection not initialized")
+	}
+
+	tx, err := r.DB.BeginTx(r.ctx(), nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM user_totp WHERE user_id = ?`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ConsumeStep records that a code for the step was used. It returns false when
+// the step (or a later one) was already used, which blocks code replay.
+func (r *TwoFactorRepository) ConsumeStep(userID uint, step int64) (bool, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "ConsumeStep")
+		return false, errors.New("database connection not initialized")
+	}
+
+	result, err := r.DB.ExecContext(r.ctx(), `UPDATE user_totp SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// ReplaceRecoveryCodes discards all recovery codes of a user and stores new ones
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "ReplaceRecoveryCodes")
+		return errors.New("database connection not initialized")
+	}
+
+	tx, err := r.DB.BeginTx(r.ctx(), nil)
+	if err != nil {
+		return err
+	}
+
+	if err = replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode consumes an unused recovery code. It returns false when no such code exists.
+func (r *TwoFactorRepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "UseRecoveryCode")
+		return false, errors.New("database connection not initialized")
+	}
+
+	query := `UPDATE user_recovery_codes SET used_at = NOW() WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`
+	result, err := r.DB.ExecContext(r.ctx(), query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// CountUnusedRecoveryCodes counts the remaining recovery codes of a user
+func (r *TwoFactorRepository) CountUnusedRecoveryCodes(userID uint) (int, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "CountUnusedRecoveryCodes")
+		return 0, errors.New("database connection not initialized")
+	}
+
+	var count int
+	err := r.DB.QueryRowContext(r.ctx(), `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// replaceRecoveryCodes deletes and inserts recovery codes inside a transaction
+func replaceRecoveryCodes(tx *sql.Tx, userID uint, codeHashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(`INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)`, userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"log"
+)
+
+// UploadRepository handles database operations for uploaded file metadata
+type UploadRepository struct {
+	DB *sql.DB
+	requestScope
+}
+
+// NewUploadRepository creates a new upload repository
+func NewUploadRepository() *UploadRepository {
+	// Check if DB is initialized
+	if config.DB == nil {
+		log.Println("WARNING: Database connection is nil in UploadRepository")
+	}
+	return &UploadRepository{
+		DB: config.DB,
+	}
+}
+
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *UploadRepository) WithContext(ctx context.Context) *UploadRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// uploadColumns is the column list read by scanUpload
+const uploadColumns = `id, storage_key, filename, content_type, size, purpose, uploaded_by, created_at`
+
+// scanUpload scans a row selected with uploadColumns
+func scanUpload(row rowScanner) (*models.Upload, error) {
+	var upload models.Upload
+	var uploadedBy sql.NullInt64
+
+	err := row.Scan(
+		&upload.ID,
+		&upload.StorageKey,
+		&upload.Filename,
+		&upload.ContentType,
+		&upload.Size,
+		&upload.Purpose,
+		&uploadedBy,
+		&upload.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if uploadedBy.Valid {
+		upload.UploadedBy = uint(uploadedBy.Int64)
+	}
+	return &upload, nil
+}
+
+// Create stores the metadata of an uploaded file
+func (r *UploadRepository) Create(upload *models.Upload) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Create")
+		return errors.New("database connection not initialized")
+	}
+
+	query := `
+		INSERT INTO uploads (storage_key, filename, content_type, size, purpose, uploaded_by)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.DB.ExecContext(r.ctx(), query,
+		upload.StorageKey,
+		upload.Filename,
+		upload.ContentType,
+		upload.Size,
+		upload.Purpose,
+		sql.NullInt64{Int64: int64(upload.UploadedBy), Valid: upload.UploadedBy != 0},
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	upload.ID = uint(id)
+	return nil
+}
+
+// FindByID finds an upload by ID
+func (r *UploadRepository) FindByID(id uint) (*models.Upload, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindByID")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `SELECT ` + uploadColumns + ` FROM uploads WHERE id = ?`
+
+	upload, err := scanUpload(r.DB.QueryRowContext(r.ctx(), query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Upload not found
+		}
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// Delete removes the metadata of an upload
+func (r *UploadRepository) Delete(id uint) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Delete")
+		return errors.New("database connection not initialized")
+	}
+
+	_, err := r.DB.ExecContext(r.ctx(), `DELETE FROM uploads WHERE id = ?`, id)
+	return err
+}
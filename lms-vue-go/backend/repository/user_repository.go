@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"lms-vue-go/backend/config"
 	"lms-vue-go/backend/models"
 	"log"
@@ -11,6 +13,7 @@ import (
 // UserRepository handles database operations for users
 type UserRepository struct {
 	DB *sql.DB
+	requestScope
 }
 
 // NewUserRepository creates a new user repository
@@ -24,29 +27,60 @@ func NewUserRepository() *UserRepository {
 	}
 }
 
-// FindByUsername finds a user by username
-func (r *UserRepository) FindByUsername(username string) (*models.User, error) {
-	// Check if DB is nil
-	if r.DB == nil {
-		log.Println("ERROR: Database connection is nil in FindByUsername")
-		return nil, errors.New("database connection not initialized")
-	}
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *UserRepository) WithContext(ctx context.Context) *UserRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
 
-	query := `
-		SELECT id, username, password, email, role
-		FROM users
-		WHERE username = ?
-	`
+// userColumns is the column list read by scanUser
+const userColumns = `id, username, password, email, role, email_verified_at, is_active, created_at, updated_at`
 
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser scans a row selected with userColumns into a user
+func scanUser(row rowScanner) (*models.User, error) {
 	var user models.User
-	err := r.DB.QueryRow(query, username).Scan(
+	var emailVerifiedAt sql.NullTime
+
+	err := row.Scan(
 		&user.ID,
 		&user.Username,
 		&user.Password,
 		&user.Email,
 		&user.Role,
+		&emailVerifiedAt,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set verification time if present
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
 
+	return &user, nil
+}
+
+// FindByUsername finds a user by username
+func (r *UserRepository) FindByUsername(username string) (*models.User, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindByUsername")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = ?`
+
+	user, err := scanUser(r.DB.QueryRowContext(r.ctx(), query, username))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // User not found
@@ -54,32 +88,41 @@ func (r *UserRepository) FindByUsername(username string) (*models.User, error) {
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // FindByID finds a user by ID
 func (r *UserRepository) FindByID(id uint) (*models.User, error) {
 	// Check if DB is nil
 	if r.DB == nil {
-		log.Println("ERROR: Database connection is nil in FindByID")
+		r.logger().Error("Database connection is nil", "method", "FindByID")
 		return nil, errors.New("database connection not initialized")
 	}
 
-	query := `
-		SELECT id, username, password, email, role
-		FROM users
-		WHERE id = ?
-	`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
 
-	var user models.User
-	err := r.DB.QueryRow(query, id).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Password,
-		&user.Email,
-		&user.Role,
-	)
+	user, err := scanUser(r.DB.QueryRowContext(r.ctx(), query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // User not found
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// FindByEmail finds a user by email
+func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindByEmail")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
 
+	user, err := scanUser(r.DB.QueryRowContext(r.ctx(), query, email))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // User not found
@@ -87,14 +130,14 @@ func (r *UserRepository) FindByID(id uint) (*models.User, error) {
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // Create creates a new user
 func (r *UserRepository) Create(user *models.User) error {
 	// Check if DB is nil
 	if r.DB == nil {
-		log.Println("ERROR: Database connection is nil in Create")
+		r.logger().Error("Database connection is nil", "method", "Create")
 		return errors.New("database connection not initialized")
 	}
 
@@ -103,7 +146,7 @@ func (r *UserRepository) Create(user *models.User) error {
 		VALUES (?, ?, ?, ?)
 	`
 
-	result, err := r.DB.Exec(query,
+	result, err := r.DB.ExecContext(r.ctx(), query,
 		user.Username,
 		user.Password,
 		user.Email,
@@ -120,6 +163,7 @@ func (r *UserRepository) Create(user *models.User) error {
 	}
 
 	user.ID = uint(id)
+	user.IsActive = true // New accounts are active by default
 	return nil
 }
 
@@ -127,7 +171,7 @@ func (r *UserRepository) Create(user *models.User) error {
 func (r *UserRepository) Update(user *models.User) error {
 	// Check if DB is nil
 	if r.DB == nil {
-		log.Println("ERROR: Database connection is nil in Update")
+		r.logger().Error("Database connection is nil", "method", "Update")
 		return errors.New("database connection not initialized")
 	}
 
@@ -137,7 +181,7 @@ func (r *UserRepository) Update(user *models.User) error {
 		WHERE id = ?
 	`
 
-	_, err := r.DB.Exec(query,
+	_, err := r.DB.ExecContext(r.ctx(), query,
 		user.Username,
 		user.Password,
 		user.Email,
@@ -148,15 +192,176 @@ func (r *UserRepository) Update(user *models.User) error {
 	return err
 }
 
+// UserListSpec lists the filter and sort fields of GET /api/admin/users
+var UserListSpec = ListSpec{
+	SearchColumns: []string{"username", "email"},
+	Filters: map[string]ListFilter{
+		"role":   roleFilter,
+		"active": BoolFilter("is_active"),
+	},
+	SortColumns: map[string]string{
+		"id":         "id",
+		"username":   "username",
+		"email":      "email",
+		"role":       "role",
+		"created_at": "created_at",
+	},
+	DefaultSort: "id",
+	TieBreaker:  "id",
+}
+
+// roleFilter matches users with a valid role
+func roleFilter(value string) (string, []interface{}, error) {
+	if !models.IsValidRole(models.Role(value)) {
+		return "", nil, fmt.Errorf("%w: unknown role %q", ErrInvalidListQuery, value)
+	}
+	return `role = ?`, []interface{}{value}, nil
+}
+
+// List returns a page of users matching the query and the total number of matches
+func (r *UserRepository) List(q ListQuery) ([]models.User, int, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "List")
+		return nil, 0, errors.New("database connection not initialized")
+	}
+
+	where, args, err := UserListSpec.whereClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := UserListSpec.orderClause(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.DB.QueryRowContext(r.ctx(), `SELECT COUNT(*) FROM users`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users` + where + order + ` LIMIT ? OFFSET ?`
+	rows, err := r.DB.QueryContext(r.ctx(), query, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, *user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// CountActiveByRole counts active users with a role
+func (r *UserRepository) CountActiveByRole(role models.Role) (int, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "CountActiveByRole")
+		return 0, errors.New("database connection not initialized")
+	}
+
+	var count int
+	err := r.DB.QueryRowContext(r.ctx(), `SELECT COUNT(*) FROM users WHERE role = ? AND is_active = TRUE`, role).Scan(&count)
+	return count, err
+}
+
+// UpdateRole changes the role of a user
+func (r *UserRepository) UpdateRole(id uint, role models.Role) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "UpdateRole")
+		return errors.New("database connection not initialized")
+	}
+
+	_, err := r.DB.ExecContext(r.ctx(), `UPDATE users SET role = ? WHERE id = ?`, role, id)
+	return err
+}
+
+// SetActive enables or disables a user account
+func (r *UserRepository) SetActive(id uint, active bool) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "SetActive")
+		return errors.New("database connection not initialized")
+	}
+
+	_, err := r.DB.ExecContext(r.ctx(), `UPDATE users SET is_active = ? WHERE id = ?`, active, id)
+	return err
+}
+
+// DeleteWithStudent deletes a user together with the linked students rows in one transaction.
+// Answers of those students are removed by the student_answers foreign key cascade.
+func (r *UserRepository) DeleteWithStudent(id uint) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "DeleteWithStudent")
+		return errors.New("database connection not initialized")
+	}
+
+	tx, err := r.DB.BeginTx(r.ctx(), nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM students WHERE user_id = ?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM users WHERE id = ?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 // Delete deletes a user
 func (r *UserRepository) Delete(id uint) error {
 	// Check if DB is nil
 	if r.DB == nil {
-		log.Println("ERROR: Database connection is nil in Delete")
+		r.logger().Error("Database connection is nil", "method", "Delete")
 		return errors.New("database connection not initialized")
 	}
 
 	query := `DELETE FROM users WHERE id = ?`
-	_, err := r.DB.Exec(query, id)
+	_, err := r.DB.ExecContext(r.ctx(), query, id)
+	return err
+}
+
+// UpdatePassword replaces the password of a user
+func (r *UserRepository) UpdatePassword(id uint, password string) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "UpdatePassword")
+		return errors.New("database connection not initialized")
+	}
+
+	query := `UPDATE users SET password = ? WHERE id = ?`
+	_, err := r.DB.ExecContext(r.ctx(), query, password, id)
+	return err
+}
+
+// MarkEmailVerified records that the user confirmed their email address
+func (r *UserRepository) MarkEmailVerified(id uint) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "MarkEmailVerified")
+		return errors.New("database connection not initialized")
+	}
+
+	query := `UPDATE users SET email_verified_at = NOW() WHERE id = ? AND email_verified_at IS NULL`
+	_, err := r.DB.ExecContext(r.ctx(), query, id)
 	return err
 }
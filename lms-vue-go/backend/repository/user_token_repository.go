@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"log"
+)
+
+// UserTokenRepository handles database operations for single-use user tokens
+type UserTokenRepository struct {
+	DB *sql.DB
+	requestScope
+}
+
+// NewUserTokenRepository creates a new user token repository
+func NewUserTokenRepository() *UserTokenRepository {
+	// Check if DB is initialized
+	if config.DB == nil {
+		log.Println("WARNING: Database connection is nil in UserTokenRepository")
+	}
+	return &UserTokenRepository{
+		DB: config.DB,
+	}
+}
+
+// WithContext returns a copy of the repository that works for the request of ctx
+func (r *UserTokenRepository) WithContext(ctx context.Context) *UserTokenRepository {
+	scoped := *r
+	scoped.reqCtx = ctx
+	return &scoped
+}
+
+// Create stores a new token
+func (r *UserTokenRepository) Create(token *models.UserToken) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "Create")
+		return errors.New("database connection not initialized")
+	}
+
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	result, err := r.DB.ExecContext(r.ctx(), query,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	token.ID = uint(id)
+	return nil
+}
+
+// FindValid finds an unused, unexpired token by hash and purpose
+func (r *UserTokenRepository) FindValid(tokenHash string, purpose models.TokenPurpose) (*models.UserToken, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "FindValid")
+		return nil, errors.New("database connection not initialized")
+	}
+
+	query := `
+		SELECT id, user_id, purpose, token_hash, expires_at, created_at
+		FROM user_tokens
+		WHERE token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > NOW()
+	`
+
+	var token models.UserToken
+	err := r.DB.QueryRowContext(r.ctx(), query, tokenHash, purpose).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Token not found, used or expired
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkUsed consumes a token. It returns false when the token was already used,
+// so two concurrent requests cannot both redeem the same token.
+func (r *UserTokenRepository) MarkUsed(id uint) (bool, error) {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "MarkUsed")
+		return false, errors.New("database connection not initialized")
+	}
+
+	query := `UPDATE user_tokens SET used_at = NOW() WHERE id = ? AND used_at IS NULL`
+	result, err := r.DB.ExecContext(r.ctx(), query, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// InvalidateForUser marks every outstanding token of a purpose as used
+func (r *UserTokenRepository) InvalidateForUser(userID uint, purpose models.TokenPurpose) error {
+	// Check if DB is nil
+	if r.DB == nil {
+		r.logger().Error("Database connection is nil", "method", "InvalidateForUser")
+		return errors.New("database connection not initialized")
+	}
+
+	query := `UPDATE user_tokens SET used_at = NOW() WHERE user_id = ? AND purpose = ? AND used_at IS NULL`
+	_, err := r.DB.ExecContext(r.ctx(), query, userID, purpose)
+	return err
+}
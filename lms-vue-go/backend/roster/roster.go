@@ -0,0 +1,223 @@
+// Package roster validates student rosters read from CSV or XLSX files before
+// they are imported. It knows nothing about the database: matching rows with
+// existing accounts is left to the caller.
+package roster
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// Limits of the columns, matching the users and students tables
+const (
+	MaxRows           = 5000
+	MaxNameLength     = 100
+	MaxClassLength    = 20
+	MaxEmailLength    = 100
+	MaxUsernameLength = 50
+	MinUsernameLength = 3
+)
+
+// ErrMissingColumns is returned when the header row lacks a required column
+var ErrMissingColumns = errors.New("missing required columns")
+
+// Entry is a valid roster row
+type Entry struct {
+	Row      int    `json:"row"` // 1-based line number in the file, the header is row 1
+	Name     string `json:"name"`
+	Class    string `json:"class"`
+	Email    string `json:"email"`
+	Username string `json:"username,omitempty"` // Empty if the file leaves it blank
+}
+
+// RowError describes why a row was rejected
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// columnAliases maps accepted header names to fields
+var columnAliases = map[string]string{
+	"name": "name", "nama": "name", "nama lengkap": "name", "full name": "name", "nama siswa": "name",
+	"class": "class", "kelas": "class",
+	"email": "email", "e-mail": "email", "surel": "email",
+	"username": "username", "user": "username", "nama pengguna": "username",
+}
+
+// requiredColumns must be present in the header
+var requiredColumns = []string{"name", "class", "email"}
+
+// usernamePattern is the set of characters allowed in usernames
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// Parse maps the header row to columns and validates every data row. Rows with
+// errors are left out of the entries. Email addresses and usernames must be
+// unique within the file; later duplicates are rejected.
+func Parse(rows [][]string) ([]Entry, []RowError, error) {
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("%w: the file is empty", ErrMissingColumns)
+	}
+	if len(rows)-1 > MaxRows {
+		return nil, nil, fmt.Errorf("the file has more than %d rows", MaxRows)
+	}
+
+	columns := map[string]int{}
+	for i, header := range rows[0] {
+		field, ok := columnAliases[strings.ToLower(strings.TrimSpace(header))]
+		if _, seen := columns[field]; ok && !seen {
+			columns[field] = i
+		}
+	}
+	var missing []string
+	for _, field := range requiredColumns {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrMissingColumns, strings.Join(missing, ", "))
+	}
+
+	cell := func(row []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var entries []Entry
+	var rowErrors []RowError
+	emails := map[string]int{}
+	usernames := map[string]int{}
+
+	for i, row := range rows[1:] {
+		entry := Entry{
+			Row:      i + 2,
+			Name:     strings.Join(strings.Fields(cell(row, "name")), " "),
+			Class:    cell(row, "class"),
+			Email:    strings.ToLower(cell(row, "email")),
+			Username: cell(row, "username"),
+		}
+		if entry.Name == "" && entry.Class == "" && entry.Email == "" && entry.Username == "" {
+			continue // Blank lines are ignored
+		}
+
+		errs := validate(entry)
+		if first, ok := emails[entry.Email]; ok && entry.Email != "" {
+			errs = append(errs, RowError{Row: entry.Row, Field: "email", Message: fmt.Sprintf("duplicate of row %d", first)})
+		}
+		key := strings.ToLower(entry.Username)
+		if first, ok := usernames[key]; ok && key != "" {
+			errs = append(errs, RowError{Row: entry.Row, Field: "username", Message: fmt.Sprintf("duplicate of row %d", first)})
+		}
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, errs...)
+			continue
+		}
+
+		emails[entry.Email] = entry.Row
+		if key != "" {
+			usernames[key] = entry.Row
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rowErrors, nil
+}
+
+// validate checks the fields of one row
+func validate(e Entry) []RowError {
+	var errs []RowError
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, RowError{Row: e.Row, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case e.Name == "":
+		fail("name", "is required")
+	case len([]rune(e.Name)) > MaxNameLength:
+		fail("name", "is longer than %d characters", MaxNameLength)
+	}
+
+	switch {
+	case e.Class == "":
+		fail("class", "is required")
+	case len([]rune(e.Class)) > MaxClassLength:
+		fail("class", "is longer than %d characters", MaxClassLength)
+	}
+
+	switch {
+	case e.Email == "":
+		fail("email", "is required")
+	case len(e.Email) > MaxEmailLength:
+		fail("email", "is longer than %d characters", MaxEmailLength)
+	case !validEmail(e.Email):
+		fail("email", "%q is not a valid email address", e.Email)
+	}
+
+	if e.Username != "" {
+		switch {
+		case len(e.Username) < MinUsernameLength || len(e.Username) > MaxUsernameLength:
+			fail("username", "must be %d to %d characters", MinUsernameLength, MaxUsernameLength)
+		case !usernamePattern.MatchString(e.Username):
+			fail("username", "may only contain letters, digits, '.', '_' and '-'")
+		}
+	}
+
+	return errs
+}
+
+// validEmail reports whether s is a bare email address
+func validEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && strings.Contains(s[strings.LastIndex(s, "@"):], ".")
+}
+
+// UsernameFromEmail suggests a username from the local part of an email address.
+// The caller must still make it unique, for example with a numeric suffix.
+func UsernameFromEmail(email string) string {
+	local := strings.ToLower(email)
+	if i := strings.LastIndex(local, "@"); i >= 0 {
+		local = local[:i]
+	}
+
+	var b strings.Builder
+	for _, ch := range local {
+		if ch < 128 && usernamePattern.MatchString(string(ch)) {
+			b.WriteRune(ch)
+		}
+	}
+
+	username := b.String()
+	if len(username) < MinUsernameLength {
+		username = "siswa" + username
+	}
+	if len(username) > MaxUsernameLength-4 {
+		username = username[:MaxUsernameLength-4] // Leave room for a suffix
+	}
+	return username
+}
+
+// passwordAlphabet leaves out characters that are easily confused when read aloud or printed
+const passwordAlphabet = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GeneratePassword returns a random initial password of the given length
+func GeneratePassword(length int) (string, error) {
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
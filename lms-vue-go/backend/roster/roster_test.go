@@ -0,0 +1,68 @@
+package roster
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	rows := [][]string{
+		{"Nama", "Kelas", "Email", "Username"},
+		{"  Budi   Santoso ", "X-1", "Budi@Example.com", ""},
+		{"", "X-1", "bukan-email", "ab"},
+		{"", "", "", ""},
+		{"Sari", "X-2", "budi@example.com", "sari"},
+		{"Rina", "X-2", "rina@example.com", "SARI"},
+		{"Andi", "X-3", "andi@example.com", "andi.p"},
+	}
+
+	entries, rowErrors, err := Parse(rows)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := []Entry{
+		{Row: 2, Name: "Budi Santoso", Class: "X-1", Email: "budi@example.com"},
+		{Row: 6, Name: "Rina", Class: "X-2", Email: "rina@example.com", Username: "SARI"},
+		{Row: 7, Name: "Andi", Class: "X-3", Email: "andi@example.com", Username: "andi.p"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v\nwant %+v", entries, want)
+	}
+
+	// Row 3: name, email and username; row 5: duplicate email
+	fields := map[int][]string{}
+	for _, e := range rowErrors {
+		fields[e.Row] = append(fields[e.Row], e.Field)
+	}
+	if !reflect.DeepEqual(fields, map[int][]string{3: {"name", "email", "username"}, 5: {"email"}}) {
+		t.Errorf("row errors = %+v", rowErrors)
+	}
+}
+
+func TestParseMissingColumns(t *testing.T) {
+	_, _, err := Parse([][]string{{"nama", "email"}})
+	if !errors.Is(err, ErrMissingColumns) {
+		t.Errorf("Parse error = %v, want ErrMissingColumns", err)
+	}
+}
+
+func TestUsernameFromEmail(t *testing.T) {
+	cases := map[string]string{
+		"Budi.Santoso+x@example.com": "budi.santosox",
+		"ab@example.com":             "siswaab",
+	}
+	for email, want := range cases {
+		if got := UsernameFromEmail(email); got != want {
+			t.Errorf("UsernameFromEmail(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	password, err := GeneratePassword(10)
+	if err != nil || len(password) != 10 {
+		t.Fatalf("GeneratePassword = %q, %v", password, err)
+	}
+}
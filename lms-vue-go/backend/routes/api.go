@@ -1,10 +1,11 @@
 package routes
 
 import (
+	"log"
 	"net/http"
-	"strconv"
 	"time"
 
+	"lms-vue-go/backend/config"
 	"lms-vue-go/backend/handlers"
 	"lms-vue-go/backend/middleware"
 	"lms-vue-go/backend/models"
@@ -16,7 +17,15 @@ import (
 
 // SetupRouter mengatur semua endpoint API
 func SetupRouter() *gin.Engine {
-	r := gin.Default()
+	// Log akses dan panic dicatat sebagai log terstruktur yang memuat request ID
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.AccessLog(), middleware.Recovery())
+
+	// Hanya percaya X-Forwarded-For dari proxy yang dikonfigurasi, karena IP klien
+	// dipakai untuk membatasi percobaan login
+	if err := r.SetTrustedProxies(config.DefaultServerConfig().TrustedProxies); err != nil {
+		log.Printf("Error setting trusted proxies: %v", err)
+	}
 
 	// Tambahkan middleware security headers
 	r.Use(middleware.SecurityHeaders())
@@ -92,7 +101,7 @@ func SetupRouter() *gin.Engine {
 	// Public endpoint for all student answers (admin only)
 	r.GET("/api/public-all-answers", func(c *gin.Context) {
 		// Initialize repositories
-		studentAnswerRepo := repository.NewStudentAnswerRepository()
+		studentAnswerRepo := repository.NewStudentAnswerRepository().WithContext(c.Request.Context())
 
 		// Get all student answers with details
 		answers, err := studentAnswerRepo.FindAll()
@@ -104,55 +113,6 @@ func SetupRouter() *gin.Engine {
 		c.JSON(http.StatusOK, gin.H{"data": answers})
 	})
 
-	// Public endpoint for grading student answers
-	r.PUT("/api/public-grade-answer/:id", func(c *gin.Context) {
-		// Get answer ID from URL parameter
-		answerIDStr := c.Param("id")
-		answerID, err := strconv.ParseUint(answerIDStr, 10, 64)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid answer ID"})
-			return
-		}
-
-		// Parse request body
-		var req struct {
-			Score int `json:"score" binding:"required"`
-		}
-
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
-			return
-		}
-
-		// Initialize repository
-		studentAnswerRepo := repository.NewStudentAnswerRepository()
-
-		// Find the answer by ID
-		answer, err := studentAnswerRepo.FindByID(uint(answerID))
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch student answer"})
-			return
-		}
-
-		if answer == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Answer not found"})
-			return
-		}
-
-		// Update the score
-		answer.Score = &req.Score
-		err = studentAnswerRepo.Update(answer)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update answer score"})
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"data":    answer,
-			"message": "Answer graded successfully",
-		})
-	})
-
 	// Grup untuk API
 	api := r.Group("/api")
 	{
@@ -161,10 +121,70 @@ func SetupRouter() *gin.Engine {
 		{
 			auth.POST("/login", handlers.Login)
 			auth.POST("/register", handlers.Register)
+			auth.POST("/forgot-password", handlers.ForgotPassword)
+			auth.POST("/reset-password", handlers.ResetPassword)
+			auth.POST("/verify-email", handlers.VerifyEmail)
+			auth.POST("/resend-verification", middleware.AuthMiddleware(), handlers.ResendVerificationEmail)
+
+			// Single sign-on melalui identity provider sekolah (OpenID Connect)
+			auth.GET("/oidc/login", handlers.OIDCLogin)
+			auth.GET("/oidc/callback", handlers.OIDCCallback)
+
+			// Langkah kedua login dan pendaftaran 2FA menggunakan challenge token
+			auth.POST("/2fa/verify", handlers.VerifyTwoFactor)
+			auth.POST("/2fa/enroll/setup", handlers.EnrollTwoFactorSetup)
+			auth.POST("/2fa/enroll/activate", handlers.EnrollTwoFactorActivate)
+
+			// Pengelolaan 2FA untuk pengguna yang sudah login
+			twoFactor := auth.Group("/2fa", middleware.AuthMiddleware(), middleware.SessionOnly())
+			{
+				twoFactor.GET("/status", handlers.GetTwoFactorStatus)
+				twoFactor.POST("/setup", handlers.SetupTwoFactor)
+				twoFactor.POST("/enable", handlers.EnableTwoFactor)
+				twoFactor.POST("/disable", handlers.DisableTwoFactor)
+				twoFactor.POST("/recovery-codes", handlers.RegenerateRecoveryCodes)
+			}
+
+			// Token API pribadi untuk skrip dan integrasi (hanya dengan login biasa)
+			apiTokens := auth.Group("/tokens", middleware.AuthMiddleware(), middleware.SessionOnly())
+			{
+				apiTokens.GET("/", handlers.ListAPITokens)
+				apiTokens.POST("/", handlers.CreateAPIToken)
+				apiTokens.DELETE("/:id", handlers.RevokeAPIToken)
+			}
 			// Route untuk mendapatkan data user saat ini (perlu middleware auth)
 			auth.GET("/me", middleware.AuthMiddleware(), handlers.GetCurrentUser)
 		}
 
+		// Routes untuk administrasi pengguna (hanya admin)
+		adminUsers := api.Group("/admin/users", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleAdmin))
+		{
+			adminUsers.GET("/", handlers.AdminListUsers)
+			adminUsers.GET("/:id", handlers.AdminGetUser)
+			adminUsers.PUT("/:id/role", handlers.AdminUpdateUserRole)
+			adminUsers.PUT("/:id/status", handlers.AdminUpdateUserStatus)
+			adminUsers.POST("/:id/reset-password", handlers.AdminResetUserPassword)
+			adminUsers.DELETE("/:id", handlers.AdminDeleteUser)
+			adminUsers.POST("/:id/unlock", handlers.AdminUnlockUser)
+		}
+		api.DELETE("/admin/lockouts/ip/:ip", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleAdmin), handlers.AdminUnlockIP)
+
+		// Log audit perubahan nilai, soal, siswa dan role (hanya admin)
+		auditLog := api.Group("/admin/audit", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleAdmin))
+		{
+			auditLog.GET("/", handlers.ListAuditLogs)
+			auditLog.GET("/verify", handlers.VerifyAuditLog)
+		}
+
+		// Tempat sampah: soal dan siswa yang dihapus dapat dipulihkan sebelum dihapus permanen
+		trash := api.Group("/admin/trash", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleAdmin))
+		{
+			trash.GET("/questions", handlers.ListTrashedQuestions)
+			trash.GET("/students", handlers.ListTrashedStudents)
+			trash.POST("/questions/:id/restore", handlers.RestoreQuestion)
+			trash.POST("/students/:id/restore", handlers.RestoreStudent)
+		}
+
 		// Routes untuk siswa (perlu middleware auth)
 		students := api.Group("/students", middleware.AuthMiddleware())
 		{
@@ -173,15 +193,62 @@ func SetupRouter() *gin.Engine {
 			students.GET("/:id", handlers.GetStudentByID)
 			// Endpoint untuk mendapatkan profil siswa sendiri (hanya untuk siswa)
 			students.GET("/profile/me", handlers.GetCurrentStudentProfile)
+			// Siswa dapat melihat perkembangan nilainya sendiri
+			students.GET("/progress/me", handlers.GetMyProgress)
 			// Hanya admin dan guru yang dapat mengelola data siswa
 			studentAdmin := students.Group("/", middleware.RoleMiddleware(models.RoleAdmin, models.RoleTeacher))
 			{
 				studentAdmin.POST("/", handlers.CreateStudent)
+				studentAdmin.POST("/import", handlers.ImportStudents)
 				studentAdmin.PUT("/:id", handlers.UpdateStudent)
 				studentAdmin.DELETE("/:id", handlers.DeleteStudent)
 			}
 		}
 
+		// Routes untuk unggah file (gambar soal dan lampiran)
+		uploads := api.Group("/uploads", middleware.AuthMiddleware())
+		{
+			uploads.POST("/", handlers.UploadFile)
+			uploads.GET("/:id", handlers.GetUpload)
+			uploads.DELETE("/:id", handlers.DeleteUpload)
+		}
+		// Isi file; gambar soal bersifat publik, file lain memerlukan link bertanda tangan
+		api.GET("/files/:id", handlers.ServeFile)
+
+		// Routes untuk ekspor nilai dan rapor
+		grades := api.Group("/grades", middleware.AuthMiddleware())
+		{
+			// Siswa dapat mengunduh rapor sendiri
+			grades.GET("/report-cards/me", handlers.GetMyReportCard)
+			// Hanya admin dan guru yang dapat mengekspor nilai semua siswa
+			gradeAdmin := grades.Group("/", middleware.RoleMiddleware(models.RoleAdmin, models.RoleTeacher))
+			{
+				gradeAdmin.GET("/export", handlers.ExportGrades)
+				gradeAdmin.GET("/report-cards", handlers.GetReportCards)
+			}
+		}
+
+		// Ringkasan dashboard guru (hanya admin dan guru)
+		api.GET("/dashboard", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleAdmin, models.RoleTeacher), handlers.GetTeacherDashboard)
+
+		// Routes untuk jadwal ujian yang batas waktunya tampil di dashboard (hanya admin dan guru)
+		examSchedules := api.Group("/exam-schedules", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleAdmin, models.RoleTeacher))
+		{
+			examSchedules.GET("/", handlers.ListExamSchedules)
+			examSchedules.POST("/", handlers.CreateExamSchedule)
+			examSchedules.PUT("/:id", handlers.UpdateExamSchedule)
+			examSchedules.DELETE("/:id", handlers.DeleteExamSchedule)
+		}
+
+		// Routes untuk analisis soal dan nilai (hanya admin dan guru)
+		analytics := api.Group("/analytics", middleware.AuthMiddleware(), middleware.RoleMiddleware(models.RoleAdmin, models.RoleTeacher))
+		{
+			analytics.GET("/items", handlers.GetItemAnalysis)
+			analytics.GET("/exam", handlers.GetExamStatistics)
+			analytics.GET("/students/:id/progress", handlers.GetStudentProgress)
+			analytics.GET("/similarity", handlers.GetAnswerSimilarity)
+		}
+
 		// Add a public endpoint for student answers with CORS headers
 		api.GET("/public/answers/my", func(c *gin.Context) {
 			// Set CORS headers
@@ -206,15 +273,15 @@ func SetupRouter() *gin.Engine {
 			}
 
 			// Validate token and get user ID
-			userID, err := middleware.ValidateToken(token)
+			userID, err := middleware.ValidateToken(c.Request.Context(), token)
 			if err != nil {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 				return
 			}
 
 			// Initialize repositories
-			studentRepo := repository.NewStudentRepository()
-			studentAnswerRepo := repository.NewStudentAnswerRepository()
+			studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
+			studentAnswerRepo := repository.NewStudentAnswerRepository().WithContext(c.Request.Context())
 
 			// Find student by user ID
 			student, err := studentRepo.FindByUserID(userID)
@@ -270,7 +337,7 @@ func SetupRouter() *gin.Engine {
 			}
 
 			// Validate token and get user ID
-			userID, err := middleware.ValidateToken(token)
+			userID, err := middleware.ValidateToken(c.Request.Context(), token)
 			if err != nil {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 				return
@@ -289,9 +356,9 @@ func SetupRouter() *gin.Engine {
 			}
 
 			// Initialize repositories
-			studentRepo := repository.NewStudentRepository()
-			studentAnswerRepo := repository.NewStudentAnswerRepository()
-			questionRepo := repository.NewQuestionRepository()
+			studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
+			studentAnswerRepo := repository.NewStudentAnswerRepository().WithContext(c.Request.Context())
+			questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
 
 			// Find student by user ID
 			student, err := studentRepo.FindByUserID(userID)
@@ -340,6 +407,7 @@ func SetupRouter() *gin.Engine {
 			if existingAnswer != nil {
 				existingAnswer.Answer = req.Answer
 				existingAnswer.Score = score
+				existingAnswer.QuestionVersion = question.Version
 				err = studentAnswerRepo.Update(existingAnswer)
 				if err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update answer"})
@@ -351,10 +419,11 @@ func SetupRouter() *gin.Engine {
 
 			// Create new answer
 			newAnswer := models.StudentAnswer{
-				StudentID:  student.ID,
-				QuestionID: req.QuestionID,
-				Answer:     req.Answer,
-				Score:      score,
+				StudentID:       student.ID,
+				QuestionID:      req.QuestionID,
+				Answer:          req.Answer,
+				Score:           score,
+				QuestionVersion: question.Version,
 			}
 
 			err = studentAnswerRepo.Create(&newAnswer)
@@ -382,6 +451,8 @@ func SetupRouter() *gin.Engine {
 			answers.GET("/my/question/:questionId", handlers.GetStudentAnswerByQuestion)
 			// Siswa dapat mengirimkan jawaban
 			answers.POST("/submit", handlers.SubmitStudentAnswer)
+			// Siswa mengunggah file jawaban esai lalu mengirim ID-nya lewat file_ids
+			answers.POST("/files", handlers.UploadAnswerFile)
 
 			// Hanya admin dan guru yang dapat melihat semua jawaban dan memberikan nilai
 			answerAdmin := answers.Group("/", middleware.RoleMiddleware(models.RoleAdmin, models.RoleTeacher))
@@ -391,6 +462,20 @@ func SetupRouter() *gin.Engine {
 			}
 		}
 
+		// Routes untuk pengawasan ujian (perlu middleware auth)
+		proctor := api.Group("/proctoring", middleware.AuthMiddleware())
+		{
+			// Browser siswa mengirim kejadian selama ujian
+			proctor.POST("/events", middleware.RoleMiddleware(models.RoleStudent), handlers.RecordProctoringEvents)
+
+			// Hanya admin dan guru yang dapat melihat linimasa dan skor kecurigaan
+			proctorAdmin := proctor.Group("/", middleware.RoleMiddleware(models.RoleAdmin, models.RoleTeacher))
+			{
+				proctorAdmin.GET("/students/:id/sessions", handlers.GetProctoringSessions)
+				proctorAdmin.GET("/students/:id/sessions/:session", handlers.GetProctoringSession)
+			}
+		}
+
 		// Add a public endpoint for questions that doesn't require authentication
 		api.GET("/public/questions", func(c *gin.Context) {
 			// Set CORS headers
@@ -399,7 +484,7 @@ func SetupRouter() *gin.Engine {
 			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept")
 
 			// Initialize repository
-			questionRepo := repository.NewQuestionRepository()
+			questionRepo := repository.NewQuestionRepository().WithContext(c.Request.Context())
 
 			// Get all questions from database
 			questions, err := questionRepo.FindAll()
@@ -432,7 +517,7 @@ func SetupRouter() *gin.Engine {
 			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept")
 
 			// Initialize repository
-			studentRepo := repository.NewStudentRepository()
+			studentRepo := repository.NewStudentRepository().WithContext(c.Request.Context())
 
 			// Get all students from database
 			students, err := studentRepo.FindAll()
@@ -482,9 +567,17 @@ func SetupRouter() *gin.Engine {
 			// Hanya admin dan guru yang dapat mengelola soal
 			questionAdmin := questions.Group("/", middleware.RoleMiddleware(models.RoleAdmin, models.RoleTeacher))
 			{
+				questionAdmin.GET("/search", handlers.SearchQuestions)
 				questionAdmin.POST("/", handlers.CreateQuestion)
+				questionAdmin.POST("/import", handlers.ImportQuestions)
+				questionAdmin.GET("/export", handlers.ExportQuestions)
 				questionAdmin.PUT("/:id", handlers.UpdateQuestion)
 				questionAdmin.DELETE("/:id", handlers.DeleteQuestion)
+				// Riwayat revisi soal
+				questionAdmin.GET("/:id/versions", handlers.ListQuestionVersions)
+				questionAdmin.GET("/:id/versions/:version", handlers.GetQuestionVersion)
+				questionAdmin.GET("/:id/diff", handlers.DiffQuestionVersions)
+				questionAdmin.POST("/:id/versions/:version/restore", handlers.RestoreQuestionVersion)
 			}
 		}
 	}
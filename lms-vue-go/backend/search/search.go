@@ -0,0 +1,123 @@
+// Package search contains text search helpers shared by the question bank:
+// splitting a query into terms, building MySQL FULLTEXT queries and
+// highlighting matched terms.
+package search
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// HighlightStart and HighlightEnd wrap matched terms in highlighted text
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// maxTerms limits how many terms of a query are used
+const maxTerms = 10
+
+// Terms splits a query into lowercase terms. Text in double quotes is kept as one phrase.
+func Terms(query string) []string {
+	var terms []string
+	seen := map[string]bool{}
+
+	add := func(term string) {
+		term = strings.Join(strings.FieldsFunc(strings.ToLower(term), isSeparator), " ")
+		if term == "" || seen[term] || len(terms) >= maxTerms {
+			return
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+
+	parts := strings.Split(query, `"`)
+	for i, part := range parts {
+		if i%2 == 1 {
+			add(part)
+			continue
+		}
+		for _, word := range strings.FieldsFunc(part, isSeparator) {
+			add(word)
+		}
+	}
+
+	return terms
+}
+
+// isSeparator reports whether r separates words. Letters, digits and a few
+// characters used inside words (e.g. "x^2", "e-mail") are kept.
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '^' && r != '\''
+}
+
+// BooleanQuery builds a MySQL BOOLEAN MODE search string that requires every
+// term. Single words also match as prefixes; phrases must match exactly.
+func BooleanQuery(terms []string) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		words := strings.FieldsFunc(term, isBooleanOperator)
+		if len(words) == 0 {
+			continue
+		}
+		if len(words) == 1 {
+			parts = append(parts, "+"+words[0]+"*")
+			continue
+		}
+		parts = append(parts, `+"`+strings.Join(words, " ")+`"`)
+	}
+	return strings.Join(parts, " ")
+}
+
+// isBooleanOperator reports whether r has a meaning in MySQL boolean mode
+func isBooleanOperator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`+-<>()~*"@'`, r)
+}
+
+// Highlight HTML-escapes text and wraps every case-insensitive match of the
+// terms in HighlightStart/HighlightEnd. It also reports whether anything matched.
+func Highlight(text string, terms []string) (string, bool) {
+	pattern := termPattern(terms)
+	if pattern == nil {
+		return html.EscapeString(text), false
+	}
+
+	matches := pattern.FindAllStringIndex(text, -1)
+	if len(matches) == 0 {
+		return html.EscapeString(text), false
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(html.EscapeString(text[last:m[0]]))
+		b.WriteString(HighlightStart)
+		b.WriteString(html.EscapeString(text[m[0]:m[1]]))
+		b.WriteString(HighlightEnd)
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+
+	return b.String(), true
+}
+
+// termPattern compiles a case-insensitive pattern matching any of the terms,
+// longest first so phrases win over the words inside them
+func termPattern(terms []string) *regexp.Regexp {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	sorted := append([]string(nil), terms...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	quoted := make([]string, len(sorted))
+	for i, term := range sorted {
+		quoted[i] = strings.ReplaceAll(regexp.QuoteMeta(term), " ", `\s+`)
+	}
+
+	return regexp.MustCompile(`(?i)(` + strings.Join(quoted, "|") + `)`)
+}
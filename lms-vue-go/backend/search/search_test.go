@@ -0,0 +1,34 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	got := Terms(`Ibukota  "Bahasa   Pemrograman" ibukota, x^2 +`)
+	want := []string{"ibukota", "bahasa pemrograman", "x^2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Terms = %q, want %q", got, want)
+	}
+}
+
+func TestBooleanQuery(t *testing.T) {
+	got := BooleanQuery([]string{"ibukota", "bahasa pemrograman", "c++", "-"})
+	want := `+ibukota* +"bahasa pemrograman" +c*`
+	if got != want {
+		t.Errorf("BooleanQuery = %q, want %q", got, want)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got, ok := Highlight("Bahasa <b>pemrograman</b> di browser: Bahasa  Pemrograman", []string{"bahasa pemrograman", "browser"})
+	want := "Bahasa &lt;b&gt;pemrograman&lt;/b&gt; di <mark>browser</mark>: <mark>Bahasa  Pemrograman</mark>"
+	if !ok || got != want {
+		t.Errorf("Highlight = %q, %v, want %q", got, ok, want)
+	}
+
+	if got, ok := Highlight("a < b", []string{"zzz"}); ok || got != "a &lt; b" {
+		t.Errorf("Highlight without match = %q, %v", got, ok)
+	}
+}
@@ -0,0 +1,311 @@
+// Package similarity finds essay answers that share long passages of text.
+// Answers are split into overlapping word 5-grams (shingles). MinHash
+// signatures with locality-sensitive hashing select candidate pairs in large
+// sets, and every candidate is scored on its exact shingle overlap.
+package similarity
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"lms-vue-go/backend/search"
+)
+
+const (
+	// ShingleSize is the number of words in a shingle
+	ShingleSize = 5
+	// MinWords is the length an answer needs to be compared; shorter answers
+	// are too generic to say anything about copying
+	MinWords = 8
+	// DefaultThreshold is the score from which a pair is reported
+	DefaultThreshold = 0.5
+
+	signatureSize = 128
+	bandRows      = 4 // 32 bands of 4 rows find pairs from a Jaccard similarity of about 0.4
+	// exactLimit is the number of documents up to which every pair is compared
+	// without MinHash, so that low thresholds find every pair
+	exactLimit = 300
+	// maxPassages limits the overlapping passages listed per pair
+	maxPassages = 10
+)
+
+// Document is a text to compare, such as one student's answer
+type Document struct {
+	ID   uint
+	Text string
+}
+
+// Pair is two documents that share text
+type Pair struct {
+	A uint `json:"a"`
+	B uint `json:"b"`
+	// Jaccard is the number of shared shingles divided by the number of distinct shingles of both
+	Jaccard float64 `json:"jaccard"`
+	// Containment is the share of the shorter document's shingles found in the other,
+	// which catches a copied passage inside a longer answer
+	Containment float64 `json:"containment"`
+	Score       float64 `json:"score"` // The larger of Jaccard and Containment
+	// HighlightA and HighlightB are the HTML-escaped texts with shared passages in <mark>
+	HighlightA string   `json:"highlight_a"`
+	HighlightB string   `json:"highlight_b"`
+	Passages   []string `json:"passages"` // Shared passages as they appear in A
+}
+
+// token is a word and its byte range in the original text
+type token struct {
+	word       string
+	start, end int
+}
+
+// prepared holds the shingles of one document
+type prepared struct {
+	doc      Document
+	tokens   []token
+	shingles map[uint64][]int // Shingle hash to the positions of its first word
+}
+
+// Compare returns the pairs of documents with a score of at least threshold,
+// highest score first. Documents shorter than MinWords words are skipped.
+func Compare(docs []Document, threshold float64) []Pair {
+	var items []*prepared
+	for _, doc := range docs {
+		tokens := tokenize(doc.Text)
+		if len(tokens) < MinWords {
+			continue
+		}
+		items = append(items, &prepared{doc: doc, tokens: tokens, shingles: shingles(tokens)})
+	}
+
+	pairs := []Pair{}
+	for _, candidate := range candidates(items) {
+		a, b := items[candidate[0]], items[candidate[1]]
+		if pair, ok := score(a, b, threshold); ok {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].Score != pairs[j].Score {
+			return pairs[i].Score > pairs[j].Score
+		}
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+	return pairs
+}
+
+// candidates returns the index pairs to score. Small sets compare every pair;
+// large sets only pairs that share a MinHash band.
+func candidates(items []*prepared) [][2]int {
+	var result [][2]int
+	if len(items) <= exactLimit {
+		for i := range items {
+			for j := i + 1; j < len(items); j++ {
+				result = append(result, [2]int{i, j})
+			}
+		}
+		return result
+	}
+
+	signatures := make([][]uint64, len(items))
+	for i, item := range items {
+		signatures[i] = signature(item.shingles)
+	}
+
+	seen := map[[2]int]bool{}
+	for band := 0; band < signatureSize/bandRows; band++ {
+		buckets := map[string][]int{}
+		key := make([]byte, 8*bandRows)
+		for i, sig := range signatures {
+			for r := 0; r < bandRows; r++ {
+				binary.LittleEndian.PutUint64(key[8*r:], sig[band*bandRows+r])
+			}
+			buckets[string(key)] = append(buckets[string(key)], i)
+		}
+		for _, bucket := range buckets {
+			for x := 0; x < len(bucket); x++ {
+				for y := x + 1; y < len(bucket); y++ {
+					pair := [2]int{bucket[x], bucket[y]}
+					if !seen[pair] {
+						seen[pair] = true
+						result = append(result, pair)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i][0] != result[j][0] {
+			return result[i][0] < result[j][0]
+		}
+		return result[i][1] < result[j][1]
+	})
+	return result
+}
+
+// score computes the similarity of two documents and reports whether it reaches threshold
+func score(a, b *prepared, threshold float64) (Pair, bool) {
+	shared := map[uint64]bool{}
+	for h := range a.shingles {
+		if _, ok := b.shingles[h]; ok {
+			shared[h] = true
+		}
+	}
+	if len(shared) == 0 {
+		return Pair{}, false
+	}
+
+	union := len(a.shingles) + len(b.shingles) - len(shared)
+	smaller := min(len(a.shingles), len(b.shingles))
+	pair := Pair{
+		A:           a.doc.ID,
+		B:           b.doc.ID,
+		Jaccard:     round(float64(len(shared)) / float64(union)),
+		Containment: round(float64(len(shared)) / float64(smaller)),
+	}
+	pair.Score = math.Max(pair.Jaccard, pair.Containment)
+	if pair.Score < threshold {
+		return Pair{}, false
+	}
+
+	runsA := overlaps(a, shared)
+	pair.HighlightA = highlight(a.doc.Text, runsA)
+	pair.HighlightB = highlight(b.doc.Text, overlaps(b, shared))
+	pair.Passages = []string{}
+	for _, run := range runsA {
+		if len(pair.Passages) == maxPassages {
+			break
+		}
+		pair.Passages = append(pair.Passages, a.doc.Text[run[0]:run[1]])
+	}
+	return pair, true
+}
+
+// tokenize splits text into lowercase words of letters and digits
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			tokens = append(tokens, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// shingles hashes every run of ShingleSize words
+func shingles(tokens []token) map[uint64][]int {
+	result := map[uint64][]int{}
+	for i := 0; i+ShingleSize <= len(tokens); i++ {
+		h := fnv.New64a()
+		for _, t := range tokens[i : i+ShingleSize] {
+			h.Write([]byte(t.word))
+			h.Write([]byte{0})
+		}
+		sum := h.Sum64()
+		result[sum] = append(result[sum], i)
+	}
+	return result
+}
+
+// signature returns the MinHash signature of a shingle set: for each of
+// signatureSize hash functions the smallest hash of any shingle
+func signature(shingles map[uint64][]int) []uint64 {
+	sig := make([]uint64, signatureSize)
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for h := range shingles {
+		for i := range sig {
+			if v := mix(h ^ seeds[i]); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// seeds select the hash functions of the MinHash signature
+var seeds = func() []uint64 {
+	s := make([]uint64, signatureSize)
+	state := uint64(0x5eed)
+	for i := range s {
+		state += 0x9e3779b97f4a7c15
+		s[i] = mix(state)
+	}
+	return s
+}()
+
+// mix is the SplitMix64 finalizer, a fast 64-bit hash
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// overlaps returns the byte ranges of the runs of words covered by shared shingles
+func overlaps(p *prepared, shared map[uint64]bool) [][2]int {
+	marked := make([]bool, len(p.tokens))
+	for h, positions := range p.shingles {
+		if !shared[h] {
+			continue
+		}
+		for _, pos := range positions {
+			for i := pos; i < pos+ShingleSize; i++ {
+				marked[i] = true
+			}
+		}
+	}
+
+	var runs [][2]int
+	for i := 0; i < len(marked); i++ {
+		if !marked[i] {
+			continue
+		}
+		j := i
+		for j+1 < len(marked) && marked[j+1] {
+			j++
+		}
+		runs = append(runs, [2]int{p.tokens[i].start, p.tokens[j].end})
+		i = j
+	}
+	return runs
+}
+
+// highlight HTML-escapes text and wraps the runs in search.HighlightStart/HighlightEnd
+func highlight(text string, runs [][2]int) string {
+	var b strings.Builder
+	last := 0
+	for _, run := range runs {
+		b.WriteString(html.EscapeString(text[last:run[0]]))
+		b.WriteString(search.HighlightStart)
+		b.WriteString(html.EscapeString(text[run[0]:run[1]]))
+		b.WriteString(search.HighlightEnd)
+		last = run[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// round rounds v to three decimals
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
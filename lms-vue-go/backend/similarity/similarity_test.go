@@ -0,0 +1,70 @@
+package similarity
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const original = "Fotosintesis adalah proses tumbuhan hijau mengubah cahaya matahari, air dan karbon dioksida menjadi glukosa dan oksigen di dalam kloroplas."
+
+func TestCompare(t *testing.T) {
+	docs := []Document{
+		{ID: 1, Text: original},
+		// Copied with a different beginning and ending
+		{ID: 2, Text: "Menurut saya fotosintesis adalah proses tumbuhan hijau mengubah cahaya matahari, air dan karbon dioksida menjadi makanan."},
+		{ID: 3, Text: "Tumbuhan membuat makanannya sendiri dengan bantuan klorofil yang menyerap energi dari sinar matahari setiap hari."},
+		{ID: 4, Text: "Terlalu pendek untuk dibandingkan."},
+	}
+
+	pairs := Compare(docs, 0.3)
+	if len(pairs) != 1 {
+		t.Fatalf("got %d pairs, want 1: %+v", len(pairs), pairs)
+	}
+	p := pairs[0]
+	if p.A != 1 || p.B != 2 || p.Score != p.Containment || p.Containment <= p.Jaccard {
+		t.Errorf("pair = %+v", p)
+	}
+	if want := "Fotosintesis adalah proses tumbuhan hijau mengubah cahaya matahari, air dan karbon dioksida menjadi"; len(p.Passages) != 1 || p.Passages[0] != want {
+		t.Errorf("passages = %q, want [%q]", p.Passages, want)
+	}
+	if !strings.HasPrefix(p.HighlightB, "Menurut saya <mark>fotosintesis adalah") || !strings.HasSuffix(p.HighlightB, "menjadi</mark> makanan.") {
+		t.Errorf("highlight B = %q", p.HighlightB)
+	}
+
+	if pairs := Compare(docs, 0.95); len(pairs) != 0 {
+		t.Errorf("pairs above 0.95 = %+v", pairs)
+	}
+}
+
+func TestHighlightEscapes(t *testing.T) {
+	text := "a <b> c d e f g h"
+	pairs := Compare([]Document{{ID: 1, Text: text}, {ID: 2, Text: text}}, DefaultThreshold)
+	if len(pairs) != 1 || pairs[0].Jaccard != 1 || pairs[0].HighlightA != "<mark>a &lt;b&gt; c d e f g h</mark>" {
+		t.Errorf("pairs = %+v", pairs)
+	}
+}
+
+func TestCompareLargeSetUsesMinHash(t *testing.T) {
+	words := strings.Fields("rumah sekolah guru murid buku pena meja kursi pintu jendela")
+	rng := rand.New(rand.NewSource(1))
+	essay := func() string {
+		var b strings.Builder
+		for i := 0; i < 40; i++ {
+			fmt.Fprintf(&b, "%s%d ", words[rng.Intn(len(words))], rng.Intn(50))
+		}
+		return b.String()
+	}
+
+	var docs []Document
+	for i := 1; i <= exactLimit+50; i++ {
+		docs = append(docs, Document{ID: uint(i), Text: essay()})
+	}
+	docs = append(docs, Document{ID: 9999, Text: docs[10].Text + " dengan kalimat penutup tambahan"})
+
+	pairs := Compare(docs, DefaultThreshold)
+	if len(pairs) != 1 || pairs[0].A != 11 || pairs[0].B != 9999 {
+		t.Fatalf("pairs = %+v", pairs)
+	}
+}
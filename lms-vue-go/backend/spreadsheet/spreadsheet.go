@@ -0,0 +1,87 @@
+// Package spreadsheet reads and writes tabular data as CSV and XLSX files using
+// only the standard library. Only cell values are read; formulas, styles and
+// every worksheet but the first are ignored.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"path"
+	"strings"
+)
+
+// ErrUnsupported is returned for files that are neither CSV nor XLSX
+var ErrUnsupported = errors.New("unsupported spreadsheet format")
+
+// Read returns the rows of a CSV or XLSX file. The format is taken from the
+// content, falling back to the file name extension.
+func Read(filename string, data []byte) ([][]string, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return ReadXLSX(data)
+	}
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".xlsx":
+		return nil, errors.New("invalid XLSX file")
+	case ".xls", ".ods":
+		return nil, ErrUnsupported
+	}
+	return ReadCSV(data)
+}
+
+// ReadCSV parses comma, semicolon or tab separated values. The separator is
+// detected from the first line, since spreadsheet programs in many locales
+// export CSV with semicolons.
+func ReadCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectSeparator(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return trimRows(rows), nil
+}
+
+// detectSeparator returns the most frequent separator on the first line
+func detectSeparator(data []byte) rune {
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line = data[:i]
+	}
+
+	separator, best := ',', bytes.Count(line, []byte(","))
+	for _, candidate := range []rune{';', '\t'} {
+		if n := bytes.Count(line, []byte(string(candidate))); n > best {
+			separator, best = candidate, n
+		}
+	}
+	return separator
+}
+
+// trimRows trims spaces around cells and removes empty rows at the end
+func trimRows(rows [][]string) [][]string {
+	for _, row := range rows {
+		for i := range row {
+			row[i] = strings.TrimSpace(row[i])
+		}
+	}
+	for len(rows) > 0 && isEmptyRow(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	return rows
+}
+
+// isEmptyRow reports whether every cell of a row is empty
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
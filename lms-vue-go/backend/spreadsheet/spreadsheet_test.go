@@ -0,0 +1,91 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestReadCSVDetectsSeparator(t *testing.T) {
+	data := []byte("\ufeffnama;kelas;email\n Budi ; X-1 ;budi@example.com\n\"Sari; S.Pd\";X-2;\n;;\n")
+	rows, err := ReadCSV(data)
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	want := [][]string{
+		{"nama", "kelas", "email"},
+		{"Budi", "X-1", "budi@example.com"},
+		{"Sari; S.Pd", "X-2", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("ReadCSV = %q, want %q", rows, want)
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Siswa" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId7" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>name</t></si><si><r><t>Bu</t></r><r><t>di</t></r></si></sst>`,
+		"xl/worksheets/data.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="inlineStr"><is><t>nis</t></is></c></row>
+<row r="2"><c r="A2" t="s"><v>1</v></c><c r="B2" t="b"><v>1</v></c><c r="C2"><v>20261</v></c></row>
+</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, _ := archive.Create(name)
+		w.Write([]byte(content))
+	}
+	archive.Close()
+
+	rows, err := Read("siswa.xlsx", buf.Bytes())
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	want := [][]string{{"name", "", "nis"}, {"Budi", "TRUE", "20261"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Read = %q, want %q", rows, want)
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "Z9": 25, "AA10": 26, "BC3": 54} {
+		if got, ok := columnIndex(ref); !ok || got != want {
+			t.Errorf("columnIndex(%q) = %d, %v, want %d", ref, got, ok, want)
+		}
+	}
+}
+
+func TestWriteXLSXRoundTrip(t *testing.T) {
+	data, err := WriteXLSX("Nilai X-1", []string{"Nama", "Total"}, [][]interface{}{
+		{"Budi <A&B>", 85},
+		{"Sari", nil, 92.5},
+	})
+	if err != nil {
+		t.Fatalf("WriteXLSX: %v", err)
+	}
+
+	rows, err := ReadXLSX(data)
+	if err != nil {
+		t.Fatalf("ReadXLSX: %v", err)
+	}
+	want := [][]string{{"Nama", "Total"}, {"Budi <A&B>", "85"}, {"Sari", "", "92.5"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 54: "BC", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
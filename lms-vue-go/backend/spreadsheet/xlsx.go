@@ -0,0 +1,214 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxPartSize limits the size of a single XML part read from an XLSX file
+const maxPartSize = 50 << 20
+
+// xlsxWorkbook lists the worksheets of a workbook in tab order
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships maps relationship IDs to parts
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxSharedStrings is the shared string table; rich text items consist of several runs
+type xlsxSharedStrings struct {
+	Items []xlsxString `xml:"si"`
+}
+
+// xlsxString is a string item: plain text in T or rich text runs in Runs
+type xlsxString struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// text returns the full text of a string item
+func (s xlsxString) text() string {
+	if len(s.Runs) == 0 {
+		return s.T
+	}
+	var b strings.Builder
+	for _, run := range s.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+// xlsxSheet holds the cell values of a worksheet
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string     `xml:"r,attr"`
+			Type   string     `xml:"t,attr"`
+			Value  string     `xml:"v"`
+			Inline xlsxString `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX returns the cell values of the first worksheet of an XLSX file.
+// Numbers are returned as stored, so dates appear as serial numbers.
+func ReadXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX file: %w", err)
+	}
+
+	parts := map[string]*zip.File{}
+	for _, file := range archive.File {
+		parts[file.Name] = file
+	}
+
+	sheetPath, err := firstSheet(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if file, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(file, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := decodePart(parts[sheetPath], &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, r := range sheet.Rows {
+		var row []string
+		for _, cell := range r.Cells {
+			col := len(row)
+			if cell.Ref != "" {
+				if parsed, ok := columnIndex(cell.Ref); ok {
+					col = parsed
+				}
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				var i int
+				if _, err := fmt.Sscan(cell.Value, &i); err == nil && i >= 0 && i < len(shared.Items) {
+					row[col] = shared.Items[i].text()
+				}
+			case "inlineStr":
+				row[col] = cell.Inline.text()
+			case "b":
+				row[col] = "FALSE"
+				if cell.Value == "1" {
+					row[col] = "TRUE"
+				}
+			default:
+				row[col] = cell.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return trimRows(rows), nil
+}
+
+// firstSheet returns the part name of the first worksheet in tab order
+func firstSheet(parts map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	workbookFile, ok := parts["xl/workbook.xml"]
+	relsFile, relsOK := parts["xl/_rels/workbook.xml.rels"]
+	if !ok || !relsOK {
+		if _, ok := parts[fallback]; ok {
+			return fallback, nil
+		}
+		return "", errors.New("invalid XLSX file: workbook not found")
+	}
+	if err := decodePart(workbookFile, &workbook); err != nil {
+		return "", err
+	}
+	if err := decodePart(relsFile, &rels); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("invalid XLSX file: workbook has no worksheets")
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		if _, ok := parts[target]; ok {
+			return target, nil
+		}
+	}
+
+	if _, ok := parts[fallback]; ok {
+		return fallback, nil
+	}
+	return "", errors.New("invalid XLSX file: worksheet not found")
+}
+
+// decodePart unmarshals an XML part of the archive
+func decodePart(file *zip.File, v interface{}) error {
+	if file.UncompressedSize64 > maxPartSize {
+		return fmt.Errorf("invalid XLSX file: %s is too large", file.Name)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("invalid XLSX file: %w", err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("invalid XLSX file: %s: %w", file.Name, err)
+	}
+	return nil
+}
+
+// columnIndex converts a cell reference such as "C7" to a zero-based column index
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+	}
+	if n == 0 || n > 3 {
+		return 0, false
+	}
+	return col - 1, true
+}
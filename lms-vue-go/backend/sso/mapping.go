@@ -0,0 +1,49 @@
+package sso
+
+import (
+	"strings"
+
+	"lms-vue-go/backend/auth"
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+)
+
+// Profile is the LMS view of an IdP user
+type Profile struct {
+	Subject       string
+	Username      string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Class         string
+	Role          models.Role
+}
+
+// MapClaims converts verified ID token claims to a profile using the configured claim names
+func MapClaims(claims Claims, cfg config.OIDCConfig) Profile {
+	profile := Profile{
+		Subject:       claims.String("sub"),
+		Username:      claims.String(cfg.UsernameClaim),
+		Email:         claims.String("email"),
+		EmailVerified: claims.Bool("email_verified"),
+		Name:          claims.String(cfg.NameClaim),
+		Role:          MapRole(claims.Strings(cfg.RoleClaim), cfg),
+	}
+
+	if cfg.ClassClaim != "" {
+		profile.Class = claims.String(cfg.ClassClaim)
+	}
+
+	// Fall back to the local part of the email when the IdP has no username claim
+	if profile.Username == "" && profile.Email != "" {
+		profile.Username, _, _ = strings.Cut(profile.Email, "@")
+	}
+
+	return profile
+}
+
+// MapRole returns the highest LMS role mapped from the claim values, or the default role
+func MapRole(values []string, cfg config.OIDCConfig) models.Role {
+	role, _ := auth.MapRole(values, cfg.RoleMapping, cfg.DefaultRole)
+	return role
+}
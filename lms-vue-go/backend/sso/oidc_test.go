@@ -0,0 +1,101 @@
+package sso_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"lms-vue-go/backend/config"
+	"lms-vue-go/backend/models"
+	"lms-vue-go/backend/sso"
+	"lms-vue-go/backend/sso/ssotest"
+)
+
+func TestAuthorizationCodeFlowWithMockIdP(t *testing.T) {
+	idp, err := ssotest.NewMockIdP("lms", map[string]interface{}{
+		"sub":                "guru-42",
+		"email":              "guru@sekolah.sch.id",
+		"email_verified":     true,
+		"preferred_username": "bu.sari",
+		"name":               "Sari Dewi",
+		"groups":             []string{"pegawai", "guru"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idp.Close()
+
+	cfg := config.OIDCConfig{
+		Issuer:        idp.Issuer(),
+		ClientID:      "lms",
+		RedirectURL:   "http://localhost:3001/api/auth/oidc/callback",
+		Scopes:        []string{"openid", "email", "profile"},
+		UsernameClaim: "preferred_username",
+		NameClaim:     "name",
+		RoleClaim:     "groups",
+		RoleMapping:   map[string]string{"guru": "teacher"},
+		DefaultRole:   "student",
+	}
+
+	ctx := context.Background()
+	provider, err := sso.NewProvider(ctx, cfg, nil)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	verifier, _ := sso.RandomString()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(provider.AuthCodeURL("state-1", "nonce-1", verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || callback.Query().Get("state") != "state-1" {
+		t.Fatalf("unexpected redirect: %s", resp.Header.Get("Location"))
+	}
+
+	if _, err := provider.Exchange(ctx, callback.Query().Get("code"), "wrong-verifier", "nonce-1"); err == nil {
+		t.Fatal("expected exchange with wrong PKCE verifier to fail")
+	}
+
+	// The code was consumed by the failed attempt, so authorize again
+	resp, err = client.Get(provider.AuthCodeURL("state-2", "nonce-2", verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	callback, _ = url.Parse(resp.Header.Get("Location"))
+
+	claims, err := provider.Exchange(ctx, callback.Query().Get("code"), verifier, "nonce-2")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	profile := sso.MapClaims(claims, cfg)
+	if profile.Subject != "guru-42" || profile.Username != "bu.sari" || !profile.EmailVerified {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+	if profile.Role != models.RoleTeacher {
+		t.Errorf("expected role teacher, got %s", profile.Role)
+	}
+}
+
+func TestMapRoleUsesDefaultAndPriority(t *testing.T) {
+	cfg := config.OIDCConfig{
+		RoleMapping: map[string]string{"guru": "teacher", "it": "admin", "siswa": "student"},
+		DefaultRole: "student",
+	}
+
+	if role := sso.MapRole(nil, cfg); role != models.RoleStudent {
+		t.Errorf("expected default role student, got %s", role)
+	}
+	if role := sso.MapRole([]string{"guru", "it"}, cfg); role != models.RoleAdmin {
+		t.Errorf("expected highest mapped role admin, got %s", role)
+	}
+	if role := sso.MapRole([]string{"unknown"}, cfg); role != models.RoleStudent {
+		t.Errorf("expected unmapped groups to fall back to default, got %s", role)
+	}
+}
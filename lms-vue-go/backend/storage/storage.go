@@ -0,0 +1,70 @@
+// Package storage keeps uploaded files in a blob store: a directory on the
+// local filesystem or a bucket of an S3-compatible object store such as AWS S3
+// or MinIO. File metadata (name, type, owner) is kept in the database by the
+// caller; the store only maps keys to bytes.
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"lms-vue-go/backend/config"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("object not found")
+
+// ErrInvalidKey is returned for keys that could escape the store, such as "../x"
+var ErrInvalidKey = errors.New("invalid object key")
+
+// BlobStore stores objects under slash separated keys
+type BlobStore interface {
+	// Put stores data under key, replacing an existing object
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Open returns the content of an object; the caller must close it
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes an object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the blob store selected by the configuration driver
+func New(cfg config.StorageConfig) BlobStore {
+	switch strings.ToLower(cfg.Driver) {
+	case "s3":
+		return NewS3Store(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3PathStyle)
+	case "local", "":
+		return NewLocalStore(cfg.LocalPath)
+	default:
+		slog.Warn("Unknown storage driver, falling back to local storage", "driver", cfg.Driver)
+		return NewLocalStore(cfg.LocalPath)
+	}
+}
+
+// NewKey returns a new random key such as "attachments/2026/10/3f2a...e1.pdf"
+func NewKey(prefix, extension string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s/%s%s", prefix, time.Now().Format("2006/01"), hex.EncodeToString(buf), extension), nil
+}
+
+// validKey reports whether key is a relative path without empty, "." or ".." segments
+func validKey(key string) bool {
+	if key == "" || strings.ContainsAny(key, "\\\x00") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// roundTrip stores, reads and deletes an object
+func roundTrip(t *testing.T, store BlobStore) {
+	t.Helper()
+	ctx := context.Background()
+	key := "attachments/2026/10/tugas.pdf"
+	data := []byte("%PDF-1.4 isi tugas")
+
+	if err := store.Put(ctx, key, data, "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	r, err := store.Open(ctx, key)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	got, _ := io.ReadAll(r)
+	r.Close()
+	if !bytes.Equal(got, data) {
+		t.Errorf("Open returned %q, want %q", got, data)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("deleting a missing object returned %v", err)
+	}
+	if _, err := store.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after Delete returned %v, want ErrNotFound", err)
+	}
+	if err := store.Put(ctx, "../luar.txt", data, "text/plain"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Put with ../ returned %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	roundTrip(t, NewLocalStore(t.TempDir()))
+}
+
+func TestValidKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"images/2026/10/a.png": true,
+		"":                     false,
+		"/etc/passwd":          false,
+		"images/../../x":       false,
+		"images//a.png":        false,
+		`images\a.png`:         false,
+	} {
+		if got := validKey(key); got != want {
+			t.Errorf("validKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+	images := Policy{MaxSize: 100, Types: []string{"image/*"}}
+
+	if contentType, err := images.Check(png); err != nil || contentType != "image/png" {
+		t.Errorf("Check(png) = %q, %v", contentType, err)
+	}
+	if _, err := images.Check([]byte("<svg onload=alert(1)>")); !errors.Is(err, ErrTypeNotAllowed) {
+		t.Errorf("Check(svg) returned %v, want ErrTypeNotAllowed", err)
+	}
+	if _, err := images.Check(append(png, make([]byte, 100)...)); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("Check(large) returned %v, want ErrFileTooLarge", err)
+	}
+	if _, err := images.Check(nil); !errors.Is(err, ErrEmptyFile) {
+		t.Errorf("Check(empty) returned %v, want ErrEmptyFile", err)
+	}
+}
+
+func TestSigner(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	signer := NewSigner([]byte("rahasia"))
+	signer.now = func() time.Time { return now }
+
+	link := signer.Sign("/api/files/7", time.Hour)
+	query := link[len("/api/files/7?"):]
+	values := map[string]string{}
+	for _, pair := range bytes.Split([]byte(query), []byte("&")) {
+		kv := bytes.SplitN(pair, []byte("="), 2)
+		values[string(kv[0])] = string(kv[1])
+	}
+
+	if err := signer.Verify("/api/files/7", values["expires"], values["signature"]); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := signer.Verify("/api/files/8", values["expires"], values["signature"]); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify with another path returned %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	if err := signer.Verify("/api/files/7", values["expires"], values["signature"]); !errors.Is(err, ErrURLExpired) {
+		t.Errorf("Verify after expiry returned %v", err)
+	}
+}
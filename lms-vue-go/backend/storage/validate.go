@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Errors returned by Policy.Check
+var (
+	ErrEmptyFile      = errors.New("file is empty")
+	ErrFileTooLarge   = errors.New("file is too large")
+	ErrTypeNotAllowed = errors.New("file type is not allowed")
+)
+
+// Common sets of allowed types. SVG is left out on purpose: it can contain scripts.
+var (
+	ImageTypes    = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+	DocumentTypes = []string{"application/pdf", "application/zip", "text/plain"}
+)
+
+// extensions maps the types that DetectType can return to a file extension
+var extensions = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/bmp":       ".bmp",
+	"application/pdf": ".pdf",
+	"application/zip": ".zip",
+	"text/plain":      ".txt",
+}
+
+// Policy limits the size and type of uploaded files
+type Policy struct {
+	MaxSize int64
+	// Types lists allowed MIME types; "image/*" allows every image type
+	Types []string
+}
+
+// Check detects the type of data from its content and checks it against the
+// policy. The name and type sent by the client are not trusted.
+func (p Policy) Check(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", ErrEmptyFile
+	}
+	if p.MaxSize > 0 && int64(len(data)) > p.MaxSize {
+		return "", ErrFileTooLarge
+	}
+
+	contentType := DetectType(data)
+	if !p.Allows(contentType) {
+		return contentType, ErrTypeNotAllowed
+	}
+	return contentType, nil
+}
+
+// Allows reports whether the policy accepts a content type
+func (p Policy) Allows(contentType string) bool {
+	for _, allowed := range p.Types {
+		if allowed == contentType || strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, allowed[:len(allowed)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectType returns the MIME type of data without parameters, e.g. "image/png"
+func DetectType(data []byte) string {
+	contentType := http.DetectContentType(data)
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.TrimSpace(contentType)
+}
+
+// Extension returns the file extension for a content type, or ".bin" if it is unknown
+func Extension(contentType string) string {
+	if ext, ok := extensions[contentType]; ok {
+		return ext
+	}
+	return ".bin"
+}
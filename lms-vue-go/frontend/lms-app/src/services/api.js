@@ -345,16 +345,15 @@ export const answerService = {
   },
 
   // Memberikan nilai untuk jawaban siswa (admin/guru)
-  async gradeAnswer(answerId, score) {
+  // feedback opsional; jika tidak diisi, catatan guru yang ada tidak diubah
+  async gradeAnswer(answerId, score, feedback) {
     try {
-      // Use the public grade answer endpoint
-      const response = await axios.put(
-        `http://localhost:3001/api/public-grade-answer/${answerId}`,
-        {
-          score: score,
-        }
-      );
-      return response;
+      const data = { score: score };
+      if (feedback !== undefined) {
+        data.feedback = feedback;
+      }
+      // Always use direct URL to avoid proxy issues
+      return await directApiClient.put(`/answers/${answerId}/grade`, data);
     } catch (error) {
       console.error(`Grade answer ${answerId} failed:`, error);
       throw error;